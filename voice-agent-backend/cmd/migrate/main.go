@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func main() {
+	dir := flag.String("dir", "migrations", "directory containing numbered .sql migrations")
+	status := flag.Bool("status", false, "list migrations and whether they have been applied, without applying")
+	baseline := flag.Int("baseline", -1, "mark migrations up to this version as applied without running them, for a schema created by hand")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	store, err := database.NewPostgresStore(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	if *status {
+		migrations, err := database.LoadMigrations(*dir)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		applied, err := store.AppliedMigrations(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read applied migrations: %v", err)
+		}
+		for _, m := range migrations {
+			state := "pending"
+			if applied[m.Version] {
+				state = "applied"
+			}
+			log.Printf("%03d_%s: %s", m.Version, m.Name, state)
+		}
+		return
+	}
+
+	if *baseline >= 0 {
+		marked, err := store.Baseline(ctx, *dir, *baseline)
+		if err != nil {
+			log.Fatalf("Baseline failed: %v", err)
+		}
+		for _, m := range marked {
+			log.Printf("Marked migration %03d_%s as applied", m.Version, m.Name)
+		}
+		log.Printf("Marked %d migration(s) as applied; run again without -baseline to apply the rest", len(marked))
+		return
+	}
+
+	ran, err := store.Migrate(ctx, *dir)
+	for _, m := range ran {
+		log.Printf("Applied migration %03d_%s", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	if len(ran) == 0 {
+		log.Println("Database is up to date")
+	} else {
+		log.Printf("Applied %d migration(s)", len(ran))
+	}
+}
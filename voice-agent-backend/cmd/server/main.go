@@ -8,14 +8,23 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // embed zoneinfo so BUSINESS_TIMEZONE works in minimal containers
 
 	"github.com/gin-gonic/gin"
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/calendar"
 	"github.com/voice-agent/backend/internal/config"
 	"github.com/voice-agent/backend/internal/database"
 	"github.com/voice-agent/backend/internal/handlers"
+	"github.com/voice-agent/backend/internal/lifecycle"
 	"github.com/voice-agent/backend/internal/middleware"
+	"github.com/voice-agent/backend/internal/policy"
 	"github.com/voice-agent/backend/internal/services/avatar"
 	"github.com/voice-agent/backend/internal/services/livekit"
+	"github.com/voice-agent/backend/internal/services/llm"
+	"github.com/voice-agent/backend/internal/services/payment"
+	"github.com/voice-agent/backend/internal/tools"
+	"github.com/voice-agent/backend/internal/waitlist"
 	"github.com/voice-agent/backend/internal/websocket"
 )
 
@@ -41,14 +50,55 @@ func main() {
 	}
 
 	// Initialize database
-	if err := database.Initialize(cfg); err != nil {
-		log.Printf("Warning: Failed to initialize database: %v", err)
-	} else {
-		log.Println("Database initialized successfully")
+	store, err := database.NewStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	log.Printf("Database initialized successfully (driver: %s)", cfg.DatabaseDriver)
+
+	// External calendars: appointment writes are pushed through the wrapped
+	// store and busy time is pulled into it
+	calendarSync, err := calendar.NewSync(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize calendar sync: %v", err)
+	}
+	if calendarSync != nil {
+		syncService := calendar.NewSyncService(cfg, store, calendarSync)
+		defer syncService.Stop()
+		store = syncService.Store()
+		log.Printf("Calendar sync enabled (%s)", cfg.CalendarSync)
+	}
+
+	// Initialize scheduling
+	schedule, err := availability.ScheduleFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load business hours: %v", err)
 	}
+	slotEngine := availability.NewEngine(schedule, store)
+	waitlistService := waitlist.NewService(cfg, store, schedule, waitlist.LogNotifier{})
+	defer waitlistService.Stop()
+	lifecycleService := lifecycle.NewService(cfg, store)
+	defer lifecycleService.Stop()
+	calendarFeeds := calendar.NewFeeds(cfg, store)
+
+	// Booking limits and cancellation policy; late fees are collected
+	// through Stripe when configured
+	bookingPolicy, err := policy.FromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load booking policy: %v", err)
+	}
+	var paymentService *payment.PaymentService
+	if cfg.StripeSecretKey != "" {
+		paymentService = payment.NewPaymentService(cfg)
+	}
+	policyEngine := policy.NewEngine(bookingPolicy, paymentService)
 
 	// Initialize services (with error recovery)
 	log.Println("Initializing services...")
+	// Each session creates its own provider; check the configuration once here
+	if _, err := llm.NewProvider(cfg); err != nil {
+		log.Fatalf("Failed to configure LLM provider: %v", err)
+	}
 	livekitService := livekit.NewService(cfg)
 	if livekitService == nil {
 		log.Println("Warning: LiveKit service is nil, will operate with limited functionality")
@@ -59,11 +109,18 @@ func main() {
 		log.Println("Warning: Avatar service is nil, will operate with limited functionality")
 	}
 
-	wsManager := websocket.NewManager(cfg)
+	wsManager := websocket.NewManager(cfg, tools.Services{
+		Store:    store,
+		Slots:    slotEngine,
+		Waitlist: waitlistService,
+		Calendar: calendarFeeds,
+		Policy:   policyEngine,
+		Payments: paymentService,
+	})
 	log.Println("Services initialized")
 
 	// Initialize handlers
-	h := handlers.NewHandler(cfg, livekitService, avatarService, wsManager)
+	h := handlers.NewHandler(cfg, store, slotEngine, calendarFeeds, livekitService, avatarService, wsManager)
 
 	// Setup router
 	router := setupRouter(h)
@@ -140,7 +197,15 @@ func setupRouter(h *handlers.Handler) *gin.Engine {
 
 		// Appointments
 		api.GET("/appointments", h.GetAppointments)
+		api.POST("/appointments/:id/status", h.UpdateAppointmentStatus)
 		api.GET("/slots", h.GetAvailableSlots)
+		api.GET("/slots/next", h.GetNextAvailableSlots)
+		api.GET("/providers", h.GetProviders)
+		api.GET("/services", h.GetServices)
+
+		// Calendar feeds (signed per-user links)
+		api.GET("/calendar/:token", h.GetCalendarFeed)
+		api.GET("/calendar/:token/:appointment", h.GetAppointmentInvite)
 
 		// Call summaries
 		api.GET("/summaries", h.GetCallSummaries)
@@ -163,7 +228,13 @@ func setupRouter(h *handlers.Handler) *gin.Engine {
 				{"method": "POST", "path": "/api/avatar/session/:id/end", "description": "End avatar session"},
 				{"method": "GET", "path": "/api/avatar/replicas", "description": "List available avatar replicas"},
 				{"method": "GET", "path": "/api/appointments", "description": "Get appointments by phone"},
+				{"method": "POST", "path": "/api/appointments/:id/status", "description": "Confirm, check in, complete or mark an appointment a no-show"},
 				{"method": "GET", "path": "/api/slots", "description": "Get available slots for a date"},
+				{"method": "GET", "path": "/api/slots/next", "description": "Find the next available slots across a date range"},
+				{"method": "GET", "path": "/api/providers", "description": "List bookable providers (staff and rooms)"},
+				{"method": "GET", "path": "/api/services", "description": "List the service catalog"},
+				{"method": "GET", "path": "/api/calendar/:token.ics", "description": "iCalendar feed of a user's upcoming appointments"},
+				{"method": "GET", "path": "/api/calendar/:token/:id.ics", "description": "iCalendar invite or cancellation for one appointment"},
 				{"method": "GET", "path": "/api/summaries", "description": "Get call summaries by phone"},
 				{"method": "GET", "path": "/api/stats", "description": "Get server statistics"},
 				{"method": "GET", "path": "/ws", "description": "WebSocket endpoint for voice agent"},
@@ -28,6 +28,7 @@ type VoiceAgent struct {
 	cartesiaService  *cartesia.Service
 	toolExecutor     *tools.ToolExecutor
 	config           *config.Config
+	store            database.Store
 
 	// Streaming clients
 	sttClient        *deepgram.StreamingClient
@@ -36,6 +37,7 @@ type VoiceAgent struct {
 	// Callbacks
 	onTranscript     func(text string, isFinal bool)
 	onAgentResponse  func(text string)
+	onResponseDelta  func(responseID, delta string)
 	onToolCall       func(payload models.ToolCallPayload)
 	onToolResult     func(payload models.ToolResultPayload)
 	onAudioOutput    func(audio []byte)
@@ -57,6 +59,7 @@ type VoiceAgent struct {
 type AgentConfig struct {
 	OnTranscript    func(text string, isFinal bool)
 	OnAgentResponse func(text string)
+	OnResponseDelta func(responseID, delta string) // each speakable piece of a reply as it is generated
 	OnToolCall      func(payload models.ToolCallPayload)
 	OnToolResult    func(payload models.ToolResultPayload)
 	OnAudioOutput   func(audio []byte)
@@ -65,7 +68,12 @@ type AgentConfig struct {
 }
 
 // NewVoiceAgent creates a new voice agent
-func NewVoiceAgent(cfg *config.Config, roomName string, agentCfg *AgentConfig) (*VoiceAgent, error) {
+func NewVoiceAgent(cfg *config.Config, services tools.Services, roomName string, agentCfg *AgentConfig) (*VoiceAgent, error) {
+	llmService, err := llm.NewService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM service: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	agentID := uuid.New().String()
@@ -74,7 +82,8 @@ func NewVoiceAgent(cfg *config.Config, roomName string, agentCfg *AgentConfig) (
 		ID:              agentID,
 		RoomName:        roomName,
 		config:          cfg,
-		llmService:      llm.NewService(cfg),
+		store:           services.Store,
+		llmService:      llmService,
 		deepgramService: deepgram.NewService(cfg),
 		cartesiaService: cartesia.NewService(cfg),
 		messages:        make([]models.ConversationMsg, 0),
@@ -88,6 +97,7 @@ func NewVoiceAgent(cfg *config.Config, roomName string, agentCfg *AgentConfig) (
 	if agentCfg != nil {
 		agent.onTranscript = agentCfg.OnTranscript
 		agent.onAgentResponse = agentCfg.OnAgentResponse
+		agent.onResponseDelta = agentCfg.OnResponseDelta
 		agent.onToolCall = agentCfg.OnToolCall
 		agent.onToolResult = agentCfg.OnToolResult
 		agent.onAudioOutput = agentCfg.OnAudioOutput
@@ -97,15 +107,26 @@ func NewVoiceAgent(cfg *config.Config, roomName string, agentCfg *AgentConfig) (
 
 	// Create tool executor
 	agent.toolExecutor = tools.NewToolExecutor(
+		services,
 		agentID,
 		func(payload models.ToolCallPayload) {
 			agent.mu.Lock()
-			agent.toolCalls = append(agent.toolCalls, models.ToolCallRecord{
-				ID:        payload.ID,
-				Name:      payload.Name,
-				Arguments: payload.Arguments,
-				Timestamp: time.Now(),
-			})
+			// A pending action is announced again when the caller answers
+			recorded := false
+			for i := range agent.toolCalls {
+				if agent.toolCalls[i].ID == payload.ID {
+					recorded = true
+					break
+				}
+			}
+			if !recorded {
+				agent.toolCalls = append(agent.toolCalls, models.ToolCallRecord{
+					ID:        payload.ID,
+					Name:      payload.Name,
+					Arguments: payload.Arguments,
+					Timestamp: time.Now(),
+				})
+			}
 			agent.mu.Unlock()
 
 			if agent.onToolCall != nil {
@@ -215,6 +236,9 @@ func (a *VoiceAgent) initSTT() error {
 func (a *VoiceAgent) Stop() {
 	a.cancel()
 
+	// Slots held during the call go back to other callers
+	a.toolExecutor.ReleaseHolds()
+
 	if a.sttClient != nil {
 		a.sttClient.Close()
 	}
@@ -262,10 +286,19 @@ func (a *VoiceAgent) ProcessUserInput(text string) {
 		Timestamp: time.Now(),
 	})
 	a.mu.Unlock()
+	a.toolExecutor.RecordUserTurn(text)
 
-	// Get LLM response
+	// Get LLM response, speaking each sentence as soon as it is written
 	log.Printf("Calling LLM with %d messages", len(a.messages))
-	response, err := a.llmService.Chat(a.ctx, a.messages, a.toolExecutor)
+	responseID := uuid.New().String()
+	speech := a.newSpeech(responseID)
+	response, err := a.llmService.ChatStream(a.ctx, a.messages, a.toolExecutor, func(chunk string) {
+		if a.onResponseDelta != nil {
+			a.onResponseDelta(responseID, chunk)
+		}
+		speech.say(chunk)
+	})
+	speech.end()
 	if err != nil {
 		log.Printf("LLM error: %v", err)
 		if a.onError != nil {
@@ -275,13 +308,10 @@ func (a *VoiceAgent) ProcessUserInput(text string) {
 	}
 	log.Printf("LLM response: %s", response.Content)
 
-	// Add assistant message
+	// Add the turn, tool calls and results included, so later turns can
+	// refer back to what was looked up
 	a.mu.Lock()
-	a.messages = append(a.messages, models.ConversationMsg{
-		Role:      "assistant",
-		Content:   response.Content,
-		Timestamp: time.Now(),
-	})
+	a.messages = append(a.messages, response.Messages...)
 	// Update user info in session
 	a.session.UserPhone = a.toolExecutor.GetUserPhone()
 	a.session.UserName = a.toolExecutor.GetUserName()
@@ -292,9 +322,6 @@ func (a *VoiceAgent) ProcessUserInput(text string) {
 		a.onAgentResponse(response.Content)
 	}
 
-	// Synthesize speech
-	a.synthesizeSpeech(response.Content)
-
 	// Check if should end
 	if response.ShouldEnd {
 		a.mu.Lock()
@@ -367,6 +394,8 @@ func (a *VoiceAgent) synthesizeSpeechREST(text string) {
 func (a *VoiceAgent) endConversation() {
 	log.Printf("[endConversation] Starting summary generation for session %s", a.ID)
 
+	a.toolExecutor.ReleaseHolds()
+
 	a.mu.RLock()
 	messages := make([]models.ConversationMsg, len(a.messages))
 	copy(messages, a.messages)
@@ -379,7 +408,7 @@ func (a *VoiceAgent) endConversation() {
 	userPhone := a.toolExecutor.GetUserPhone()
 	if userPhone != "" {
 		log.Printf("[endConversation] Fetching appointments for user: %s", userPhone)
-		apts, err := database.DB.GetUpcomingAppointments(userPhone)
+		apts, err := a.store.GetUpcomingAppointments(userPhone)
 		if err == nil {
 			appointments = apts
 			log.Printf("[endConversation] Found %d appointments", len(appointments))
@@ -422,8 +451,8 @@ func (a *VoiceAgent) endConversation() {
 	log.Printf("[endConversation] Costs calculated - Total: $%.4f", cost.TotalCost)
 
 	// Save summary to database
-	if database.DB != nil {
-		if err := database.DB.SaveCallSummary(summary); err != nil {
+	if a.store != nil {
+		if err := a.store.SaveCallSummary(summary); err != nil {
 			log.Printf("[endConversation] ERROR saving summary to database: %v", err)
 		} else {
 			log.Printf("[endConversation] Summary saved to database")
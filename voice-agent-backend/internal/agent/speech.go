@@ -0,0 +1,42 @@
+package agent
+
+import "log"
+
+// speech voices one reply a chunk at a time as the LLM writes it. Chunks
+// share a Cartesia context so they are spoken with continuous prosody; if
+// the stream is unavailable or fails, the rest of the reply goes over REST.
+type speech struct {
+	agent     *VoiceAgent
+	contextID string
+	started   bool
+	fallback  bool
+}
+
+func (a *VoiceAgent) newSpeech(contextID string) *speech {
+	return &speech{agent: a, contextID: contextID, fallback: a.ttsClient == nil}
+}
+
+// say speaks the next chunk of the reply
+func (s *speech) say(chunk string) {
+	if !s.fallback {
+		// Cartesia joins continued transcripts as-is, so keep the word break
+		err := s.agent.ttsClient.SpeakStreaming(chunk+" ", s.contextID, true)
+		if err == nil {
+			s.started = true
+			return
+		}
+		log.Printf("[speech] Streaming TTS failed, falling back to REST: %v", err)
+		s.fallback = true
+	}
+	s.agent.synthesizeSpeechREST(chunk)
+}
+
+// end tells Cartesia no more text is coming so it flushes the last audio
+func (s *speech) end() {
+	if !s.started || s.fallback {
+		return
+	}
+	if err := s.agent.ttsClient.SpeakStreaming("", s.contextID, false); err != nil {
+		log.Printf("[speech] Failed to close TTS context %s: %v", s.contextID, err)
+	}
+}
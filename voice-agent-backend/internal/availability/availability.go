@@ -0,0 +1,298 @@
+package availability
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// SlotChecker is the booking data the engine reads from the store.
+// CheckSlotAvailability reports whether a time range is free of booked
+// appointments and external busy time on a provider's calendar; an empty
+// providerID is the unassigned calendar. The range queries back multi-day
+// searches.
+type SlotChecker interface {
+	CheckSlotAvailability(providerID string, dateTime time.Time, duration int) (bool, error)
+	GetBookedAppointments(from, to time.Time) ([]models.Appointment, error)
+	GetSlotHolds(from, to time.Time) ([]models.SlotHold, error)
+	GetBusyBlocks(from, to time.Time) ([]models.BusyBlock, error)
+}
+
+// Holder identifies whose slot holds are ignored when checking availability:
+// a caller's own holds never block them. The zero Holder owns nothing.
+type Holder struct {
+	SessionID string
+	Phone     string
+}
+
+// owns reports whether hold belongs to the holder
+func (h Holder) owns(hold *models.SlotHold) bool {
+	return hold.OwnedBy(h.SessionID, h.Phone)
+}
+
+// Engine generates appointment slots from a Schedule and checks them against bookings
+type Engine struct {
+	schedule *Schedule
+	checker  SlotChecker
+}
+
+// NewEngine creates a slot engine for the given schedule
+func NewEngine(schedule *Schedule, checker SlotChecker) *Engine {
+	return &Engine{
+		schedule: schedule,
+		checker:  checker,
+	}
+}
+
+// Schedule returns the engine's schedule
+func (e *Engine) Schedule() *Schedule {
+	return e.schedule
+}
+
+// DefaultDuration returns the appointment length used when none is given
+func (e *Engine) DefaultDuration() int {
+	return e.schedule.DefaultDuration
+}
+
+// ScheduleFor returns the schedule a provider works to: the business schedule
+// with its weekly hours replaced by the provider's own, if set. A nil
+// provider gets the business schedule.
+func (e *Engine) ScheduleFor(provider *models.Provider) (*Schedule, error) {
+	if provider == nil || provider.Hours == "" {
+		return e.schedule, nil
+	}
+
+	hours, err := ParseWeeklyHours(provider.Hours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hours for provider %s: %w", provider.Name, err)
+	}
+
+	schedule := *e.schedule
+	schedule.WeeklyHours = hours
+	return &schedule, nil
+}
+
+// Slots returns every candidate slot on date with its booking availability
+// on the provider's calendar. A nil provider uses the business schedule and
+// the unassigned calendar. buffer minutes after each slot must also be free.
+// A free slot overlapping someone else's hold is reported as held rather than
+// available.
+func (e *Engine) Slots(date time.Time, duration, buffer int, provider *models.Provider, holder Holder) ([]models.TimeSlot, error) {
+	if duration <= 0 {
+		duration = e.schedule.DefaultDuration
+	}
+
+	schedule, err := e.ScheduleFor(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	providerID := ""
+	if provider != nil {
+		providerID = provider.ID
+	}
+
+	candidates := schedule.Candidates(date, duration, time.Now())
+	slots := make([]models.TimeSlot, 0, len(candidates))
+	if len(candidates) == 0 {
+		return slots, nil
+	}
+
+	block := time.Duration(duration+buffer) * time.Minute
+	var held []busyRange
+	holds, err := e.checker.GetSlotHolds(candidates[0], candidates[len(candidates)-1].Add(block))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	for i := range holds {
+		if holds[i].ProviderID == providerID && !holder.owns(&holds[i]) {
+			held = append(held, busyRange{holds[i].DateTime, holds[i].EndTime})
+		}
+	}
+
+	for _, slotTime := range candidates {
+		available, err := e.checker.CheckSlotAvailability(providerID, slotTime, duration+buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check availability: %w", err)
+		}
+		isHeld := available && overlapsAny(held, slotTime, slotTime.Add(block))
+
+		slots = append(slots, models.TimeSlot{
+			DateTime:   slotTime,
+			Available:  available && !isHeld,
+			Held:       isHeld,
+			Duration:   duration,
+			ProviderID: providerID,
+		})
+	}
+
+	return slots, nil
+}
+
+// AnySlots merges the slots of several providers ("whoever is free"). A time
+// is available if at least one provider is free then; ProviderID names the
+// first such provider in the given order. A time no provider is free at is
+// held if any provider's slot is held.
+func (e *Engine) AnySlots(date time.Time, duration, buffer int, providers []models.Provider, holder Holder) ([]models.TimeSlot, error) {
+	if len(providers) == 0 {
+		return e.Slots(date, duration, buffer, nil, holder)
+	}
+
+	merged := make(map[time.Time]*models.TimeSlot)
+	for i := range providers {
+		slots, err := e.Slots(date, duration, buffer, &providers[i], holder)
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			key := slot.DateTime.UTC()
+			existing, ok := merged[key]
+			if !ok {
+				slotCopy := slot
+				if !slot.Available {
+					slotCopy.ProviderID = ""
+				}
+				merged[key] = &slotCopy
+				continue
+			}
+			if !existing.Available && slot.Available {
+				existing.Available = true
+				existing.Held = false
+				existing.ProviderID = slot.ProviderID
+			}
+			if !existing.Available && slot.Held {
+				existing.Held = true
+			}
+		}
+	}
+
+	slots := make([]models.TimeSlot, 0, len(merged))
+	for _, slot := range merged {
+		slots = append(slots, *slot)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].DateTime.Before(slots[j].DateTime)
+	})
+	return slots, nil
+}
+
+// Validate checks that an appointment starting at start fits the provider's
+// schedule (the business schedule for a nil provider). The returned error is
+// phrased for the caller and safe to relay.
+func (e *Engine) Validate(start time.Time, duration int, now time.Time, provider *models.Provider) error {
+	schedule, err := e.ScheduleFor(provider)
+	if err != nil {
+		return err
+	}
+	return schedule.Validate(start, duration, now)
+}
+
+// Candidates returns the start times on date at which an appointment of the
+// given duration fits inside opening hours, avoids breaks and respects lead time.
+func (s *Schedule) Candidates(date time.Time, duration int, now time.Time) []time.Time {
+	if duration <= 0 {
+		duration = s.DefaultDuration
+	}
+
+	loc := s.Location
+	earliest := now.Add(s.LeadTime)
+
+	// Interpret the calendar date in the business timezone regardless of how it was parsed
+	year, month, day := date.Date()
+	weekday := time.Date(year, month, day, 12, 0, 0, 0, loc).Weekday()
+
+	var candidates []time.Time
+	for _, hours := range s.WeeklyHours[weekday] {
+		for start := hours.Start; start+duration <= hours.End; start += s.SlotInterval {
+			if s.inBreak(start, start+duration) {
+				continue
+			}
+
+			slotTime := time.Date(year, month, day, start/60, start%60, 0, 0, loc)
+			// Skip wall-clock times that don't exist on DST transition days
+			if slotTime.Hour() != start/60 || slotTime.Minute() != start%60 {
+				continue
+			}
+			if slotTime.Before(earliest) {
+				continue
+			}
+			candidates = append(candidates, slotTime)
+		}
+	}
+
+	return candidates
+}
+
+// Validate checks that an appointment starting at start fits the schedule.
+// The returned error is phrased for the caller and safe to relay.
+func (s *Schedule) Validate(start time.Time, duration int, now time.Time) error {
+	if duration <= 0 {
+		duration = s.DefaultDuration
+	}
+
+	if start.Before(now) {
+		return fmt.Errorf("cannot book appointments in the past")
+	}
+	if start.Before(now.Add(s.LeadTime)) {
+		return fmt.Errorf("appointments must be booked at least %s in advance", formatLeadTime(s.LeadTime))
+	}
+
+	local := start.In(s.Location)
+	startMin := local.Hour()*60 + local.Minute()
+	endMin := startMin + duration
+
+	hours := s.WeeklyHours[local.Weekday()]
+	if len(hours) == 0 {
+		return fmt.Errorf("we are closed on %ss", local.Weekday())
+	}
+
+	within := false
+	for _, r := range hours {
+		if r.Contains(startMin, endMin) {
+			within = true
+			break
+		}
+	}
+	if !within {
+		return fmt.Errorf("that time is outside business hours on %ss (%s)", local.Weekday(), formatRanges(hours))
+	}
+
+	if s.inBreak(startMin, endMin) {
+		return fmt.Errorf("that time overlaps a break (%s)", formatRanges(s.Breaks))
+	}
+
+	return nil
+}
+
+func (s *Schedule) inBreak(start, end int) bool {
+	for _, b := range s.Breaks {
+		if b.Overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatRanges(ranges []TimeRange) string {
+	result := ""
+	for i, r := range ranges {
+		if i > 0 {
+			result += ", "
+		}
+		result += r.String()
+	}
+	return result
+}
+
+func formatLeadTime(d time.Duration) string {
+	if d%time.Hour == 0 {
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
@@ -0,0 +1,216 @@
+package availability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestParseWeeklyHours(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[time.Weekday][]TimeRange
+		wantErr bool
+	}{
+		{"mon-wed 09:00-17:00", map[time.Weekday][]TimeRange{
+			time.Monday: {{540, 1020}}, time.Tuesday: {{540, 1020}}, time.Wednesday: {{540, 1020}},
+		}, false},
+		{"sat-mon 10:00-12:00, 13:00-14:00; tue 08:30-24:00", map[time.Weekday][]TimeRange{
+			time.Saturday: {{600, 720}, {780, 840}}, time.Sunday: {{600, 720}, {780, 840}}, time.Monday: {{600, 720}, {780, 840}},
+			time.Tuesday: {{510, 1440}},
+		}, false},
+		{"", map[time.Weekday][]TimeRange{}, false},
+		{"mon", nil, true},
+		{"funday 09:00-17:00", nil, true},
+		{"mon 17:00-09:00", nil, true},
+		{"mon 09:00-24:30", nil, true},
+		{"mon 9-17", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeeklyHours(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []time.Weekday
+	}{
+		{"mon,wed,fri", []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+		{"Monday-Wednesday", []time.Weekday{time.Monday, time.Tuesday, time.Wednesday}},
+		{"fri-sun, tue", []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Tuesday}},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeekdays(tt.spec)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+	if _, err := ParseWeekdays("weekends"); err == nil {
+		t.Error("expected an unknown weekday to be rejected")
+	}
+}
+
+// newTestSchedule is open 09:00-12:00 and 13:00-17:00 on weekdays with a
+// break at 10:00-10:30, in New York
+func newTestSchedule(t *testing.T) *Schedule {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	hours, err := ParseWeeklyHours("mon-fri 09:00-12:00,13:00-17:00")
+	if err != nil {
+		t.Fatalf("hours: %v", err)
+	}
+	return &Schedule{
+		WeeklyHours:     hours,
+		Breaks:          []TimeRange{{600, 630}},
+		SlotInterval:    30,
+		DefaultDuration: 60,
+		LeadTime:        2 * time.Hour,
+		Location:        newYork,
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	s := newTestSchedule(t)
+	loc := s.Location
+	monday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	clock := func(times []time.Time) string {
+		var out []string
+		for _, t := range times {
+			out = append(out, t.In(loc).Format("15:04"))
+		}
+		return strings.Join(out, " ")
+	}
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		duration int
+		now      time.Time
+		want     string
+	}{
+		{"default duration avoids the break", monday, 0, monday.AddDate(0, 0, -3), "09:00 10:30 11:00 13:00 13:30 14:00 14:30 15:00 15:30 16:00"},
+		{"short appointments", monday, 30, monday.AddDate(0, 0, -3), "09:00 09:30 10:30 11:00 11:30 13:00 13:30 14:00 14:30 15:00 15:30 16:00 16:30"},
+		{"lead time", monday, 90, time.Date(2026, 3, 9, 11, 0, 0, 0, loc), "13:00 13:30 14:00 14:30 15:00 15:30"},
+		{"too long for any opening", monday, 300, monday.AddDate(0, 0, -3), ""},
+		{"closed day", monday.AddDate(0, 0, -1), 30, monday.AddDate(0, 0, -3), ""},
+	}
+	for _, tt := range tests {
+		if got := clock(s.Candidates(tt.date, tt.duration, tt.now)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Night hours on the day the clocks go forward skip the missing hour
+	s.WeeklyHours[time.Sunday] = []TimeRange{{60, 240}}
+	sunday := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	if got, want := clock(s.Candidates(sunday, 60, sunday.AddDate(0, 0, -3))), "01:00 01:30 03:00"; got != want {
+		t.Errorf("DST: got %q, want %q", got, want)
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	s := newTestSchedule(t)
+	loc := s.Location
+	now := time.Date(2026, 3, 9, 8, 0, 0, 0, loc)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		duration int
+		err      string
+	}{
+		{"fits", at(10, 9, 0), 60, ""},
+		{"in the past", at(9, 7, 0), 30, "in the past"},
+		{"inside the lead time", at(9, 9, 30), 30, "at least 2 hours in advance"},
+		{"closed", at(14, 10, 0), 30, "closed on Saturdays"},
+		{"runs into lunch", at(10, 11, 30), 60, "outside business hours on Tuesdays (09:00-12:00, 13:00-17:00)"},
+		{"overlaps the break", at(10, 9, 30), 60, "overlaps a break (10:00-10:30)"},
+		{"default duration", at(10, 16, 30), 0, "outside business hours"},
+	}
+	for _, tt := range tests {
+		err := s.Validate(tt.start, tt.duration, now)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestFindNextSkipsBusyTime(t *testing.T) {
+	s := newTestSchedule(t)
+	loc := s.Location
+	store := database.NewMemoryStore()
+	engine := NewEngine(s, store)
+
+	// The first Monday at least two days away
+	day := time.Now().In(loc).AddDate(0, 0, 2)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+	}
+	if err := store.CreateAppointment(&models.Appointment{UserPhone: "+15550000001", DateTime: at(9, 0), Duration: 60, Status: models.StatusBooked}); err != nil {
+		t.Fatalf("create appointment: %v", err)
+	}
+	if err := store.CreateSlotHold(&models.SlotHold{DateTime: at(11, 0), EndTime: at(12, 0), SessionID: "other", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   SearchOptions
+		holder Holder
+		want   []time.Time
+	}{
+		{"around a booking and a hold", SearchOptions{Duration: 60, Limit: 3}, Holder{}, []time.Time{at(13, 0), at(13, 30), at(14, 0)}},
+		{"own hold does not block", SearchOptions{Duration: 60, Limit: 2}, Holder{SessionID: "other"}, []time.Time{at(10, 30), at(11, 0)}},
+		{"inside a window", SearchOptions{Duration: 30, Limit: 2, Windows: []TimeRange{{900, 960}}}, Holder{}, []time.Time{at(15, 0), at(15, 30)}},
+		{"buffer runs into the hold", SearchOptions{Duration: 30, Buffer: 30, Limit: 2}, Holder{}, []time.Time{at(13, 0), at(13, 30)}},
+		{"buffer beside the caller's own hold", SearchOptions{Duration: 30, Buffer: 30, Limit: 1}, Holder{SessionID: "other"}, []time.Time{at(10, 30)}},
+	}
+	for _, tt := range tests {
+		opts := tt.opts
+		opts.From, opts.Days, opts.Holder = at(0, 0), 1, tt.holder
+		slots, err := engine.FindNext(opts)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []time.Time
+		for _, slot := range slots {
+			got = append(got, slot.DateTime.In(loc))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tt.want[i]) {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package availability
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+)
+
+// TimeRange is a window within a day, in minutes since midnight [Start, End)
+type TimeRange struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether [start, end) lies entirely within the range
+func (r TimeRange) Contains(start, end int) bool {
+	return start >= r.Start && end <= r.End
+}
+
+// Overlaps reports whether [start, end) shares any minute with the range
+func (r TimeRange) Overlaps(start, end int) bool {
+	return start < r.End && end > r.Start
+}
+
+func (r TimeRange) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", r.Start/60, r.Start%60, r.End/60, r.End%60)
+}
+
+// Schedule describes when the business takes appointments
+type Schedule struct {
+	// WeeklyHours lists the opening hours for each weekday; missing days are closed
+	WeeklyHours map[time.Weekday][]TimeRange
+	// Breaks apply on every open day (e.g. lunch)
+	Breaks []TimeRange
+	// SlotInterval is the spacing between candidate start times, in minutes
+	SlotInterval int
+	// DefaultDuration is the appointment length used when none is given, in minutes
+	DefaultDuration int
+	// LeadTime is the minimum notice required before an appointment starts
+	LeadTime time.Duration
+	// HoldTTL is how long a caller can hold a slot before booking it
+	HoldTTL time.Duration
+	// Location is the timezone the hours are expressed in
+	Location *time.Location
+}
+
+// ScheduleFromConfig builds a Schedule from the application configuration
+func ScheduleFromConfig(cfg *config.Config) (*Schedule, error) {
+	hours, err := ParseWeeklyHours(cfg.BusinessHours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUSINESS_HOURS: %w", err)
+	}
+
+	breaks, err := ParseTimeRanges(cfg.BusinessBreaks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUSINESS_BREAKS: %w", err)
+	}
+
+	if cfg.SlotIntervalMinutes <= 0 {
+		return nil, fmt.Errorf("SLOT_INTERVAL_MINUTES must be positive")
+	}
+	if cfg.DefaultAppointmentMinutes <= 0 {
+		return nil, fmt.Errorf("DEFAULT_APPOINTMENT_MINUTES must be positive")
+	}
+	if cfg.BookingLeadTimeMinutes < 0 {
+		return nil, fmt.Errorf("BOOKING_LEAD_TIME_MINUTES cannot be negative")
+	}
+	if cfg.SlotHoldMinutes <= 0 {
+		return nil, fmt.Errorf("SLOT_HOLD_MINUTES must be positive")
+	}
+
+	loc, err := cfg.BusinessLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{
+		WeeklyHours:     hours,
+		Breaks:          breaks,
+		SlotInterval:    cfg.SlotIntervalMinutes,
+		DefaultDuration: cfg.DefaultAppointmentMinutes,
+		LeadTime:        time.Duration(cfg.BookingLeadTimeMinutes) * time.Minute,
+		HoldTTL:         time.Duration(cfg.SlotHoldMinutes) * time.Minute,
+		Location:        loc,
+	}, nil
+}
+
+// DateTimeLayout is the spoken form used in confirmations, e.g. "Monday, January 2, 2006 at 3:04 PM EST"
+const DateTimeLayout = "Monday, January 2, 2006 at 3:04 PM MST"
+
+// FormatDateTime formats t in the business timezone
+func (s *Schedule) FormatDateTime(t time.Time) string {
+	return t.In(s.Location).Format(DateTimeLayout)
+}
+
+// FormatTime formats the clock time of t in the business timezone, e.g. "3:04 PM"
+func (s *Schedule) FormatTime(t time.Time) string {
+	return t.In(s.Location).Format("3:04 PM")
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in the business timezone
+func (s *Schedule) ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, s.Location)
+}
+
+// ParseDateTime parses an ISO 8601 timestamp. Timestamps without an explicit
+// offset are interpreted in the business timezone rather than UTC.
+func (s *Schedule) ParseDateTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, s.Location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date_time %q, use ISO 8601 (e.g., 2024-01-15T10:00:00)", value)
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWeeklyHours parses specs like "mon-fri 09:00-17:00; sat 10:00-14:00".
+// Each entry is a weekday or weekday range followed by comma-separated time ranges.
+func ParseWeeklyHours(spec string) (map[time.Weekday][]TimeRange, error) {
+	hours := make(map[time.Weekday][]TimeRange)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Fields(entry)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%q: expected \"<days> <ranges>\"", entry)
+		}
+
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		ranges, err := ParseTimeRanges(strings.Join(fields[1:], ""))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, day := range days {
+			hours[day] = append(hours[day], ranges...)
+		}
+	}
+
+	return hours, nil
+}
+
+// ParseTimeRanges parses comma-separated ranges like "12:00-13:00,15:30-15:45"
+func ParseTimeRanges(spec string) ([]TimeRange, error) {
+	var ranges []TimeRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%q: expected HH:MM-HH:MM", part)
+		}
+
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("%q: end must be after start", part)
+		}
+
+		ranges = append(ranges, TimeRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// ParseWeekdays parses a comma-separated list of weekdays and ranges such as
+// "mon,wed,fri" or "mon-fri". Full day names are accepted too.
+func ParseWeekdays(spec string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		for i, bound := range bounds {
+			bound = strings.TrimSpace(bound)
+			if len(bound) > 3 {
+				bound = bound[:3]
+			}
+			bounds[i] = bound
+		}
+		parsed, err := parseWeekdays(strings.Join(bounds, "-"))
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, parsed...)
+	}
+	return days, nil
+}
+
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	spec = strings.ToLower(spec)
+
+	bounds := strings.SplitN(spec, "-", 2)
+	first, ok := weekdayNames[bounds[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown weekday %q", bounds[0])
+	}
+	if len(bounds) == 1 {
+		return []time.Weekday{first}, nil
+	}
+
+	last, ok := weekdayNames[bounds[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown weekday %q", bounds[1])
+	}
+
+	// Ranges may wrap around the week, e.g. "sat-mon"
+	days := []time.Weekday{first}
+	for day := first; day != last; {
+		day = (day + 1) % 7
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseClock(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("%q: expected HH:MM", s)
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("%q: invalid hour", s)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("%q: invalid minute", s)
+	}
+
+	return hour*60 + minute, nil
+}
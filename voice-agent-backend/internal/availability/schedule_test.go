@@ -0,0 +1,84 @@
+package availability
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+)
+
+func TestScheduleFromConfigTimezone(t *testing.T) {
+	base := config.Config{
+		BusinessHours:             "mon-fri 09:00-17:00",
+		SlotIntervalMinutes:       30,
+		DefaultAppointmentMinutes: 60,
+		SlotHoldMinutes:           5,
+	}
+	tests := []struct {
+		timezone string
+		want     string
+		err      string
+	}{
+		{"", time.Local.String(), ""},
+		{"America/New_York", "America/New_York", ""},
+		{"Mars/Olympus_Mons", "", "invalid BUSINESS_TIMEZONE"},
+	}
+	for _, tt := range tests {
+		cfg := base
+		cfg.BusinessTimezone = tt.timezone
+		s, err := ScheduleFromConfig(&cfg)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: got %v, want an error containing %q", tt.timezone, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			if strings.Contains(err.Error(), "unknown time zone") {
+				t.Skipf("timezone data unavailable: %v", err)
+			}
+			t.Errorf("%q: unexpected error %v", tt.timezone, err)
+			continue
+		}
+		if s.Location.String() != tt.want {
+			t.Errorf("%q: location is %s, want %s", tt.timezone, s.Location, tt.want)
+		}
+	}
+}
+
+func TestScheduleParsesAndFormatsInTheBusinessTimezone(t *testing.T) {
+	s := newTestSchedule(t)
+	loc := s.Location
+
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{"2026-03-10T14:00:00", time.Date(2026, 3, 10, 14, 0, 0, 0, loc), false},
+		{"2026-03-10 14:00", time.Date(2026, 3, 10, 14, 0, 0, 0, loc), false},
+		{"2026-03-10T14:00:00Z", time.Date(2026, 3, 10, 10, 0, 0, 0, loc), false},
+		{"2026-03-10T14:00:00-07:00", time.Date(2026, 3, 10, 17, 0, 0, 0, loc), false},
+		{"tomorrow at 2", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := s.ParseDateTime(tt.value)
+		if tt.err != (err != nil) || !got.Equal(tt.want) {
+			t.Errorf("ParseDateTime(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	day, err := s.ParseDate("2026-03-08")
+	if err != nil || !day.Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, loc)) {
+		t.Errorf("ParseDate: got %v, %v", day, err)
+	}
+
+	utc := time.Date(2026, 3, 10, 19, 30, 0, 0, time.UTC)
+	if got, want := s.FormatDateTime(utc), "Tuesday, March 10, 2026 at 3:30 PM EDT"; got != want {
+		t.Errorf("FormatDateTime: got %q, want %q", got, want)
+	}
+	if got, want := s.FormatTime(utc), "3:30 PM"; got != want {
+		t.Errorf("FormatTime: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package availability
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// Search limits
+const (
+	DefaultSearchDays  = 14
+	MaxSearchDays      = 90
+	DefaultSearchLimit = 3
+	MaxSearchLimit     = 20
+)
+
+// SearchOptions constrains a search for the next available slots
+type SearchOptions struct {
+	// From is the earliest acceptable start; times before now are skipped
+	From time.Time
+	// Days is how many calendar days to search from From's date
+	Days int
+	// Weekdays limits the days searched; empty means any open day
+	Weekdays []time.Weekday
+	// Windows limits start and end to these times of day; empty means any time
+	Windows  []TimeRange
+	Duration int
+	Buffer   int
+	// Providers are tried in order for each time ("whoever is free"); empty
+	// searches the unassigned calendar
+	Providers []models.Provider
+	// Holder's own holds do not block slots
+	Holder Holder
+	// Limit is the number of slots to return
+	Limit int
+}
+
+// busyRange is a blocked interval on one provider's calendar
+type busyRange struct {
+	start, end time.Time
+}
+
+// FindNext returns the earliest available slots matching opts, in order.
+// Bookings, holds and external busy time for the whole range are loaded in
+// one query each rather than checking slot by slot.
+func (e *Engine) FindNext(opts SearchOptions) ([]models.TimeSlot, error) {
+	now := time.Now()
+	if opts.From.Before(now) {
+		opts.From = now
+	}
+	if opts.Days <= 0 {
+		opts.Days = DefaultSearchDays
+	}
+	if opts.Days > MaxSearchDays {
+		opts.Days = MaxSearchDays
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+	if opts.Limit > MaxSearchLimit {
+		opts.Limit = MaxSearchLimit
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = e.schedule.DefaultDuration
+	}
+
+	loc := e.schedule.Location
+	first := opts.From.In(loc)
+	firstDay := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	rangeEnd := firstDay.AddDate(0, 0, opts.Days+1)
+
+	booked, err := e.checker.GetBookedAppointments(opts.From, rangeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booked appointments: %w", err)
+	}
+	holds, err := e.checker.GetSlotHolds(opts.From, rangeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	blocks, err := e.checker.GetBusyBlocks(opts.From, rangeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get busy blocks: %w", err)
+	}
+
+	busy := make(map[string][]busyRange)
+	for _, apt := range booked {
+		busy[apt.ProviderID] = append(busy[apt.ProviderID], busyRange{apt.DateTime, apt.BlockEnd()})
+	}
+	for i := range holds {
+		hold := &holds[i]
+		if opts.Holder.owns(hold) {
+			continue
+		}
+		busy[hold.ProviderID] = append(busy[hold.ProviderID], busyRange{hold.DateTime, hold.EndTime})
+	}
+	for _, block := range blocks {
+		busy[block.ProviderID] = append(busy[block.ProviderID], busyRange{block.DateTime, block.EndTime})
+	}
+
+	candidates := []*models.Provider{nil}
+	if len(opts.Providers) > 0 {
+		candidates = candidates[:0]
+		for i := range opts.Providers {
+			candidates = append(candidates, &opts.Providers[i])
+		}
+	}
+	schedules := make([]*Schedule, len(candidates))
+	for i, provider := range candidates {
+		if schedules[i], err = e.ScheduleFor(provider); err != nil {
+			return nil, err
+		}
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	for _, day := range opts.Weekdays {
+		weekdays[day] = true
+	}
+
+	block := time.Duration(opts.Duration+opts.Buffer) * time.Minute
+	var slots []models.TimeSlot
+	for d := 0; d < opts.Days && len(slots) < opts.Limit; d++ {
+		date := firstDay.AddDate(0, 0, d)
+		if len(weekdays) > 0 && !weekdays[date.Weekday()] {
+			continue
+		}
+
+		// The first free provider wins each start time
+		free := make(map[time.Time]string)
+		for i, provider := range candidates {
+			providerID := ""
+			if provider != nil {
+				providerID = provider.ID
+			}
+			for _, start := range schedules[i].Candidates(date, opts.Duration, now) {
+				key := start.UTC()
+				if _, taken := free[key]; taken || start.Before(opts.From) {
+					continue
+				}
+				if !inWindows(start.In(loc), opts.Duration, opts.Windows) {
+					continue
+				}
+				if overlapsAny(busy[providerID], start, start.Add(block)) {
+					continue
+				}
+				free[key] = providerID
+			}
+		}
+
+		times := make([]time.Time, 0, len(free))
+		for t := range free {
+			times = append(times, t)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		for _, t := range times {
+			if len(slots) == opts.Limit {
+				break
+			}
+			slots = append(slots, models.TimeSlot{
+				DateTime:   t.In(loc),
+				Available:  true,
+				Duration:   opts.Duration,
+				ProviderID: free[t],
+			})
+		}
+	}
+
+	return slots, nil
+}
+
+func inWindows(local time.Time, duration int, windows []TimeRange) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	start := local.Hour()*60 + local.Minute()
+	for _, w := range windows {
+		if w.Contains(start, start+duration) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsAny(ranges []busyRange, start, end time.Time) bool {
+	for _, r := range ranges {
+		if r.start.Before(end) && r.end.After(start) {
+			return true
+		}
+	}
+	return false
+}
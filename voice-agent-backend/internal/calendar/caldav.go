@@ -0,0 +1,167 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// CalDAVSync talks to a CalDAV server (RFC 4791). Each provider's calendar is
+// the collection <baseURL>/<provider ID>/ and appointments are stored as
+// <appointment ID>.ics resources in it.
+type CalDAVSync struct {
+	baseURL  string
+	username string
+	password string
+	loc      *time.Location
+	client   *http.Client
+}
+
+// NewCalDAVSync creates a CalDAV client using HTTP basic auth. Floating
+// times on the server are read in loc.
+func NewCalDAVSync(baseURL, username, password string, loc *time.Location) *CalDAVSync {
+	return &CalDAVSync{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		loc:      loc,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c *CalDAVSync) collectionURL(providerID string) string {
+	return c.baseURL + "/" + url.PathEscape(calendarName(providerID)) + "/"
+}
+
+func (c *CalDAVSync) eventURL(providerID, appointmentID string) string {
+	return c.collectionURL(providerID) + url.PathEscape(appointmentID) + Extension
+}
+
+func (c *CalDAVSync) PushAppointment(apt *models.Appointment) error {
+	if apt.Status == models.StatusCancelled {
+		return c.RemoveAppointment(apt.ProviderID, apt.ID)
+	}
+
+	resp, err := c.do("PUT", c.eventURL(apt.ProviderID, apt.ID), ContentType, eventData(apt), nil)
+	if err != nil {
+		return fmt.Errorf("failed to push appointment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to push appointment: %s", statusError(resp))
+	}
+	return nil
+}
+
+func (c *CalDAVSync) RemoveAppointment(providerID, appointmentID string) error {
+	resp, err := c.do("DELETE", c.eventURL(providerID, appointmentID), "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove appointment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Already gone is fine
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to remove appointment: %s", statusError(resp))
+	}
+	return nil
+}
+
+// PullBusy runs a calendar-query REPORT for events in the range, asking the
+// server to expand recurring events into instances
+func (c *CalDAVSync) PullBusy(providerID string, from, to time.Time) ([]models.BusyBlock, error) {
+	start, end := formatTime(from), formatTime(to)
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data>
+      <C:expand start="` + start + `" end="` + end + `"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="` + start + `" end="` + end + `"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+	resp, err := c.do("REPORT", c.collectionURL(providerID), "application/xml; charset=utf-8", []byte(body), map[string]string{"Depth": "1"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// A provider without a calendar on the server has no busy time
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("failed to query calendar: %s", statusError(resp))
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode calendar query: %w", err)
+	}
+
+	var blocks []models.BusyBlock
+	for _, r := range result.Responses {
+		for _, ps := range r.Propstats {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+			events, err := ParseEvents([]byte(ps.Prop.CalendarData), c.loc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", r.Href, err)
+			}
+			blocks = append(blocks, BusyBlocks(events, from, to)...)
+		}
+	}
+	return blocks, nil
+}
+
+// multistatus is the part of a WebDAV multistatus response we read
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"href"`
+		Propstats []struct {
+			Prop struct {
+				CalendarData string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func (c *CalDAVSync) do(method, target, contentType string, body []byte, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.client.Do(req)
+}
+
+func statusError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// DirSync keeps each provider's calendar as a directory of .ics files under
+// root (root/<provider ID>/<event>.ics), the layout vdirsyncer and similar
+// tools use. Appointments are written as <appointment ID>.ics; every other
+// file is read as the provider's own events.
+type DirSync struct {
+	root string
+	loc  *time.Location
+}
+
+// NewDirSync creates a directory calendar sync, creating root if needed.
+// Floating times in the files are read in loc.
+func NewDirSync(root string, loc *time.Location) (*DirSync, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create calendar directory: %w", err)
+	}
+	return &DirSync{root: root, loc: loc}, nil
+}
+
+// CalendarDir returns the directory holding a provider's calendar
+func (d *DirSync) CalendarDir(providerID string) string {
+	return filepath.Join(d.root, calendarName(providerID))
+}
+
+func (d *DirSync) eventPath(providerID, appointmentID string) string {
+	return filepath.Join(d.CalendarDir(providerID), filepath.Base(appointmentID)+Extension)
+}
+
+func (d *DirSync) PushAppointment(apt *models.Appointment) error {
+	if apt.Status == models.StatusCancelled {
+		return d.RemoveAppointment(apt.ProviderID, apt.ID)
+	}
+
+	if err := os.MkdirAll(d.CalendarDir(apt.ProviderID), 0o755); err != nil {
+		return fmt.Errorf("failed to create calendar directory: %w", err)
+	}
+
+	// Write then rename so readers never see a partial file
+	path := d.eventPath(apt.ProviderID, apt.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, eventData(apt), 0o644); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+func (d *DirSync) RemoveAppointment(providerID, appointmentID string) error {
+	if err := os.Remove(d.eventPath(providerID, appointmentID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove event: %w", err)
+	}
+	return nil
+}
+
+func (d *DirSync) PullBusy(providerID string, from, to time.Time) ([]models.BusyBlock, error) {
+	dir := d.CalendarDir(providerID)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar directory: %w", err)
+	}
+
+	var blocks []models.BusyBlock
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), Extension) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		// One bad file shouldn't hide the rest of the calendar
+		events, err := ParseEvents(data, d.loc)
+		if err != nil {
+			log.Printf("[calendar] Skipping %s: %v", filepath.Join(dir, entry.Name()), err)
+			continue
+		}
+		blocks = append(blocks, BusyBlocks(events, from, to)...)
+	}
+	return blocks, nil
+}
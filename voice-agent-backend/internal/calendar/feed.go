@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// ErrInvalidToken is returned for feed tokens that were never issued
+var ErrInvalidToken = errors.New("invalid calendar token")
+
+// Extension is the file extension feed and invite URLs end in
+const Extension = ".ics"
+
+// tokenBytes is how much randomness a feed token carries
+const tokenBytes = 32
+
+// Feeds issues per-user calendar URLs and renders their contents. A token
+// is random and stored against the user's phone number, so feed URLs reveal
+// nothing about the user and cannot be guessed for another.
+type Feeds struct {
+	store     database.Store
+	baseURL   string
+	name      string
+	organizer Organizer
+}
+
+// NewFeeds creates the calendar feeds from the configuration
+func NewFeeds(cfg *config.Config, store database.Store) *Feeds {
+	return &Feeds{
+		store:   store,
+		baseURL: strings.TrimRight(cfg.PublicBaseURL, "/"),
+		name:    cfg.CalendarName,
+		organizer: Organizer{
+			Name:  cfg.CalendarName,
+			Email: cfg.CalendarOrganizerEmail,
+		},
+	}
+}
+
+// Token returns the feed token for a phone number, issuing one the first time
+func (f *Feeds) Token(phone string) (string, error) {
+	random := make([]byte, tokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	return f.store.SaveCalendarToken(phone, base64.RawURLEncoding.EncodeToString(random))
+}
+
+// Phone returns the phone number a token was issued for
+func (f *Feeds) Phone(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidToken
+	}
+	phone, err := f.store.GetPhoneByCalendarToken(token)
+	if err != nil {
+		return "", err
+	}
+	if phone == "" {
+		return "", ErrInvalidToken
+	}
+	return phone, nil
+}
+
+// FeedURL returns the subscription URL listing a user's upcoming appointments
+func (f *Feeds) FeedURL(phone string) (string, error) {
+	token, err := f.Token(phone)
+	if err != nil {
+		return "", err
+	}
+	return f.baseURL + "/api/calendar/" + url.PathEscape(token) + Extension, nil
+}
+
+// AppointmentURL returns the URL of a single appointment's invite, or its
+// cancellation once cancelled
+func (f *Feeds) AppointmentURL(phone, appointmentID string) (string, error) {
+	token, err := f.Token(phone)
+	if err != nil {
+		return "", err
+	}
+	return f.baseURL + "/api/calendar/" + url.PathEscape(token) + "/" + url.PathEscape(appointmentID) + Extension, nil
+}
+
+// Feed renders a user's appointments that have not ended yet. Cancelled ones
+// are kept so subscribed calendars drop them.
+func (f *Feeds) Feed(appointments []models.Appointment, now time.Time) []byte {
+	upcoming := make([]models.Appointment, 0, len(appointments))
+	for _, apt := range appointments {
+		end := apt.DateTime.Add(time.Duration(apt.Duration) * time.Minute)
+		if end.After(now) {
+			upcoming = append(upcoming, apt)
+		}
+	}
+	return Encode(MethodPublish, f.name, f.organizer, upcoming, now)
+}
+
+// Invite renders one appointment, as a cancellation if it was cancelled
+func (f *Feeds) Invite(apt *models.Appointment, now time.Time) []byte {
+	method := MethodPublish
+	if apt.Status == models.StatusCancelled {
+		method = MethodCancel
+	}
+	return Encode(method, "", f.organizer, []models.Appointment{*apt}, now)
+}
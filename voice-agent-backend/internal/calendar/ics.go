@@ -0,0 +1,150 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// iCalendar methods (RFC 5546)
+const (
+	MethodPublish = "PUBLISH"
+	MethodCancel  = "CANCEL"
+)
+
+// ContentType is the MIME type of iCalendar data
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	productID = "-//Voice Agent//Appointments//EN"
+	uidDomain = "voice-agent"
+	icsLayout = "20060102T150405Z"
+)
+
+// Organizer is who appointments are with, written as ORGANIZER when Email is set
+type Organizer struct {
+	Name  string
+	Email string
+}
+
+// Encode renders appointments as an iCalendar object. Times are written in
+// UTC so no VTIMEZONE is needed. An empty method is left out, as calendar
+// stores require. Cancelled appointments get STATUS:CANCELLED, which together
+// with METHOD:CANCEL removes them from the attendee's calendar.
+func Encode(method, name string, organizer Organizer, appointments []models.Appointment, now time.Time) []byte {
+	var b icsWriter
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + productID)
+	b.line("CALSCALE:GREGORIAN")
+	if method != "" {
+		b.line("METHOD:" + method)
+	}
+	if name != "" {
+		b.line("X-WR-CALNAME:" + escapeText(name))
+	}
+
+	for i := range appointments {
+		writeEvent(&b, &appointments[i], organizer, now)
+	}
+
+	b.line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// UID returns the stable iCalendar UID of an appointment
+func UID(appointmentID string) string {
+	return appointmentID + "@" + uidDomain
+}
+
+// isOwnUID reports whether an event UID is one of our appointments
+func isOwnUID(uid string) bool {
+	return strings.HasSuffix(uid, "@"+uidDomain)
+}
+
+func writeEvent(b *icsWriter, apt *models.Appointment, organizer Organizer, now time.Time) {
+	summary := apt.Purpose
+	if summary == "" {
+		summary = "Appointment"
+	}
+	status := "CONFIRMED"
+	if apt.Status == models.StatusCancelled {
+		status = "CANCELLED"
+	}
+
+	b.line("BEGIN:VEVENT")
+	b.line("UID:" + UID(apt.ID))
+	b.line("DTSTAMP:" + formatTime(now))
+	b.line("DTSTART:" + formatTime(apt.DateTime))
+	b.line("DTEND:" + formatTime(apt.DateTime.Add(time.Duration(apt.Duration)*time.Minute)))
+	b.line("SUMMARY:" + escapeText(summary))
+	if apt.Notes != "" {
+		b.line("DESCRIPTION:" + escapeText(apt.Notes))
+	}
+	b.line("STATUS:" + status)
+	// Every update touches UpdatedAt, so seconds since creation only grow
+	b.line(fmt.Sprintf("SEQUENCE:%d", sequence(apt)))
+	if !apt.UpdatedAt.IsZero() {
+		b.line("LAST-MODIFIED:" + formatTime(apt.UpdatedAt))
+	}
+	if organizer.Email != "" {
+		param := ""
+		if organizer.Name != "" {
+			param = ";CN=" + quoteParam(organizer.Name)
+		}
+		b.line("ORGANIZER" + param + ":mailto:" + organizer.Email)
+	}
+	b.line("END:VEVENT")
+}
+
+func sequence(apt *models.Appointment) int64 {
+	if apt.CreatedAt.IsZero() || apt.UpdatedAt.Before(apt.CreatedAt) {
+		return 0
+	}
+	return int64(apt.UpdatedAt.Sub(apt.CreatedAt) / time.Second)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(icsLayout)
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// quoteParam quotes a parameter value, dropping characters it cannot contain
+func quoteParam(s string) string {
+	return `"` + strings.NewReplacer(`"`, "", "\r", "", "\n", " ").Replace(s) + `"`
+}
+
+// icsWriter writes content lines with CRLF endings, folded at 75 octets
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(s string) {
+	// Continuation lines start with a space, which counts toward the limit
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		// Don't split a UTF-8 sequence
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
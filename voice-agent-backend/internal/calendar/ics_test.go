@@ -0,0 +1,125 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestEncode(t *testing.T) {
+	now := time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	apt := models.Appointment{
+		ID:        "apt-1",
+		DateTime:  time.Date(2026, 3, 10, 14, 30, 0, 0, time.FixedZone("EST", -5*3600)),
+		Duration:  45,
+		Purpose:   "Cleaning, checkup; x-ray",
+		Notes:     "Bring forms\nand ID",
+		Status:    models.StatusBooked,
+		CreatedAt: created,
+		UpdatedAt: created.Add(90 * time.Second),
+	}
+	cancelled := apt
+	cancelled.ID, cancelled.Purpose, cancelled.Notes, cancelled.Status = "apt-2", "", "", models.StatusCancelled
+
+	tests := []struct {
+		name      string
+		method    string
+		organizer Organizer
+		apts      []models.Appointment
+		want      []string
+		absent    []string
+	}{
+		{
+			name:      "published appointment",
+			method:    MethodPublish,
+			organizer: Organizer{Name: `The "Best" Dental`, Email: "office@example.com"},
+			apts:      []models.Appointment{apt},
+			want: []string{
+				"METHOD:PUBLISH",
+				"UID:apt-1@voice-agent",
+				"DTSTAMP:20260306T090000Z",
+				"DTSTART:20260310T193000Z",
+				"DTEND:20260310T201500Z",
+				`SUMMARY:Cleaning\, checkup\; x-ray`,
+				`DESCRIPTION:Bring forms\nand ID`,
+				"STATUS:CONFIRMED",
+				"SEQUENCE:90",
+				"LAST-MODIFIED:20260306T080130Z",
+				`ORGANIZER;CN="The Best Dental":mailto:office@example.com`,
+			},
+		},
+		{
+			name:   "cancelled appointment",
+			method: MethodCancel,
+			apts:   []models.Appointment{cancelled},
+			want:   []string{"METHOD:CANCEL", "SUMMARY:Appointment", "STATUS:CANCELLED"},
+			absent: []string{"DESCRIPTION", "ORGANIZER"},
+		},
+		{
+			name:   "calendar store object",
+			apts:   []models.Appointment{apt, cancelled},
+			absent: []string{"METHOD"},
+		},
+	}
+	for _, tt := range tests {
+		data := string(Encode(tt.method, "", tt.organizer, tt.apts, now))
+		lines := strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n")
+		if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+			t.Errorf("%s: not a calendar object:\n%s", tt.name, data)
+		}
+		if got := strings.Count(data, "BEGIN:VEVENT"); got != len(tt.apts) {
+			t.Errorf("%s: got %d events, want %d", tt.name, got, len(tt.apts))
+		}
+		for _, want := range tt.want {
+			if !contains(lines, want) {
+				t.Errorf("%s: missing line %q in:\n%s", tt.name, want, data)
+			}
+		}
+		for _, absent := range tt.absent {
+			if strings.Contains(data, absent+":") || strings.Contains(data, absent+";") {
+				t.Errorf("%s: unexpected %s in:\n%s", tt.name, absent, data)
+			}
+		}
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	apt := models.Appointment{
+		ID:       "apt-1",
+		DateTime: time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC),
+		Duration: 30,
+		Notes:    strings.Repeat("é", 100),
+	}
+	data := string(Encode("", "Appointments", Organizer{}, []models.Appointment{apt}, time.Now()))
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets: %q", i, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a character: %q", i, line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	if !strings.Contains(unfolded.String(), "\nDESCRIPTION:"+strings.Repeat("é", 100)+"\n") {
+		t.Fatalf("folded description does not unfold to the original:\n%s", data)
+	}
+}
+
+func contains(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,322 @@
+package calendar
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/recurrence"
+)
+
+// Event is the part of a VEVENT that matters for busy time
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Cancelled   bool
+	Transparent bool   // TRANSP:TRANSPARENT, shown as free
+	RRule       string // empty for single events
+}
+
+// ParseEvents reads the VEVENTs of an iCalendar object. Floating times and
+// unknown TZIDs are read in loc.
+func ParseEvents(data []byte, loc *time.Location) ([]Event, error) {
+	var events []Event
+	var current *Event
+	var hasEnd bool
+	var duration time.Duration
+	depth := 0 // nesting inside the current VEVENT, e.g. VALARM
+
+	for _, line := range unfold(string(data)) {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && current == nil:
+			current = &Event{}
+			hasEnd, duration, depth = false, 0, 0
+			continue
+		case name == "BEGIN" && current != nil:
+			depth++
+			continue
+		case name == "END" && current != nil && depth > 0:
+			depth--
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT") && current != nil:
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", current.UID)
+			}
+			if !hasEnd {
+				switch {
+				case duration > 0:
+					current.End = current.Start.Add(duration)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				default:
+					current.End = current.Start
+				}
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		}
+		if current == nil || depth > 0 {
+			continue
+		}
+
+		var err error
+		switch name {
+		case "UID":
+			current.UID = value
+		case "DTSTART":
+			current.Start, current.AllDay, err = parseDateTime(value, params, loc)
+		case "DTEND":
+			current.End, _, err = parseDateTime(value, params, loc)
+			hasEnd = true
+		case "DURATION":
+			duration, err = parseDuration(value)
+		case "STATUS":
+			current.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case "TRANSP":
+			current.Transparent = strings.EqualFold(value, "TRANSPARENT")
+		case "RRULE":
+			current.RRule = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event %q: %w", current.UID, err)
+		}
+	}
+
+	return events, nil
+}
+
+// BusyBlocks turns events into busy time overlapping [from, to). Our own
+// appointments, cancelled and transparent events are skipped, and recurring
+// events are expanded within the window.
+func BusyBlocks(events []Event, from, to time.Time) []models.BusyBlock {
+	var blocks []models.BusyBlock
+	for _, ev := range events {
+		if isOwnUID(ev.UID) || ev.Cancelled || ev.Transparent || !ev.End.After(ev.Start) {
+			continue
+		}
+		length := ev.End.Sub(ev.Start)
+		for _, start := range occurrences(ev, from.Add(-length), to) {
+			end := start.Add(length)
+			if start.Before(to) && end.After(from) {
+				blocks = append(blocks, models.BusyBlock{
+					DateTime:    start,
+					EndTime:     end,
+					ExternalUID: ev.UID,
+				})
+			}
+		}
+	}
+	return blocks
+}
+
+// occurrences returns the event's start times up to to, expanding RRULEs the
+// recurrence package understands in the event's own timezone. Rules without
+// COUNT are moved forward to just before from; COUNT is counted from DTSTART.
+// Rules it cannot read are logged and fall back to the first occurrence.
+func occurrences(ev Event, from, to time.Time) []time.Time {
+	if ev.RRule == "" {
+		return []time.Time{ev.Start}
+	}
+	loc := ev.Start.Location()
+
+	// The recurrence package caps COUNT for series it books, so apply an
+	// external event's COUNT here and let the window end the rule instead
+	var parts []string
+	count := 0
+	hasUntil := false
+	for _, part := range strings.Split(ev.RRule, ";") {
+		name, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(strings.TrimSpace(name)) {
+		case "COUNT":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				log.Printf("[calendar] Event %s has an invalid RRULE COUNT %q; only its first occurrence is busy", ev.UID, value)
+				return []time.Time{ev.Start}
+			}
+			count = n
+			continue
+		case "UNTIL":
+			hasUntil = true
+		}
+		parts = append(parts, part)
+	}
+	if !hasUntil {
+		parts = append(parts, "UNTIL="+to.UTC().Format(icsLayout))
+	}
+	rule, err := recurrence.Parse(strings.Join(parts, ";"), loc)
+	if err != nil {
+		log.Printf("[calendar] Can't expand RRULE %q of event %s (%v); only its first occurrence is busy", ev.RRule, ev.UID, err)
+		return []time.Time{ev.Start}
+	}
+	if rule.Until.After(to) {
+		rule.Until = to
+	}
+
+	start := ev.Start
+	if count == 0 {
+		start = skipPeriods(rule, ev.Start, from, loc)
+	}
+
+	// Expand in batches past the occurrence cap, restarting at the last one
+	all := rule.Occurrences(start, loc)
+	for batch := all; len(batch) >= recurrence.MaxOccurrences-1 && (count == 0 || len(all) < count); {
+		next := rule.Occurrences(batch[len(batch)-1], loc)
+		if len(next) < 2 {
+			break
+		}
+		batch = next[1:]
+		all = append(all, batch...)
+	}
+	if count > 0 && len(all) > count {
+		all = all[:count]
+	}
+	return all
+}
+
+// skipPeriods advances start by whole rule periods while it stays before from
+func skipPeriods(rule *recurrence.Rule, start, from time.Time, loc *time.Location) time.Time {
+	local := start.In(loc)
+	var periods int
+	switch rule.Freq {
+	case recurrence.Daily:
+		periods = int(from.Sub(start).Hours()/24)/rule.Interval - 1
+	case recurrence.Weekly:
+		periods = int(from.Sub(start).Hours()/(24*7))/rule.Interval - 1
+	case recurrence.Monthly:
+		months := (from.Year()-local.Year())*12 + int(from.Month()) - int(local.Month())
+		periods = months/rule.Interval - 1
+	}
+	if periods <= 0 {
+		return start
+	}
+
+	days, months := 0, 0
+	switch rule.Freq {
+	case recurrence.Daily:
+		days = periods * rule.Interval
+	case recurrence.Weekly:
+		days = periods * rule.Interval * 7
+	case recurrence.Monthly:
+		months = periods * rule.Interval
+	}
+	return local.AddDate(0, months, days)
+}
+
+// unfold joins folded content lines (RFC 5545 section 3.1)
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitProperty splits "NAME;PARAM=VALUE:value" into its parts. Parameter
+// names are upper-cased; colons inside quoted parameter values are skipped.
+func splitProperty(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, strings.TrimSpace(line[colon+1:]), true
+}
+
+// parseDateTime reads a DATE or DATE-TIME value, reporting whether it was a date
+func parseDateTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsLayout, value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads a DURATION value such as "PT1H30M" or "P1D"
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if s == value || strings.HasPrefix(value, "-") {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid DURATION %q", value)
+		}
+		num = ""
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid DURATION %q", value)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusyBlocksExpandsRecurringEvents(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	day := func(year int, month time.Month, d, hour int) time.Time {
+		return time.Date(year, month, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		rrule    string
+		from, to time.Time
+		want     int
+	}{
+		{"single event", day(2026, 3, 2, 9), "", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 1},
+		{"open-ended weekly years before the window", day(2022, 1, 3, 9), "FREQ=WEEKLY;BYDAY=MO,WE", day(2026, 3, 2, 0), day(2026, 3, 16, 0), 4},
+		{"UNTIL rule past the occurrence cap", day(2024, 1, 1, 9), "FREQ=DAILY;UNTIL=20271231T000000Z", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 7},
+		{"UNTIL rule ended before the window", day(2024, 1, 1, 9), "FREQ=DAILY;UNTIL=20240201T000000Z", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 0},
+		{"COUNT past the occurrence cap", day(2026, 1, 1, 9), "FREQ=DAILY;COUNT=100", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 7},
+		{"COUNT counted from DTSTART", day(2026, 1, 1, 9), "FREQ=DAILY;COUNT=62", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 3},
+		{"COUNT used up before the window", day(2026, 1, 1, 9), "FREQ=WEEKLY;COUNT=3", day(2026, 3, 1, 0), day(2026, 3, 8, 0), 0},
+		{"monthly on a day some months lack", day(2025, 1, 31, 9), "FREQ=MONTHLY;UNTIL=20260101T000000Z", day(2025, 1, 1, 0), day(2026, 1, 1, 0), 7},
+		{"unsupported rule keeps its first occurrence", day(2026, 3, 2, 9), "FREQ=MONTHLY;BYMONTHDAY=2,16", day(2026, 3, 1, 0), day(2026, 3, 31, 0), 1},
+		{"unsupported rule outside the window", day(2025, 3, 2, 9), "FREQ=YEARLY", day(2026, 3, 1, 0), day(2026, 3, 31, 0), 0},
+	}
+	for _, tt := range tests {
+		events := []Event{{UID: "ext-1", Start: tt.start, End: tt.start.Add(time.Hour), RRule: tt.rrule}}
+		blocks := BusyBlocks(events, tt.from, tt.to)
+		if len(blocks) != tt.want {
+			t.Errorf("%s: got %d blocks, want %d: %v", tt.name, len(blocks), tt.want, blocks)
+		}
+		for _, block := range blocks {
+			if !block.DateTime.Before(tt.to) || !block.EndTime.After(tt.from) {
+				t.Errorf("%s: block %v outside the window", tt.name, block)
+			}
+		}
+	}
+
+	// A weekly 10:00 meeting stays at 10:00 local time across the DST change
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, newYork)
+	events := []Event{{UID: "ext-2", Start: start, End: start.Add(time.Hour), RRule: "FREQ=WEEKLY"}}
+	blocks := BusyBlocks(events, time.Date(2026, 3, 1, 0, 0, 0, 0, newYork), time.Date(2026, 3, 22, 0, 0, 0, 0, newYork))
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 weekly blocks, got %v", blocks)
+	}
+	for _, block := range blocks {
+		if local := block.DateTime.In(newYork); local.Hour() != 10 {
+			t.Errorf("expected 10:00 local, got %s", local)
+		}
+	}
+}
+
+func TestParseEventsReadsRecurringEvents(t *testing.T) {
+	data := []byte("BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:standup@example.com\r\n" +
+		"DTSTART;TZID=America/New_York:20260302T100000\r\n" +
+		"DURATION:PT30M\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;\r\n" +
+		" COUNT=10\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"TRIGGER:-PT5M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:holiday@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20260303\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n")
+
+	events, err := ParseEvents(data, time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	standup := events[0]
+	if standup.RRule != "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=10" || standup.End.Sub(standup.Start) != 30*time.Minute {
+		t.Fatalf("unexpected event %+v", standup)
+	}
+	holiday := events[1]
+	if !holiday.AllDay || !holiday.Transparent || holiday.End.Sub(holiday.Start) != 24*time.Hour {
+		t.Fatalf("unexpected event %+v", holiday)
+	}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	blocks := BusyBlocks(events, from, from.AddDate(0, 1, 0))
+	if len(blocks) != 10 {
+		t.Fatalf("expected the 10 counted standups and no holiday, got %d: %v", len(blocks), blocks)
+	}
+}
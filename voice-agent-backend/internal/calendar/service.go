@@ -0,0 +1,222 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// Sync defaults
+const (
+	DefaultSyncInterval = 5 * time.Minute
+	DefaultSyncDays     = 60
+)
+
+// pushQueueSize bounds appointment changes waiting to be pushed
+const pushQueueSize = 256
+
+// SyncService keeps external calendars and the store in step. Appointment
+// changes made through Store() are queued and pushed in order by one worker,
+// so a slow calendar server never delays a booking, and busy time for every
+// provider is pulled into the store on a ticker.
+type SyncService struct {
+	store  database.Store
+	sync   CalendarSync
+	days   int
+	pushes chan pushJob
+	ticker *time.Ticker
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// pushJob is one queued change: an appointment to push, or an event to
+// remove from a provider's calendar
+type pushJob struct {
+	appointment   *models.Appointment
+	providerID    string
+	appointmentID string
+}
+
+// id returns the ID of the appointment the job is for
+func (j pushJob) id() string {
+	if j.appointment != nil {
+		return j.appointment.ID
+	}
+	return j.appointmentID
+}
+
+// NewSyncService starts pushing queued changes and pulling busy time, the
+// first pull straight away
+func NewSyncService(cfg *config.Config, store database.Store, calendarSync CalendarSync) *SyncService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interval := time.Duration(cfg.CalendarSyncMinutes) * time.Minute
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	days := cfg.CalendarSyncDays
+	if days <= 0 {
+		days = DefaultSyncDays
+	}
+
+	s := &SyncService{
+		store:  store,
+		sync:   calendarSync,
+		days:   days,
+		pushes: make(chan pushJob, pushQueueSize),
+		ticker: time.NewTicker(interval),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	s.wg.Add(2)
+	go s.pushLoop()
+	go s.pullLoop()
+
+	return s
+}
+
+// Store wraps the store so appointment writes are pushed to external calendars
+func (s *SyncService) Store() database.Store {
+	return &syncedStore{Store: s.store, service: s}
+}
+
+// Stop stops pulling, pushes the changes already queued and waits for both.
+// The queue is never closed, so a change saved while stopping cannot panic;
+// it is dropped instead.
+func (s *SyncService) Stop() {
+	s.ticker.Stop()
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Pull replaces the stored busy time of every provider, and of the
+// unassigned calendar, for the sync window
+func (s *SyncService) Pull() error {
+	providers, err := s.store.GetProviders()
+	if err != nil {
+		return fmt.Errorf("failed to get providers: %w", err)
+	}
+
+	from := time.Now()
+	to := from.AddDate(0, 0, s.days)
+	providerIDs := []string{""}
+	for _, provider := range providers {
+		providerIDs = append(providerIDs, provider.ID)
+	}
+
+	var firstErr error
+	for _, providerID := range providerIDs {
+		blocks, err := s.sync.PullBusy(providerID, from, to)
+		if err == nil {
+			err = s.store.ReplaceBusyBlocks(providerID, from, to, blocks)
+		}
+		if err != nil {
+			// Keep the last good blocks for this provider and try the others
+			log.Printf("[calendar] Failed to sync busy time for %s: %v", calendarName(providerID), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// enqueue queues a change without waiting, dropping it if the service has
+// stopped or the queue is full, so a slow calendar server never holds up a
+// booking
+func (s *SyncService) enqueue(job pushJob) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	select {
+	case s.pushes <- job:
+	default:
+		log.Printf("[calendar] Push queue full, dropping change to appointment %s", job.id())
+	}
+}
+
+func (s *SyncService) pushLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case job := <-s.pushes:
+			s.push(job)
+		case <-s.ctx.Done():
+			// Push what was queued before stopping
+			for {
+				select {
+				case job := <-s.pushes:
+					s.push(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *SyncService) push(job pushJob) {
+	var err error
+	if job.appointment != nil {
+		err = s.sync.PushAppointment(job.appointment)
+	} else {
+		err = s.sync.RemoveAppointment(job.providerID, job.appointmentID)
+	}
+	if err != nil {
+		log.Printf("[calendar] Failed to sync appointment %s: %v", job.id(), err)
+	}
+}
+
+func (s *SyncService) pullLoop() {
+	defer s.wg.Done()
+	s.Pull()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.ticker.C:
+			s.Pull()
+		}
+	}
+}
+
+// syncedStore queues appointment changes for the calendar sync after they are saved
+type syncedStore struct {
+	database.Store
+	service *SyncService
+}
+
+func (s *syncedStore) CreateAppointment(apt *models.Appointment) error {
+	if err := s.Store.CreateAppointment(apt); err != nil {
+		return err
+	}
+	aptCopy := *apt
+	s.service.enqueue(pushJob{appointment: &aptCopy})
+	return nil
+}
+
+func (s *syncedStore) UpdateAppointment(apt *models.Appointment) error {
+	previous, err := s.Store.GetAppointmentByID(apt.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.UpdateAppointment(apt); err != nil {
+		return err
+	}
+
+	// Moving to another provider moves the event to their calendar
+	if previous != nil && previous.ProviderID != apt.ProviderID {
+		s.service.enqueue(pushJob{providerID: previous.ProviderID, appointmentID: apt.ID})
+	}
+	aptCopy := *apt
+	s.service.enqueue(pushJob{appointment: &aptCopy})
+	return nil
+}
@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// CalendarSync connects providers' external calendars. Each provider has its
+// own calendar, named by its ID; appointments with no provider use
+// DefaultCalendar.
+type CalendarSync interface {
+	// PushAppointment creates or updates the appointment's event on its
+	// provider's calendar, or removes it once the appointment is cancelled
+	PushAppointment(apt *models.Appointment) error
+	// RemoveAppointment deletes an appointment's event from a provider's
+	// calendar, e.g. after it moved to another provider
+	RemoveAppointment(providerID, appointmentID string) error
+	// PullBusy returns busy time on a provider's calendar overlapping
+	// [from, to), leaving out events pushed for our own appointments
+	PullBusy(providerID string, from, to time.Time) ([]models.BusyBlock, error)
+}
+
+// DefaultCalendar is the calendar of appointments with no provider
+const DefaultCalendar = "default"
+
+// Sync drivers
+const (
+	SyncNone   = ""
+	SyncCalDAV = "caldav"
+	SyncDir    = "dir"
+)
+
+// NewSync creates the calendar sync selected by cfg.CalendarSync. It returns
+// nil when sync is disabled.
+func NewSync(cfg *config.Config) (CalendarSync, error) {
+	loc, err := cfg.BusinessLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.CalendarSync {
+	case SyncNone:
+		return nil, nil
+	case SyncCalDAV:
+		if cfg.CalDAVURL == "" {
+			return nil, fmt.Errorf("CALDAV_URL is required for CalDAV calendar sync")
+		}
+		return NewCalDAVSync(cfg.CalDAVURL, cfg.CalDAVUsername, cfg.CalDAVPassword, loc), nil
+	case SyncDir:
+		if cfg.CalendarSyncDir == "" {
+			return nil, fmt.Errorf("CALENDAR_SYNC_DIR is required for directory calendar sync")
+		}
+		return NewDirSync(cfg.CalendarSyncDir, loc)
+	default:
+		return nil, fmt.Errorf("unknown calendar sync: %s", cfg.CalendarSync)
+	}
+}
+
+// calendarName returns the calendar a provider's events live in
+func calendarName(providerID string) string {
+	if providerID == "" {
+		return DefaultCalendar
+	}
+	return providerID
+}
+
+// eventData renders an appointment as a stored calendar object
+func eventData(apt *models.Appointment) []byte {
+	return Encode("", "", Organizer{}, []models.Appointment{*apt}, time.Now())
+}
@@ -1,16 +1,19 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	// Server
-	Port        string
-	Environment string
+	Port          string
+	Environment   string
+	PublicBaseURL string // prefix for links handed to users, e.g. "https://api.example.com"
 
 	// LiveKit
 	LiveKitURL       string
@@ -24,28 +27,76 @@ type Config struct {
 	CartesiaAPIKey  string
 	CartesiaVoiceID string
 
-	// LLM (OpenAI or compatible)
-	LLMProvider string
-	LLMAPIKey   string
-	LLMBaseURL  string
-	LLMModel    string
+	// LLM ("openai", "anthropic", "ollama", "llamacpp" or "mock")
+	LLMProvider   string
+	LLMAPIKey     string
+	LLMBaseURL    string // empty uses the provider's default endpoint
+	LLMModel      string // empty uses the provider's default model
+	LLMMockScript string // JSON file of responses replayed by the mock provider
+	// Tool results from earlier turns longer than this are cut when the
+	// history is sent to the model; 0 keeps them whole
+	LLMToolResultMaxChars int
+	// Estimated tokens of context sent per request before older turns are
+	// summarized; 0 sends the whole call
+	LLMContextTokens int
 
 	// Avatar (Beyond Presence / Tavus)
 	AvatarProvider string
 	AvatarAPIKey   string
 	AvatarAvatarID string
 
+	// Database ("supabase", "postgres" or "memory")
+	DatabaseDriver string
+	DatabaseURL    string
+
 	// Supabase
 	SupabaseURL    string
 	SupabaseAPIKey string
 
+	// Scheduling
+	BusinessTimezone          string // IANA name, e.g. "America/New_York"; empty uses the server's zone
+	BusinessHours             string // e.g. "mon-fri 09:00-17:00; sat 10:00-14:00"
+	BusinessBreaks            string // e.g. "12:00-13:00"
+	SlotIntervalMinutes       int
+	DefaultAppointmentMinutes int
+	BookingLeadTimeMinutes    int
+	WaitlistHoldMinutes       int // how long a freed slot is held for a waitlisted caller
+	SlotHoldMinutes           int // how long hold_slot reserves a slot during a call
+	NoShowGraceMinutes        int // how long after the start an appointment without check-in becomes a no-show
+
+	// Booking limits per caller (0 switches a limit off)
+	MaxUpcomingAppointments  int
+	AllowOverlappingBookings bool // let one caller book appointments that overlap each other
+	DuplicateWindowDays      int  // warn about a near-identical appointment within this many days
+
+	// Cancellation policy (0 switches a rule off)
+	CancelNoticeMinutes     int // cancelling with less notice is refused
+	LateCancelHours         int // cancelling within this many hours of the start is charged LateCancelFeeCents
+	LateCancelFeeCents      int64
+	RescheduleNoticeMinutes int // rescheduling with less notice is refused
+	MaxReschedules          int // times one appointment may be rescheduled
+
+	// Calendar feeds
+	CalendarName           string
+	CalendarOrganizerEmail string // ORGANIZER of invites and cancellations; optional
+
+	// External calendar sync ("", "caldav" or "dir")
+	CalendarSync        string
+	CalendarSyncDir     string // root of the per-provider .ics directories
+	CalDAVURL           string // base URL holding one collection per provider
+	CalDAVUsername      string
+	CalDAVPassword      string
+	CalendarSyncMinutes int // how often busy time is pulled
+	CalendarSyncDays    int // how far ahead busy time is pulled
+
 	// Pricing (per minute/token for cost estimation)
 	DeepgramPricePerMin  float64
 	CartesiaPricePerChar float64
 	LLMPricePerToken     float64
 
 	// Stripe
-	StripeSecretKey string
+	StripeSecretKey      string
+	StripePublishableKey string // lets the client show a payment form; without it only card tokens are charged
 }
 
 var AppConfig *Config
@@ -57,9 +108,29 @@ func Load() (*Config, error) {
 	cartesiaPrice, _ := strconv.ParseFloat(getEnv("CARTESIA_PRICE_PER_CHAR", "0.000015"), 64)
 	llmPrice, _ := strconv.ParseFloat(getEnv("LLM_PRICE_PER_TOKEN", "0.00003"), 64)
 
+	slotInterval, _ := strconv.Atoi(getEnv("SLOT_INTERVAL_MINUTES", "30"))
+	defaultAppointment, _ := strconv.Atoi(getEnv("DEFAULT_APPOINTMENT_MINUTES", "30"))
+	leadTime, _ := strconv.Atoi(getEnv("BOOKING_LEAD_TIME_MINUTES", "0"))
+	waitlistHold, _ := strconv.Atoi(getEnv("WAITLIST_HOLD_MINUTES", "30"))
+	slotHold, _ := strconv.Atoi(getEnv("SLOT_HOLD_MINUTES", "5"))
+	noShowGrace, _ := strconv.Atoi(getEnv("NO_SHOW_GRACE_MINUTES", "15"))
+	maxUpcoming, _ := strconv.Atoi(getEnv("MAX_UPCOMING_APPOINTMENTS", "5"))
+	allowOverlap, _ := strconv.ParseBool(getEnv("ALLOW_OVERLAPPING_BOOKINGS", "false"))
+	duplicateDays, _ := strconv.Atoi(getEnv("DUPLICATE_WINDOW_DAYS", "7"))
+	cancelNotice, _ := strconv.Atoi(getEnv("CANCEL_NOTICE_MINUTES", "0"))
+	lateCancelHours, _ := strconv.Atoi(getEnv("LATE_CANCEL_HOURS", "24"))
+	lateCancelFee, _ := strconv.ParseInt(getEnv("LATE_CANCEL_FEE_CENTS", "0"), 10, 64)
+	rescheduleNotice, _ := strconv.Atoi(getEnv("RESCHEDULE_NOTICE_MINUTES", "0"))
+	maxReschedules, _ := strconv.Atoi(getEnv("MAX_RESCHEDULES", "0"))
+	syncMinutes, _ := strconv.Atoi(getEnv("CALENDAR_SYNC_MINUTES", "5"))
+	syncDays, _ := strconv.Atoi(getEnv("CALENDAR_SYNC_DAYS", "60"))
+	toolResultMax, _ := strconv.Atoi(getEnv("LLM_TOOL_RESULT_MAX_CHARS", "0"))
+	contextTokens, _ := strconv.Atoi(getEnv("LLM_CONTEXT_TOKENS", "4000"))
+
 	AppConfig = &Config{
-		Port:        getEnv("PORT", "8080"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Port:          getEnv("PORT", "8080"),
+		Environment:   getEnv("ENVIRONMENT", "development"),
+		PublicBaseURL: getEnv("PUBLIC_BASE_URL", ""),
 
 		LiveKitURL:       getEnv("LIVEKIT_URL", ""),
 		LiveKitAPIKey:    getEnv("LIVEKIT_API_KEY", ""),
@@ -70,26 +141,79 @@ func Load() (*Config, error) {
 		CartesiaAPIKey:  getEnv("CARTESIA_API_KEY", ""),
 		CartesiaVoiceID: getEnv("CARTESIA_VOICE_ID", "a0e99841-438c-4a64-b679-ae501e7d6091"),
 
-		LLMProvider: getEnv("LLM_PROVIDER", "openai"),
-		LLMAPIKey:   getEnv("LLM_API_KEY", ""),
-		LLMBaseURL:  getEnv("LLM_BASE_URL", "https://api.openai.com/v1"),
-		LLMModel:    getEnv("LLM_MODEL", "gpt-4o"),
+		LLMProvider:   getEnv("LLM_PROVIDER", "openai"),
+		LLMAPIKey:     getEnv("LLM_API_KEY", ""),
+		LLMBaseURL:    getEnv("LLM_BASE_URL", ""),
+		LLMModel:      getEnv("LLM_MODEL", ""),
+		LLMMockScript: getEnv("LLM_MOCK_SCRIPT", ""),
+
+		LLMToolResultMaxChars: toolResultMax,
+		LLMContextTokens:      contextTokens,
 
 		AvatarProvider: getEnv("AVATAR_PROVIDER", "tavus"),
 		AvatarAPIKey:   getEnv("AVATAR_API_KEY", ""),
 		AvatarAvatarID: getEnv("AVATAR_ID", ""),
 
+		DatabaseDriver: getEnv("DATABASE_DRIVER", "supabase"),
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+
 		SupabaseURL:    getEnv("SUPABASE_URL", ""),
 		SupabaseAPIKey: getEnv("SUPABASE_API_KEY", ""),
 
+		BusinessTimezone:          getEnv("BUSINESS_TIMEZONE", ""),
+		BusinessHours:             getEnv("BUSINESS_HOURS", "mon-sun 09:00-17:00"),
+		BusinessBreaks:            getEnv("BUSINESS_BREAKS", ""),
+		SlotIntervalMinutes:       slotInterval,
+		DefaultAppointmentMinutes: defaultAppointment,
+		BookingLeadTimeMinutes:    leadTime,
+		WaitlistHoldMinutes:       waitlistHold,
+		SlotHoldMinutes:           slotHold,
+		NoShowGraceMinutes:        noShowGrace,
+
+		MaxUpcomingAppointments:  maxUpcoming,
+		AllowOverlappingBookings: allowOverlap,
+		DuplicateWindowDays:      duplicateDays,
+
+		CancelNoticeMinutes:     cancelNotice,
+		LateCancelHours:         lateCancelHours,
+		LateCancelFeeCents:      lateCancelFee,
+		RescheduleNoticeMinutes: rescheduleNotice,
+		MaxReschedules:          maxReschedules,
+
+		CalendarName:           getEnv("CALENDAR_NAME", "Appointments"),
+		CalendarOrganizerEmail: getEnv("CALENDAR_ORGANIZER_EMAIL", ""),
+
+		CalendarSync:        getEnv("CALENDAR_SYNC", ""),
+		CalendarSyncDir:     getEnv("CALENDAR_SYNC_DIR", ""),
+		CalDAVURL:           getEnv("CALDAV_URL", ""),
+		CalDAVUsername:      getEnv("CALDAV_USERNAME", ""),
+		CalDAVPassword:      getEnv("CALDAV_PASSWORD", ""),
+		CalendarSyncMinutes: syncMinutes,
+		CalendarSyncDays:    syncDays,
+
 		DeepgramPricePerMin:  deepgramPrice,
 		CartesiaPricePerChar: cartesiaPrice,
 		LLMPricePerToken:     llmPrice,
+
+		StripeSecretKey:      getEnv("STRIPE_SECRET_KEY", ""),
+		StripePublishableKey: getEnv("STRIPE_PUBLISHABLE_KEY", ""),
 	}
 
 	return AppConfig, nil
 }
 
+// BusinessLocation returns the timezone business hours and appointment times are expressed in
+func (c *Config) BusinessLocation() (*time.Location, error) {
+	if c.BusinessTimezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.BusinessTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUSINESS_TIMEZONE %q: %w", c.BusinessTimezone, err)
+	}
+	return loc, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,644 @@
+package database
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// MemoryStore is a thread-safe in-memory Store for tests and offline demos
+type MemoryStore struct {
+	mu           sync.RWMutex
+	users        map[string]*models.User        // key: phone number
+	appointments map[string]*models.Appointment // key: appointment ID
+	providers    map[string]*models.Provider    // key: provider ID
+	services     map[string]*models.Service     // key: service ID
+	series       map[string]*models.AppointmentSeries
+	holds        map[string]*models.SlotHold
+	busy         []models.BusyBlock
+	waitlist     map[string]*models.WaitlistEntry
+	summaries    []models.CallSummary
+	tokens       map[string]string // calendar token -> phone
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:        make(map[string]*models.User),
+		appointments: make(map[string]*models.Appointment),
+		providers:    make(map[string]*models.Provider),
+		services:     make(map[string]*models.Service),
+		series:       make(map[string]*models.AppointmentSeries),
+		holds:        make(map[string]*models.SlotHold),
+		waitlist:     make(map[string]*models.WaitlistEntry),
+		tokens:       make(map[string]string),
+	}
+}
+
+// User operations
+func (m *MemoryStore) GetUserByPhone(phone string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[phone]
+	if !ok {
+		return nil, nil
+	}
+	userCopy := *user
+	return &userCopy, nil
+}
+
+func (m *MemoryStore) CreateUser(user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	userCopy := *user
+	m.users[user.PhoneNumber] = &userCopy
+	return nil
+}
+
+func (m *MemoryStore) UpdateUser(user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for phone, existing := range m.users {
+		if existing.ID == user.ID {
+			delete(m.users, phone)
+			break
+		}
+	}
+	user.UpdatedAt = time.Now()
+	userCopy := *user
+	m.users[user.PhoneNumber] = &userCopy
+	return nil
+}
+
+// Appointment operations
+func (m *MemoryStore) CreateAppointment(apt *models.Appointment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if apt.ID == "" {
+		apt.ID = uuid.New().String()
+	}
+	if m.overlapsLocked(apt) {
+		return ErrSlotUnavailable
+	}
+	now := time.Now()
+	if apt.CreatedAt.IsZero() {
+		apt.CreatedAt = now
+	}
+	apt.UpdatedAt = now
+
+	aptCopy := *apt
+	m.appointments[apt.ID] = &aptCopy
+	return nil
+}
+
+func (m *MemoryStore) GetAppointmentsByPhone(phone string) ([]models.Appointment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appointments []models.Appointment
+	for _, apt := range m.appointments {
+		if apt.UserPhone == phone {
+			appointments = append(appointments, *apt)
+		}
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].DateTime.After(appointments[j].DateTime)
+	})
+	return appointments, nil
+}
+
+func (m *MemoryStore) GetAppointmentByID(id string) (*models.Appointment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	apt, ok := m.appointments[id]
+	if !ok {
+		return nil, nil
+	}
+	aptCopy := *apt
+	return &aptCopy, nil
+}
+
+func (m *MemoryStore) UpdateAppointment(apt *models.Appointment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.overlapsLocked(apt) {
+		return ErrSlotUnavailable
+	}
+	apt.UpdatedAt = time.Now()
+	aptCopy := *apt
+	m.appointments[apt.ID] = &aptCopy
+	return nil
+}
+
+// overlapsLocked mirrors the appointments_no_overlap constraint: an active
+// appointment may not overlap any other active appointment with the same
+// provider. Callers hold m.mu.
+func (m *MemoryStore) overlapsLocked(apt *models.Appointment) bool {
+	if !apt.IsActive() {
+		return false
+	}
+	start := apt.DateTime
+	end := apt.BlockEnd()
+
+	for id, other := range m.appointments {
+		if id == apt.ID || !other.IsActive() || other.ProviderID != apt.ProviderID {
+			continue
+		}
+		if other.DateTime.Before(end) && other.BlockEnd().After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) CheckSlotAvailability(providerID string, dateTime time.Time, duration int) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	requestedStart := dateTime
+	requestedEnd := dateTime.Add(time.Duration(duration) * time.Minute)
+
+	for _, apt := range m.appointments {
+		if !apt.IsActive() || apt.ProviderID != providerID {
+			continue
+		}
+		if apt.DateTime.Before(requestedEnd) && apt.BlockEnd().After(requestedStart) {
+			return false, nil
+		}
+	}
+
+	for _, block := range m.busy {
+		if block.ProviderID == providerID && block.DateTime.Before(requestedEnd) && block.EndTime.After(requestedStart) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (m *MemoryStore) GetUpcomingAppointments(phone string) ([]models.Appointment, error) {
+	appointments, err := m.GetAppointmentsByPhone(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var upcoming []models.Appointment
+	for _, apt := range appointments {
+		if apt.DateTime.After(now) && apt.IsActive() {
+			upcoming = append(upcoming, apt)
+		}
+	}
+
+	return upcoming, nil
+}
+
+func (m *MemoryStore) GetUpcomingAppointmentsInWindow(from time.Time, to time.Time) ([]models.Appointment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appointments []models.Appointment
+	for _, apt := range m.appointments {
+		if !apt.IsActive() {
+			continue
+		}
+		if apt.DateTime.Before(from) || apt.DateTime.After(to) {
+			continue
+		}
+		appointments = append(appointments, *apt)
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].DateTime.Before(appointments[j].DateTime)
+	})
+	return appointments, nil
+}
+
+func (m *MemoryStore) GetBookedAppointments(from, to time.Time) ([]models.Appointment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appointments []models.Appointment
+	for _, apt := range m.appointments {
+		if apt.IsActive() && apt.DateTime.Before(to) && apt.BlockEnd().After(from) {
+			appointments = append(appointments, *apt)
+		}
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].DateTime.Before(appointments[j].DateTime)
+	})
+	return appointments, nil
+}
+
+// Recurring series operations
+func (m *MemoryStore) CreateAppointmentSeries(series *models.AppointmentSeries) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if series.ID == "" {
+		series.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if series.CreatedAt.IsZero() {
+		series.CreatedAt = now
+	}
+	series.UpdatedAt = now
+
+	seriesCopy := *series
+	m.series[series.ID] = &seriesCopy
+	return nil
+}
+
+func (m *MemoryStore) GetAppointmentSeries(id string) (*models.AppointmentSeries, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	series, ok := m.series[id]
+	if !ok {
+		return nil, nil
+	}
+	seriesCopy := *series
+	return &seriesCopy, nil
+}
+
+func (m *MemoryStore) UpdateAppointmentSeries(series *models.AppointmentSeries) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series.UpdatedAt = time.Now()
+	seriesCopy := *series
+	m.series[series.ID] = &seriesCopy
+	return nil
+}
+
+func (m *MemoryStore) DeleteAppointmentSeries(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.series, id)
+	return nil
+}
+
+func (m *MemoryStore) GetAppointmentsBySeries(seriesID string) ([]models.Appointment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appointments []models.Appointment
+	for _, apt := range m.appointments {
+		if apt.SeriesID == seriesID {
+			appointments = append(appointments, *apt)
+		}
+	}
+
+	sort.Slice(appointments, func(i, j int) bool {
+		return appointments[i].DateTime.Before(appointments[j].DateTime)
+	})
+	return appointments, nil
+}
+
+// Slot hold operations
+func (m *MemoryStore) CreateSlotHold(hold *models.SlotHold) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hold.ID == "" {
+		hold.ID = uuid.New().String()
+	}
+	now := time.Now()
+	for id, existing := range m.holds {
+		if existing.ProviderID != hold.ProviderID || !existing.DateTime.Before(hold.EndTime) || !existing.EndTime.After(hold.DateTime) {
+			continue
+		}
+		if existing.ExpiresAt.After(now) {
+			return ErrSlotUnavailable
+		}
+		delete(m.holds, id)
+	}
+
+	if hold.CreatedAt.IsZero() {
+		hold.CreatedAt = now
+	}
+	holdCopy := *hold
+	m.holds[hold.ID] = &holdCopy
+	return nil
+}
+
+func (m *MemoryStore) GetSlotHolds(from, to time.Time) ([]models.SlotHold, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var holds []models.SlotHold
+	for _, hold := range m.holds {
+		if hold.ExpiresAt.After(now) && hold.DateTime.Before(to) && hold.EndTime.After(from) {
+			holds = append(holds, *hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].DateTime.Before(holds[j].DateTime)
+	})
+	return holds, nil
+}
+
+func (m *MemoryStore) DeleteSlotHold(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.holds, id)
+	return nil
+}
+
+func (m *MemoryStore) DeleteSlotHoldsBySession(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, hold := range m.holds {
+		if hold.SessionID == sessionID {
+			delete(m.holds, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteExpiredSlotHolds(now time.Time) ([]models.SlotHold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired []models.SlotHold
+	for id, hold := range m.holds {
+		if !hold.ExpiresAt.After(now) {
+			expired = append(expired, *hold)
+			delete(m.holds, id)
+		}
+	}
+	return expired, nil
+}
+
+// Busy block operations
+func (m *MemoryStore) GetBusyBlocks(from, to time.Time) ([]models.BusyBlock, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var blocks []models.BusyBlock
+	for _, block := range m.busy {
+		if block.DateTime.Before(to) && block.EndTime.After(from) {
+			blocks = append(blocks, block)
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].DateTime.Before(blocks[j].DateTime)
+	})
+	return blocks, nil
+}
+
+func (m *MemoryStore) ReplaceBusyBlocks(providerID string, from, to time.Time, blocks []models.BusyBlock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.busy[:0]
+	for _, block := range m.busy {
+		if block.ProviderID == providerID && block.DateTime.Before(to) && block.EndTime.After(from) {
+			continue
+		}
+		kept = append(kept, block)
+	}
+	m.busy = kept
+
+	now := time.Now()
+	for _, block := range blocks {
+		block.ID = uuid.New().String()
+		block.ProviderID = providerID
+		block.CreatedAt = now
+		m.busy = append(m.busy, block)
+	}
+	return nil
+}
+
+// Waitlist operations
+func (m *MemoryStore) CreateWaitlistEntry(entry *models.WaitlistEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+
+	entryCopy := *entry
+	m.waitlist[entry.ID] = &entryCopy
+	return nil
+}
+
+func (m *MemoryStore) GetWaitlistEntries(status string) ([]models.WaitlistEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []models.WaitlistEntry
+	for _, entry := range m.waitlist {
+		if entry.Status == status {
+			entries = append(entries, *entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (m *MemoryStore) GetWaitlistEntryByID(id string) (*models.WaitlistEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.waitlist[id]
+	if !ok {
+		return nil, nil
+	}
+	entryCopy := *entry
+	return &entryCopy, nil
+}
+
+func (m *MemoryStore) UpdateWaitlistEntry(entry *models.WaitlistEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry.UpdatedAt = time.Now()
+	entryCopy := *entry
+	m.waitlist[entry.ID] = &entryCopy
+	return nil
+}
+
+// Provider operations
+func (m *MemoryStore) GetProviders() ([]models.Provider, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var providers []models.Provider
+	for _, provider := range m.providers {
+		if provider.Active {
+			providers = append(providers, *provider)
+		}
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers, nil
+}
+
+func (m *MemoryStore) GetProviderByID(id string) (*models.Provider, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	provider, ok := m.providers[id]
+	if !ok {
+		return nil, nil
+	}
+	providerCopy := *provider
+	return &providerCopy, nil
+}
+
+func (m *MemoryStore) CreateProvider(provider *models.Provider) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if provider.ID == "" {
+		provider.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if provider.CreatedAt.IsZero() {
+		provider.CreatedAt = now
+	}
+	provider.UpdatedAt = now
+
+	providerCopy := *provider
+	m.providers[provider.ID] = &providerCopy
+	return nil
+}
+
+// Service catalog operations
+func (m *MemoryStore) GetServices() ([]models.Service, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var services []models.Service
+	for _, service := range m.services {
+		if service.Active {
+			services = append(services, *service)
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+func (m *MemoryStore) GetServiceByID(id string) (*models.Service, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	service, ok := m.services[id]
+	if !ok {
+		return nil, nil
+	}
+	serviceCopy := *service
+	return &serviceCopy, nil
+}
+
+func (m *MemoryStore) CreateService(service *models.Service) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if service.ID == "" {
+		service.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if service.CreatedAt.IsZero() {
+		service.CreatedAt = now
+	}
+	service.UpdatedAt = now
+
+	serviceCopy := *service
+	m.services[service.ID] = &serviceCopy
+	return nil
+}
+
+// Calendar feed token operations
+func (m *MemoryStore) SaveCalendarToken(phone, token string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for existing, owner := range m.tokens {
+		if owner == phone {
+			return existing, nil
+		}
+	}
+	m.tokens[token] = phone
+	return token, nil
+}
+
+func (m *MemoryStore) GetPhoneByCalendarToken(token string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.tokens[token], nil
+}
+
+// Call Summary operations
+func (m *MemoryStore) SaveCallSummary(summary *models.CallSummary) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if summary.ID == "" {
+		summary.ID = uuid.New().String()
+	}
+	if summary.CreatedAt.IsZero() {
+		summary.CreatedAt = time.Now()
+	}
+	m.summaries = append(m.summaries, *summary)
+	return nil
+}
+
+func (m *MemoryStore) GetCallSummariesByPhone(phone string) ([]models.CallSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var summaries []models.CallSummary
+	for _, summary := range m.summaries {
+		if summary.UserPhone == phone {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+	})
+	return summaries, nil
+}
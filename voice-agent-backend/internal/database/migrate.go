@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationFilePattern matches files like 001_initial_schema.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+// Migration is a numbered SQL file in the migrations directory
+type Migration struct {
+	Version int
+	Name    string
+	Path    string
+}
+
+// LoadMigrations reads the migrations in dir, sorted by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    match[2],
+			Path:    filepath.Join(dir, entry.Name()),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// AppliedMigrations returns the versions recorded in schema_migrations
+func (p *PostgresStore) AppliedMigrations(ctx context.Context) (map[int]bool, error) {
+	if err := p.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.pool.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// Migrate applies all pending migrations from dir in version order. Each
+// migration runs in its own transaction together with its schema_migrations row.
+func (p *PostgresStore) Migrate(ctx context.Context, dir string) ([]Migration, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := p.AppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		sql, err := os.ReadFile(m.Path)
+		if err != nil {
+			return ran, fmt.Errorf("failed to read migration %d: %w", m.Version, err)
+		}
+
+		err = p.withTx(ctx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, string(sql)); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			_, err := tx.Exec(ctx,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+				m.Version, m.Name,
+			)
+			return err
+		})
+		if err != nil {
+			return ran, err
+		}
+		ran = append(ran, m)
+	}
+
+	return ran, nil
+}
+
+// Baseline records the migrations in dir up to and including version as
+// applied without running them, for a database whose schema was created
+// by hand (e.g. in the Supabase SQL editor). It returns the migrations it
+// recorded.
+func (p *PostgresStore) Baseline(ctx context.Context, dir string, version int) ([]Migration, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := p.AppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []Migration
+	err = p.withTx(ctx, func(tx pgx.Tx) error {
+		for _, m := range migrations {
+			if m.Version > version || applied[m.Version] {
+				continue
+			}
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+				m.Version, m.Name,
+			); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+			}
+			marked = append(marked, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marked, nil
+}
+
+func (p *PostgresStore) ensureMigrationsTable(ctx context.Context) error {
+	_, err := p.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
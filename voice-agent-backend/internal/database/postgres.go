@@ -0,0 +1,942 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+const queryTimeout = 10 * time.Second
+
+// PostgresStore is a Store backed by a direct PostgreSQL connection pool
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore connects to the database at databaseURL
+func NewPostgresStore(ctx context.Context, databaseURL string) (*PostgresStore, error) {
+	if databaseURL == "" {
+		return nil, fmt.Errorf("database URL is required for the postgres driver")
+	}
+
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	return &PostgresStore{pool: pool}, nil
+}
+
+// Pool returns the underlying connection pool
+func (p *PostgresStore) Pool() *pgxpool.Pool {
+	return p.pool
+}
+
+// Close closes all pooled connections
+func (p *PostgresStore) Close() {
+	p.pool.Close()
+}
+
+// withTx runs fn inside a transaction, committing on success
+func (p *PostgresStore) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isOverlapViolation reports whether err was raised by the appointments_no_overlap or
+// slot_holds_no_overlap constraint
+func isOverlapViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+const userColumns = `id::text, phone_number, COALESCE(name, ''), COALESCE(email, ''), COALESCE(timezone, ''), created_at, updated_at`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+	if err := row.Scan(&user.ID, &user.PhoneNumber, &user.Name, &user.Email, &user.Timezone, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// User operations
+func (p *PostgresStore) GetUserByPhone(phone string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE phone_number = $1`, phone)
+	user, err := scanUser(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (p *PostgresStore) CreateUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO users (id, phone_number, name, email, timezone)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING `+userColumns,
+		user.ID, user.PhoneNumber, user.Name, user.Email, user.Timezone,
+	)
+	created, err := scanUser(row)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	*user = *created
+	return nil
+}
+
+func (p *PostgresStore) UpdateUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	_, err := p.pool.Exec(ctx, `
+		UPDATE users SET phone_number = $2, name = NULLIF($3, ''), email = NULLIF($4, ''), timezone = NULLIF($5, '')
+		WHERE id = $1`,
+		user.ID, user.PhoneNumber, user.Name, user.Email, user.Timezone,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+const appointmentColumns = `id::text, user_phone, COALESCE(user_name, ''), COALESCE(provider_id::text, ''),
+	COALESCE(service_id::text, ''), COALESCE(series_id::text, ''), date_time, duration, buffer_minutes, COALESCE(price_cents, 0),
+	COALESCE(purpose, ''), status, COALESCE(notes, ''), reschedule_count, COALESCE(fee_cents, 0),
+	COALESCE(fee_payment_id, ''), COALESCE(payment_id, ''), COALESCE(payment_status, ''), paid_at,
+	confirmed_at, rescheduled_at, checked_in_at, completed_at, no_show_at, cancelled_at,
+	created_at, updated_at`
+
+func scanAppointment(row pgx.Row) (*models.Appointment, error) {
+	var apt models.Appointment
+	if err := row.Scan(
+		&apt.ID, &apt.UserPhone, &apt.UserName, &apt.ProviderID,
+		&apt.ServiceID, &apt.SeriesID, &apt.DateTime, &apt.Duration, &apt.BufferMinutes, &apt.PriceCents,
+		&apt.Purpose, &apt.Status, &apt.Notes, &apt.RescheduleCount, &apt.FeeCents,
+		&apt.FeePaymentID, &apt.PaymentID, &apt.PaymentStatus, &apt.PaidAt,
+		&apt.ConfirmedAt, &apt.RescheduledAt, &apt.CheckedInAt, &apt.CompletedAt,
+		&apt.NoShowAt, &apt.CancelledAt, &apt.CreatedAt, &apt.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &apt, nil
+}
+
+func (p *PostgresStore) queryAppointments(ctx context.Context, query string, args ...interface{}) ([]models.Appointment, error) {
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []models.Appointment
+	for rows.Next() {
+		apt, err := scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, *apt)
+	}
+	return appointments, rows.Err()
+}
+
+// Appointment operations
+func (p *PostgresStore) CreateAppointment(apt *models.Appointment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO appointments (id, user_phone, user_name, provider_id, service_id, series_id, date_time, duration,
+			buffer_minutes, price_cents, purpose, status, notes)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, NULLIF($3, ''), NULLIF($4, '')::uuid,
+			NULLIF($5, '')::uuid, NULLIF($6, '')::uuid, $7, $8, $9, NULLIF($10, 0), NULLIF($11, ''), $12, NULLIF($13, ''))
+		RETURNING `+appointmentColumns,
+		apt.ID, apt.UserPhone, apt.UserName, apt.ProviderID, apt.ServiceID, apt.SeriesID, apt.DateTime, apt.Duration,
+		apt.BufferMinutes, apt.PriceCents, apt.Purpose, apt.Status, apt.Notes,
+	)
+	created, err := scanAppointment(row)
+	if isOverlapViolation(err) {
+		return ErrSlotUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create appointment: %w", err)
+	}
+	*apt = *created
+	return nil
+}
+
+func (p *PostgresStore) GetAppointmentsByPhone(phone string) ([]models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointments, err := p.queryAppointments(ctx,
+		`SELECT `+appointmentColumns+` FROM appointments WHERE user_phone = $1 ORDER BY date_time DESC`,
+		phone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+func (p *PostgresStore) GetAppointmentByID(id string) (*models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// Compare as text so malformed IDs from the LLM are "not found" rather than a cast error
+	row := p.pool.QueryRow(ctx, `SELECT `+appointmentColumns+` FROM appointments WHERE id::text = $1`, id)
+	apt, err := scanAppointment(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	return apt, nil
+}
+
+func (p *PostgresStore) UpdateAppointment(apt *models.Appointment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	apt.UpdatedAt = time.Now()
+	_, err := p.pool.Exec(ctx, `
+		UPDATE appointments
+		SET user_name = NULLIF($2, ''), provider_id = NULLIF($3, '')::uuid, service_id = NULLIF($4, '')::uuid,
+			series_id = NULLIF($5, '')::uuid, date_time = $6, duration = $7, buffer_minutes = $8,
+			price_cents = NULLIF($9, 0), purpose = NULLIF($10, ''), status = $11, notes = NULLIF($12, ''),
+			confirmed_at = $13, rescheduled_at = $14, checked_in_at = $15, completed_at = $16, no_show_at = $17,
+			cancelled_at = $18, reschedule_count = $19, fee_cents = NULLIF($20, 0), fee_payment_id = NULLIF($21, ''),
+			payment_id = NULLIF($22, ''), payment_status = NULLIF($23, ''), paid_at = $24
+		WHERE id = $1`,
+		apt.ID, apt.UserName, apt.ProviderID, apt.ServiceID, apt.SeriesID, apt.DateTime, apt.Duration,
+		apt.BufferMinutes, apt.PriceCents, apt.Purpose, apt.Status, apt.Notes,
+		apt.ConfirmedAt, apt.RescheduledAt, apt.CheckedInAt, apt.CompletedAt, apt.NoShowAt, apt.CancelledAt,
+		apt.RescheduleCount, apt.FeeCents, apt.FeePaymentID, apt.PaymentID, apt.PaymentStatus, apt.PaidAt,
+	)
+	if isOverlapViolation(err) {
+		return ErrSlotUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update appointment: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) CheckSlotAvailability(providerID string, dateTime time.Time, duration int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	requestedEnd := dateTime.Add(time.Duration(duration) * time.Minute)
+
+	var overlapping bool
+	err := p.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE status = ANY($1)
+			  AND provider_id::text IS NOT DISTINCT FROM NULLIF($4, '')
+			  AND date_time < $3
+			  AND end_time > $2
+		) OR EXISTS (
+			SELECT 1 FROM busy_blocks
+			WHERE provider_id::text IS NOT DISTINCT FROM NULLIF($4, '')
+			  AND date_time < $3
+			  AND end_time > $2
+		)`,
+		models.ActiveStatuses, dateTime, requestedEnd, providerID,
+	).Scan(&overlapping)
+	if err != nil {
+		return false, fmt.Errorf("failed to check availability: %w", err)
+	}
+
+	return !overlapping, nil
+}
+
+func (p *PostgresStore) GetUpcomingAppointments(phone string) ([]models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointments, err := p.queryAppointments(ctx, `
+		SELECT `+appointmentColumns+` FROM appointments
+		WHERE user_phone = $1 AND status = ANY($2) AND date_time > NOW()
+		ORDER BY date_time DESC`,
+		phone, models.ActiveStatuses,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+func (p *PostgresStore) GetUpcomingAppointmentsInWindow(from time.Time, to time.Time) ([]models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointments, err := p.queryAppointments(ctx, `
+		SELECT `+appointmentColumns+` FROM appointments
+		WHERE status = ANY($1) AND date_time >= $2 AND date_time <= $3
+		ORDER BY date_time ASC`,
+		models.ActiveStatuses, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointments in window: %w", err)
+	}
+	return appointments, nil
+}
+
+func (p *PostgresStore) GetBookedAppointments(from, to time.Time) ([]models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointments, err := p.queryAppointments(ctx, `
+		SELECT `+appointmentColumns+` FROM appointments
+		WHERE status = ANY($1) AND date_time < $3 AND end_time > $2
+		ORDER BY date_time ASC`,
+		models.ActiveStatuses, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booked appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+const seriesColumns = `id::text, user_phone, rrule, dtstart, timezone, created_at, updated_at`
+
+func scanSeries(row pgx.Row) (*models.AppointmentSeries, error) {
+	var series models.AppointmentSeries
+	if err := row.Scan(
+		&series.ID, &series.UserPhone, &series.RRule, &series.DTStart, &series.Timezone,
+		&series.CreatedAt, &series.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// Recurring series operations
+func (p *PostgresStore) CreateAppointmentSeries(series *models.AppointmentSeries) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO appointment_series (id, user_phone, rrule, dtstart, timezone)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, $3, $4, $5)
+		RETURNING `+seriesColumns,
+		series.ID, series.UserPhone, series.RRule, series.DTStart, series.Timezone,
+	)
+	created, err := scanSeries(row)
+	if err != nil {
+		return fmt.Errorf("failed to create appointment series: %w", err)
+	}
+	*series = *created
+	return nil
+}
+
+func (p *PostgresStore) GetAppointmentSeries(id string) (*models.AppointmentSeries, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `SELECT `+seriesColumns+` FROM appointment_series WHERE id::text = $1`, id)
+	series, err := scanSeries(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment series: %w", err)
+	}
+	return series, nil
+}
+
+func (p *PostgresStore) UpdateAppointmentSeries(series *models.AppointmentSeries) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	series.UpdatedAt = time.Now()
+	_, err := p.pool.Exec(ctx, `
+		UPDATE appointment_series SET rrule = $2, dtstart = $3, timezone = $4
+		WHERE id = $1`,
+		series.ID, series.RRule, series.DTStart, series.Timezone,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update appointment series: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) DeleteAppointmentSeries(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	if _, err := p.pool.Exec(ctx, `DELETE FROM appointment_series WHERE id::text = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete appointment series: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetAppointmentsBySeries(seriesID string) ([]models.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointments, err := p.queryAppointments(ctx,
+		`SELECT `+appointmentColumns+` FROM appointments WHERE series_id::text = $1 ORDER BY date_time ASC`,
+		seriesID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+const holdColumns = `id::text, COALESCE(provider_id::text, ''), date_time, end_time, COALESCE(session_id, ''),
+	COALESCE(holder_phone, ''), COALESCE(waitlist_entry_id::text, ''), expires_at, created_at`
+
+func scanHold(row pgx.Row) (*models.SlotHold, error) {
+	var hold models.SlotHold
+	if err := row.Scan(
+		&hold.ID, &hold.ProviderID, &hold.DateTime, &hold.EndTime, &hold.SessionID, &hold.HolderPhone,
+		&hold.WaitlistEntryID, &hold.ExpiresAt, &hold.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (p *PostgresStore) queryHolds(ctx context.Context, query string, args ...interface{}) ([]models.SlotHold, error) {
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []models.SlotHold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+	return holds, rows.Err()
+}
+
+// Slot hold operations
+func (p *PostgresStore) CreateSlotHold(hold *models.SlotHold) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return p.withTx(ctx, func(tx pgx.Tx) error {
+		// Expired holds still count against slot_holds_no_overlap until swept
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM slot_holds
+			WHERE provider_id::text IS NOT DISTINCT FROM NULLIF($1, '')
+			  AND date_time < $3 AND end_time > $2 AND expires_at <= NOW()`,
+			hold.ProviderID, hold.DateTime, hold.EndTime,
+		); err != nil {
+			return fmt.Errorf("failed to clear expired slot holds: %w", err)
+		}
+
+		row := tx.QueryRow(ctx, `
+			INSERT INTO slot_holds (id, provider_id, date_time, end_time, session_id, holder_phone, waitlist_entry_id,
+				expires_at)
+			VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), NULLIF($2, '')::uuid, $3, $4, NULLIF($5, ''),
+				NULLIF($6, ''), NULLIF($7, '')::uuid, $8)
+			RETURNING `+holdColumns,
+			hold.ID, hold.ProviderID, hold.DateTime, hold.EndTime, hold.SessionID, hold.HolderPhone,
+			hold.WaitlistEntryID, hold.ExpiresAt,
+		)
+		created, err := scanHold(row)
+		if isOverlapViolation(err) {
+			return ErrSlotUnavailable
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create slot hold: %w", err)
+		}
+		*hold = *created
+		return nil
+	})
+}
+
+func (p *PostgresStore) GetSlotHolds(from, to time.Time) ([]models.SlotHold, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	holds, err := p.queryHolds(ctx, `
+		SELECT `+holdColumns+` FROM slot_holds
+		WHERE date_time < $2 AND end_time > $1 AND expires_at > NOW()
+		ORDER BY date_time ASC`,
+		from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	return holds, nil
+}
+
+func (p *PostgresStore) DeleteSlotHold(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	if _, err := p.pool.Exec(ctx, `DELETE FROM slot_holds WHERE id::text = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete slot hold: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) DeleteSlotHoldsBySession(sessionID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	if _, err := p.pool.Exec(ctx, `DELETE FROM slot_holds WHERE session_id = $1`, sessionID); err != nil {
+		return fmt.Errorf("failed to release session holds: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) DeleteExpiredSlotHolds(now time.Time) ([]models.SlotHold, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	holds, err := p.queryHolds(ctx, `DELETE FROM slot_holds WHERE expires_at <= $1 RETURNING `+holdColumns, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired slot holds: %w", err)
+	}
+	return holds, nil
+}
+
+const busyColumns = `id::text, COALESCE(provider_id::text, ''), date_time, end_time, COALESCE(external_uid, ''), created_at`
+
+// Busy block operations
+func (p *PostgresStore) GetBusyBlocks(from, to time.Time) ([]models.BusyBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := p.pool.Query(ctx, `
+		SELECT `+busyColumns+` FROM busy_blocks
+		WHERE date_time < $2 AND end_time > $1
+		ORDER BY date_time ASC`,
+		from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get busy blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []models.BusyBlock
+	for rows.Next() {
+		var block models.BusyBlock
+		if err := rows.Scan(&block.ID, &block.ProviderID, &block.DateTime, &block.EndTime, &block.ExternalUID, &block.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to get busy blocks: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, rows.Err()
+}
+
+func (p *PostgresStore) ReplaceBusyBlocks(providerID string, from, to time.Time, blocks []models.BusyBlock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return p.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM busy_blocks
+			WHERE provider_id::text IS NOT DISTINCT FROM NULLIF($1, '')
+			  AND date_time < $3 AND end_time > $2`,
+			providerID, from, to,
+		); err != nil {
+			return fmt.Errorf("failed to clear busy blocks: %w", err)
+		}
+
+		for _, block := range blocks {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO busy_blocks (provider_id, date_time, end_time, external_uid)
+				VALUES (NULLIF($1, '')::uuid, $2, $3, NULLIF($4, ''))`,
+				providerID, block.DateTime, block.EndTime, block.ExternalUID,
+			); err != nil {
+				return fmt.Errorf("failed to save busy block: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+const waitlistColumns = `id::text, user_phone, COALESCE(user_name, ''), COALESCE(provider_id::text, ''),
+	COALESCE(service_id::text, ''), duration, buffer_minutes, from_date::text, to_date::text,
+	COALESCE(preferred_times, ''), status, offer_date_time, COALESCE(offer_provider_id::text, ''),
+	offer_expires_at, COALESCE(appointment_id::text, ''), created_at, updated_at`
+
+func scanWaitlistEntry(row pgx.Row) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if err := row.Scan(
+		&entry.ID, &entry.UserPhone, &entry.UserName, &entry.ProviderID, &entry.ServiceID, &entry.Duration,
+		&entry.BufferMinutes, &entry.FromDate, &entry.ToDate, &entry.PreferredTimes, &entry.Status,
+		&entry.OfferDateTime, &entry.OfferProviderID, &entry.OfferExpiresAt, &entry.AppointmentID,
+		&entry.CreatedAt, &entry.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Waitlist operations
+func (p *PostgresStore) CreateWaitlistEntry(entry *models.WaitlistEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO waitlist (id, user_phone, user_name, provider_id, service_id, duration, buffer_minutes,
+			from_date, to_date, preferred_times, status)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, NULLIF($3, ''), NULLIF($4, '')::uuid,
+			NULLIF($5, '')::uuid, $6, $7, $8::date, $9::date, NULLIF($10, ''), $11)
+		RETURNING `+waitlistColumns,
+		entry.ID, entry.UserPhone, entry.UserName, entry.ProviderID, entry.ServiceID, entry.Duration,
+		entry.BufferMinutes, entry.FromDate, entry.ToDate, entry.PreferredTimes, entry.Status,
+	)
+	created, err := scanWaitlistEntry(row)
+	if err != nil {
+		return fmt.Errorf("failed to create waitlist entry: %w", err)
+	}
+	*entry = *created
+	return nil
+}
+
+func (p *PostgresStore) GetWaitlistEntries(status string) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := p.pool.Query(ctx, `SELECT `+waitlistColumns+` FROM waitlist WHERE status = $1 ORDER BY created_at ASC`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.WaitlistEntry
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waitlist: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get waitlist: %w", err)
+	}
+	return entries, nil
+}
+
+func (p *PostgresStore) GetWaitlistEntryByID(id string) (*models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `SELECT `+waitlistColumns+` FROM waitlist WHERE id::text = $1`, id)
+	entry, err := scanWaitlistEntry(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (p *PostgresStore) UpdateWaitlistEntry(entry *models.WaitlistEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entry.UpdatedAt = time.Now()
+	_, err := p.pool.Exec(ctx, `
+		UPDATE waitlist
+		SET status = $2, offer_date_time = $3, offer_provider_id = NULLIF($4, '')::uuid,
+			offer_expires_at = $5, appointment_id = NULLIF($6, '')::uuid
+		WHERE id = $1`,
+		entry.ID, entry.Status, entry.OfferDateTime, entry.OfferProviderID, entry.OfferExpiresAt, entry.AppointmentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+	return nil
+}
+
+const providerColumns = `id::text, name, kind, COALESCE(hours, ''), active, created_at, updated_at`
+
+func scanProvider(row pgx.Row) (*models.Provider, error) {
+	var provider models.Provider
+	if err := row.Scan(
+		&provider.ID, &provider.Name, &provider.Kind, &provider.Hours, &provider.Active,
+		&provider.CreatedAt, &provider.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+// Provider operations
+func (p *PostgresStore) GetProviders() ([]models.Provider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := p.pool.Query(ctx, `SELECT `+providerColumns+` FROM providers WHERE active ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+	defer rows.Close()
+
+	var providers []models.Provider
+	for rows.Next() {
+		provider, err := scanProvider(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get providers: %w", err)
+		}
+		providers = append(providers, *provider)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+	return providers, nil
+}
+
+func (p *PostgresStore) GetProviderByID(id string) (*models.Provider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `SELECT `+providerColumns+` FROM providers WHERE id::text = $1`, id)
+	provider, err := scanProvider(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	return provider, nil
+}
+
+func (p *PostgresStore) CreateProvider(provider *models.Provider) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO providers (id, name, kind, hours, active)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, $3, NULLIF($4, ''), $5)
+		RETURNING `+providerColumns,
+		provider.ID, provider.Name, provider.Kind, provider.Hours, provider.Active,
+	)
+	created, err := scanProvider(row)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+	*provider = *created
+	return nil
+}
+
+const serviceColumns = `id::text, name, COALESCE(description, ''), duration, buffer_minutes, price_cents,
+	COALESCE(provider_ids::text[], '{}'), active, created_at, updated_at`
+
+func scanService(row pgx.Row) (*models.Service, error) {
+	var service models.Service
+	if err := row.Scan(
+		&service.ID, &service.Name, &service.Description, &service.Duration, &service.BufferMinutes,
+		&service.PriceCents, &service.ProviderIDs, &service.Active, &service.CreatedAt, &service.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// Service catalog operations
+func (p *PostgresStore) GetServices() ([]models.Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := p.pool.Query(ctx, `SELECT `+serviceColumns+` FROM services WHERE active ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	defer rows.Close()
+
+	var services []models.Service
+	for rows.Next() {
+		service, err := scanService(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get services: %w", err)
+		}
+		services = append(services, *service)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	return services, nil
+}
+
+func (p *PostgresStore) GetServiceByID(id string) (*models.Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	row := p.pool.QueryRow(ctx, `SELECT `+serviceColumns+` FROM services WHERE id::text = $1`, id)
+	service, err := scanService(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	return service, nil
+}
+
+func (p *PostgresStore) CreateService(service *models.Service) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	providerIDs := service.ProviderIDs
+	if providerIDs == nil {
+		providerIDs = []string{}
+	}
+
+	row := p.pool.QueryRow(ctx, `
+		INSERT INTO services (id, name, description, duration, buffer_minutes, price_cents, provider_ids, active)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, NULLIF($3, ''), $4, $5, $6, $7::text[]::uuid[], $8)
+		RETURNING `+serviceColumns,
+		service.ID, service.Name, service.Description, service.Duration, service.BufferMinutes,
+		service.PriceCents, providerIDs, service.Active,
+	)
+	created, err := scanService(row)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	*service = *created
+	return nil
+}
+
+// Calendar feed token operations
+func (p *PostgresStore) SaveCalendarToken(phone, token string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// The no-op update makes RETURNING give the existing token on conflict
+	var saved string
+	err := p.pool.QueryRow(ctx, `
+		INSERT INTO calendar_tokens (token, phone_number)
+		VALUES ($1, $2)
+		ON CONFLICT (phone_number) DO UPDATE SET phone_number = EXCLUDED.phone_number
+		RETURNING token`,
+		token, phone,
+	).Scan(&saved)
+	if err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+	return saved, nil
+}
+
+func (p *PostgresStore) GetPhoneByCalendarToken(token string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var phone string
+	err := p.pool.QueryRow(ctx, `SELECT phone_number FROM calendar_tokens WHERE token = $1`, token).Scan(&phone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar token: %w", err)
+	}
+	return phone, nil
+}
+
+// Call Summary operations
+func (p *PostgresStore) SaveCallSummary(summary *models.CallSummary) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	appointmentsJSON, err := json.Marshal(summary.AppointmentsBooked)
+	if err != nil {
+		return fmt.Errorf("failed to marshal appointments: %w", err)
+	}
+	preferencesJSON, err := json.Marshal(summary.UserPreferences)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+	topicsJSON, err := json.Marshal(summary.KeyTopics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal topics: %w", err)
+	}
+
+	err = p.pool.QueryRow(ctx, `
+		INSERT INTO call_summaries (id, session_id, user_phone, summary, appointments_booked, user_preferences, key_topics, duration)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, NULLIF($3, ''), $4, $5::jsonb, $6::jsonb, $7::jsonb, $8)
+		RETURNING id::text, created_at`,
+		summary.ID, summary.SessionID, summary.UserPhone, summary.Summary,
+		string(appointmentsJSON), string(preferencesJSON), string(topicsJSON), summary.Duration,
+	).Scan(&summary.ID, &summary.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save call summary: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetCallSummariesByPhone(phone string) ([]models.CallSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := p.pool.Query(ctx, `
+		SELECT id::text, session_id, COALESCE(user_phone, ''), COALESCE(summary, ''),
+			appointments_booked, user_preferences, key_topics, COALESCE(duration, 0), created_at
+		FROM call_summaries WHERE user_phone = $1 ORDER BY created_at DESC`,
+		phone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get call summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []models.CallSummary
+	for rows.Next() {
+		var summary models.CallSummary
+		var appointmentsJSON, preferencesJSON, topicsJSON []byte
+		if err := rows.Scan(
+			&summary.ID, &summary.SessionID, &summary.UserPhone, &summary.Summary,
+			&appointmentsJSON, &preferencesJSON, &topicsJSON, &summary.Duration, &summary.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan call summary: %w", err)
+		}
+		_ = json.Unmarshal(appointmentsJSON, &summary.AppointmentsBooked)
+		_ = json.Unmarshal(preferencesJSON, &summary.UserPreferences)
+		_ = json.Unmarshal(topicsJSON, &summary.KeyTopics)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read call summaries: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// Set TEST_DATABASE_URL to a disposable database to run these tests.
+func newTestPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	store, err := NewPostgresStore(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	if _, err := store.Migrate(ctx, "../../migrations"); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+func TestPostgresCreateAppointmentConcurrentOverlap(t *testing.T) {
+	store := newTestPostgresStore(t)
+
+	// Far enough out that reruns don't collide with earlier runs
+	slot := time.Now().Add(time.Duration(time.Now().UnixNano()%1_000_000) * time.Hour).Truncate(time.Hour)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.CreateAppointment(&models.Appointment{
+				UserPhone: fmt.Sprintf("+1555000%04d", i),
+				DateTime:  slot.Add(time.Duration(i) * time.Minute),
+				Duration:  30,
+				Status:    models.StatusBooked,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	booked := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, ErrSlotUnavailable):
+		default:
+			t.Errorf("caller %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if booked != 1 {
+		t.Fatalf("expected exactly 1 successful booking, got %d", booked)
+	}
+}
+
+func TestPostgresCreateSlotHoldConcurrentOverlap(t *testing.T) {
+	store := newTestPostgresStore(t)
+
+	slot := time.Now().Add(time.Duration(time.Now().UnixNano()%1_000_000) * time.Hour).Truncate(time.Hour)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.CreateSlotHold(&models.SlotHold{
+				DateTime:  slot.Add(time.Duration(i) * time.Minute),
+				EndTime:   slot.Add(time.Duration(i+30) * time.Minute),
+				SessionID: fmt.Sprintf("session-%d", i),
+				ExpiresAt: time.Now().Add(time.Minute),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	held := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			held++
+		case errors.Is(err, ErrSlotUnavailable):
+		default:
+			t.Errorf("caller %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if held != 1 {
+		t.Fatalf("expected exactly 1 successful hold, got %d", held)
+	}
+}
+
+func TestPostgresBaselineSkipsMigrationsAppliedByHand(t *testing.T) {
+	store := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	// Versions far above the real ones, so the test leaves them alone
+	dir := t.TempDir()
+	files := map[string]string{
+		"900001_by_hand.sql":   "THIS IS NOT SQL;",
+		"900002_by_hand.sql":   "THIS IS NOT SQL EITHER;",
+		"900003_afterward.sql": "SELECT 1;",
+	}
+	for name, sql := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(sql), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	t.Cleanup(func() {
+		_, _ = store.pool.Exec(ctx, `DELETE FROM schema_migrations WHERE version >= 900000`)
+	})
+
+	marked, err := store.Baseline(ctx, dir, 900002)
+	if err != nil || len(marked) != 2 {
+		t.Fatalf("expected two migrations marked, got %v %v", marked, err)
+	}
+	if again, err := store.Baseline(ctx, dir, 900002); err != nil || len(again) != 0 {
+		t.Fatalf("expected a second baseline to mark nothing, got %v %v", again, err)
+	}
+
+	ran, err := store.Migrate(ctx, dir)
+	if err != nil || len(ran) != 1 || ran[0].Version != 900003 {
+		t.Fatalf("expected only the later migration to run, got %v %v", ran, err)
+	}
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// ErrSlotUnavailable is returned by CreateAppointment and UpdateAppointment
+// when an active appointment would overlap another active appointment with the
+// same provider, and by CreateSlotHold when a hold would overlap another
+// unexpired hold with the same provider.
+var ErrSlotUnavailable = errors.New("time slot is already booked")
+
+// Store is the persistence layer used by the agent, tools, handlers and
+// background services. Lookups that find nothing return (nil, nil).
+type Store interface {
+	// User operations
+	GetUserByPhone(phone string) (*models.User, error)
+	CreateUser(user *models.User) error
+	UpdateUser(user *models.User) error
+
+	// Appointment operations
+	CreateAppointment(apt *models.Appointment) error
+	GetAppointmentsByPhone(phone string) ([]models.Appointment, error)
+	GetAppointmentByID(id string) (*models.Appointment, error)
+	UpdateAppointment(apt *models.Appointment) error
+	// CheckSlotAvailability checks one provider's calendar; an empty
+	// providerID is the calendar of appointments with no provider. Existing
+	// appointments block their duration plus buffer time, and busy blocks
+	// pulled from external calendars block their whole span.
+	CheckSlotAvailability(providerID string, dateTime time.Time, duration int) (bool, error)
+	GetUpcomingAppointments(phone string) ([]models.Appointment, error)
+	GetUpcomingAppointmentsInWindow(from time.Time, to time.Time) ([]models.Appointment, error)
+	// GetBookedAppointments returns active appointments whose calendar block
+	// (including buffer) overlaps [from, to), across all providers
+	GetBookedAppointments(from, to time.Time) ([]models.Appointment, error)
+
+	// Recurring series operations
+	CreateAppointmentSeries(series *models.AppointmentSeries) error
+	GetAppointmentSeries(id string) (*models.AppointmentSeries, error)
+	UpdateAppointmentSeries(series *models.AppointmentSeries) error
+	// DeleteAppointmentSeries removes a series no appointment points at
+	DeleteAppointmentSeries(id string) error
+	GetAppointmentsBySeries(seriesID string) ([]models.Appointment, error) // ordered by date_time
+
+	// Slot hold operations
+	CreateSlotHold(hold *models.SlotHold) error
+	GetSlotHolds(from, to time.Time) ([]models.SlotHold, error) // unexpired holds overlapping [from, to)
+	DeleteSlotHold(id string) error
+	DeleteExpiredSlotHolds(now time.Time) ([]models.SlotHold, error)
+	DeleteSlotHoldsBySession(sessionID string) error
+
+	// External calendar busy time
+	GetBusyBlocks(from, to time.Time) ([]models.BusyBlock, error) // blocks overlapping [from, to), all providers
+	// ReplaceBusyBlocks replaces a provider's blocks overlapping [from, to)
+	// with the given ones, as pulled in one sync
+	ReplaceBusyBlocks(providerID string, from, to time.Time, blocks []models.BusyBlock) error
+
+	// Waitlist operations
+	CreateWaitlistEntry(entry *models.WaitlistEntry) error
+	GetWaitlistEntries(status string) ([]models.WaitlistEntry, error) // oldest first
+	GetWaitlistEntryByID(id string) (*models.WaitlistEntry, error)
+	UpdateWaitlistEntry(entry *models.WaitlistEntry) error
+
+	// Provider operations
+	GetProviders() ([]models.Provider, error) // active providers, ordered by name
+	GetProviderByID(id string) (*models.Provider, error)
+	CreateProvider(provider *models.Provider) error
+
+	// Service catalog operations
+	GetServices() ([]models.Service, error) // active services, ordered by name
+	GetServiceByID(id string) (*models.Service, error)
+	CreateService(service *models.Service) error
+
+	// Calendar feed token operations
+	// SaveCalendarToken stores token for phone unless phone already has one,
+	// and returns the token phone ends up with
+	SaveCalendarToken(phone, token string) (string, error)
+	GetPhoneByCalendarToken(token string) (string, error) // "" for unknown tokens
+
+	// Call Summary operations
+	SaveCallSummary(summary *models.CallSummary) error
+	GetCallSummariesByPhone(phone string) ([]models.CallSummary, error)
+}
+
+// Store drivers
+const (
+	DriverSupabase = "supabase"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// NewStore creates the store selected by cfg.DatabaseDriver
+func NewStore(cfg *config.Config) (Store, error) {
+	switch cfg.DatabaseDriver {
+	case DriverSupabase, "":
+		return NewSupabaseClient(cfg), nil
+	case DriverPostgres:
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+		return NewPostgresStore(ctx, cfg.DatabaseURL)
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", cfg.DatabaseDriver)
+	}
+}
+
+var (
+	_ Store = (*SupabaseClient)(nil)
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -7,8 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/voice-agent/backend/internal/config"
 	"github.com/voice-agent/backend/internal/models"
 )
@@ -19,15 +21,13 @@ type SupabaseClient struct {
 	client *http.Client
 }
 
-var DB *SupabaseClient
-
-func Initialize(cfg *config.Config) error {
-	DB = &SupabaseClient{
+// NewSupabaseClient creates a Store backed by the Supabase PostgREST API
+func NewSupabaseClient(cfg *config.Config) *SupabaseClient {
+	return &SupabaseClient{
 		URL:    cfg.SupabaseURL,
 		APIKey: cfg.SupabaseAPIKey,
 		client: &http.Client{Timeout: 10 * time.Second},
 	}
-	return nil
 }
 
 func (s *SupabaseClient) doRequest(method, endpoint string, body interface{}, result interface{}) error {
@@ -62,6 +62,12 @@ func (s *SupabaseClient) doRequest(method, endpoint string, body interface{}, re
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// 23P01 is exclusion_violation, raised by the appointments_no_overlap and
+	// slot_holds_no_overlap constraints
+	if resp.StatusCode == http.StatusConflict && strings.Contains(string(respBody), "23P01") {
+		return fmt.Errorf("%w: %s", ErrSlotUnavailable, string(respBody))
+	}
+
 	if resp.StatusCode >= 400 {
 		return fmt.Errorf("supabase error (status %d): %s", resp.StatusCode, string(respBody))
 	}
@@ -108,6 +114,9 @@ func (s *SupabaseClient) UpdateUser(user *models.User) error {
 	return s.doRequest("PATCH", endpoint, user, nil)
 }
 
+// activeStatusFilter matches appointments that still occupy their slot
+var activeStatusFilter = "(" + strings.Join(models.ActiveStatuses, ",") + ")"
+
 // Appointment operations
 func (s *SupabaseClient) CreateAppointment(apt *models.Appointment) error {
 	var result []models.Appointment
@@ -153,7 +162,7 @@ func (s *SupabaseClient) UpdateAppointment(apt *models.Appointment) error {
 	return s.doRequest("PATCH", endpoint, apt, nil)
 }
 
-func (s *SupabaseClient) CheckSlotAvailability(dateTime time.Time, duration int) (bool, error) {
+func (s *SupabaseClient) CheckSlotAvailability(providerID string, dateTime time.Time, duration int) (bool, error) {
 	// Check if there's any overlapping appointment
 	// An appointment overlaps if it starts before the requested slot ends
 	// and its end time (date_time + duration) is after the requested slot starts
@@ -163,10 +172,15 @@ func (s *SupabaseClient) CheckSlotAvailability(dateTime time.Time, duration int)
 	// Get appointments that start before the requested end time and are booked
 	// We'll need to check overlap in code since PostgREST doesn't easily support
 	// checking if (date_time + duration) overlaps with our range
+	providerFilter := "provider_id=is.null"
+	if providerID != "" {
+		providerFilter = "provider_id=eq." + url.QueryEscape(providerID)
+	}
+
 	var appointments []models.Appointment
 	endpoint := fmt.Sprintf(
-		"appointments?status=eq.booked&date_time=lt.%s&order=date_time.desc",
-		requestedEnd.Format(time.RFC3339),
+		"appointments?status=in.%s&%s&date_time=lt.%s&order=date_time.desc",
+		activeStatusFilter, providerFilter, url.QueryEscape(requestedEnd.Format(time.RFC3339)),
 	)
 
 	if err := s.doRequest("GET", endpoint, nil, &appointments); err != nil {
@@ -175,16 +189,24 @@ func (s *SupabaseClient) CheckSlotAvailability(dateTime time.Time, duration int)
 
 	// Check if any appointment overlaps with the requested slot
 	for _, apt := range appointments {
-		aptStart := apt.DateTime
-		aptEnd := aptStart.Add(time.Duration(apt.Duration) * time.Minute)
-
 		// Check for overlap: appointments overlap if one starts before the other ends
-		if aptStart.Before(requestedEnd) && aptEnd.After(requestedStart) {
+		if apt.DateTime.Before(requestedEnd) && apt.BlockEnd().After(requestedStart) {
 			return false, nil
 		}
 	}
 
-	return true, nil
+	// Busy time pulled from the provider's external calendar
+	var blocks []models.BusyBlock
+	endpoint = fmt.Sprintf("busy_blocks?select=id&%s&date_time=lt.%s&end_time=gt.%s&limit=1",
+		providerFilter,
+		url.QueryEscape(requestedEnd.Format(time.RFC3339)),
+		url.QueryEscape(requestedStart.Format(time.RFC3339)),
+	)
+	if err := s.doRequest("GET", endpoint, nil, &blocks); err != nil {
+		return false, err
+	}
+
+	return len(blocks) == 0, nil
 }
 
 // GetUpcomingAppointments gets all upcoming appointments for a user (filters locally, no DB timestamp issues)
@@ -199,7 +221,7 @@ func (s *SupabaseClient) GetUpcomingAppointments(phone string) ([]models.Appoint
 	now := time.Now()
 	var upcoming []models.Appointment
 	for _, apt := range appointments {
-		if apt.DateTime.After(now) && apt.Status == "booked" {
+		if apt.DateTime.After(now) && apt.IsActive() {
 			upcoming = append(upcoming, apt)
 		}
 	}
@@ -213,8 +235,8 @@ func (s *SupabaseClient) GetUpcomingAppointmentsInWindow(from time.Time, to time
 	fromStr := from.Format(time.RFC3339)
 	toStr := to.Format(time.RFC3339)
 	endpoint := fmt.Sprintf(
-		"appointments?status=eq.booked&date_time=gte.%s&date_time=lte.%s&order=date_time.asc",
-		fromStr, toStr,
+		"appointments?status=in.%s&date_time=gte.%s&date_time=lte.%s&order=date_time.asc",
+		activeStatusFilter, fromStr, toStr,
 	)
 
 	if err := s.doRequest("GET", endpoint, nil, &appointments); err != nil {
@@ -224,6 +246,358 @@ func (s *SupabaseClient) GetUpcomingAppointmentsInWindow(from time.Time, to time
 	return appointments, nil
 }
 
+// GetBookedAppointments gets booked appointments whose calendar block overlaps a range
+func (s *SupabaseClient) GetBookedAppointments(from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	endpoint := fmt.Sprintf(
+		"appointments?status=in.%s&date_time=lt.%s&end_time=gt.%s&order=date_time.asc",
+		activeStatusFilter, url.QueryEscape(to.Format(time.RFC3339)), url.QueryEscape(from.Format(time.RFC3339)),
+	)
+
+	if err := s.doRequest("GET", endpoint, nil, &appointments); err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+// Recurring series operations
+func (s *SupabaseClient) CreateAppointmentSeries(series *models.AppointmentSeries) error {
+	if series.ID == "" {
+		series.ID = uuid.New().String()
+	}
+	var result []models.AppointmentSeries
+	if err := s.doRequest("POST", "appointment_series", series, &result); err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		*series = result[0]
+	}
+	return nil
+}
+
+func (s *SupabaseClient) GetAppointmentSeries(id string) (*models.AppointmentSeries, error) {
+	var series []models.AppointmentSeries
+	endpoint := fmt.Sprintf("appointment_series?id=eq.%s", url.QueryEscape(id))
+
+	if err := s.doRequest("GET", endpoint, nil, &series); err != nil {
+		return nil, err
+	}
+
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	return &series[0], nil
+}
+
+func (s *SupabaseClient) UpdateAppointmentSeries(series *models.AppointmentSeries) error {
+	endpoint := fmt.Sprintf("appointment_series?id=eq.%s", series.ID)
+	series.UpdatedAt = time.Now()
+	return s.doRequest("PATCH", endpoint, series, nil)
+}
+
+func (s *SupabaseClient) DeleteAppointmentSeries(id string) error {
+	endpoint := fmt.Sprintf("appointment_series?id=eq.%s", url.QueryEscape(id))
+	return s.doRequest("DELETE", endpoint, nil, nil)
+}
+
+func (s *SupabaseClient) GetAppointmentsBySeries(seriesID string) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	endpoint := fmt.Sprintf("appointments?series_id=eq.%s&order=date_time.asc", url.QueryEscape(seriesID))
+
+	if err := s.doRequest("GET", endpoint, nil, &appointments); err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+// Slot hold operations
+func (s *SupabaseClient) CreateSlotHold(hold *models.SlotHold) error {
+	if hold.ID == "" {
+		hold.ID = uuid.New().String()
+	}
+	if hold.CreatedAt.IsZero() {
+		hold.CreatedAt = time.Now()
+	}
+
+	// Expired holds still count against slot_holds_no_overlap until swept
+	providerFilter := "provider_id=is.null"
+	if hold.ProviderID != "" {
+		providerFilter = "provider_id=eq." + url.QueryEscape(hold.ProviderID)
+	}
+	expired := fmt.Sprintf("slot_holds?%s&date_time=lt.%s&end_time=gt.%s&expires_at=lte.%s",
+		providerFilter,
+		url.QueryEscape(hold.EndTime.Format(time.RFC3339)),
+		url.QueryEscape(hold.DateTime.Format(time.RFC3339)),
+		url.QueryEscape(time.Now().Format(time.RFC3339)),
+	)
+	if err := s.doRequest("DELETE", expired, nil, nil); err != nil {
+		return err
+	}
+
+	var result []models.SlotHold
+	if err := s.doRequest("POST", "slot_holds", hold, &result); err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		*hold = result[0]
+	}
+	return nil
+}
+
+func (s *SupabaseClient) GetSlotHolds(from, to time.Time) ([]models.SlotHold, error) {
+	var holds []models.SlotHold
+	endpoint := fmt.Sprintf("slot_holds?date_time=lt.%s&end_time=gt.%s&expires_at=gt.%s&order=date_time.asc",
+		url.QueryEscape(to.Format(time.RFC3339)),
+		url.QueryEscape(from.Format(time.RFC3339)),
+		url.QueryEscape(time.Now().Format(time.RFC3339)),
+	)
+
+	if err := s.doRequest("GET", endpoint, nil, &holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (s *SupabaseClient) DeleteSlotHold(id string) error {
+	endpoint := fmt.Sprintf("slot_holds?id=eq.%s", url.QueryEscape(id))
+	return s.doRequest("DELETE", endpoint, nil, nil)
+}
+
+func (s *SupabaseClient) DeleteSlotHoldsBySession(sessionID string) error {
+	endpoint := fmt.Sprintf("slot_holds?session_id=eq.%s", url.QueryEscape(sessionID))
+	return s.doRequest("DELETE", endpoint, nil, nil)
+}
+
+func (s *SupabaseClient) DeleteExpiredSlotHolds(now time.Time) ([]models.SlotHold, error) {
+	var holds []models.SlotHold
+	endpoint := fmt.Sprintf("slot_holds?expires_at=lte.%s", url.QueryEscape(now.Format(time.RFC3339)))
+
+	if err := s.doRequest("DELETE", endpoint, nil, &holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// Busy block operations
+func (s *SupabaseClient) GetBusyBlocks(from, to time.Time) ([]models.BusyBlock, error) {
+	var blocks []models.BusyBlock
+	endpoint := fmt.Sprintf("busy_blocks?date_time=lt.%s&end_time=gt.%s&order=date_time.asc",
+		url.QueryEscape(to.Format(time.RFC3339)),
+		url.QueryEscape(from.Format(time.RFC3339)),
+	)
+
+	if err := s.doRequest("GET", endpoint, nil, &blocks); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ReplaceBusyBlocks deletes then inserts; PostgREST has no transaction, so a
+// failed insert leaves the window empty until the next sync
+func (s *SupabaseClient) ReplaceBusyBlocks(providerID string, from, to time.Time, blocks []models.BusyBlock) error {
+	providerFilter := "provider_id=is.null"
+	if providerID != "" {
+		providerFilter = "provider_id=eq." + url.QueryEscape(providerID)
+	}
+	endpoint := fmt.Sprintf("busy_blocks?%s&date_time=lt.%s&end_time=gt.%s",
+		providerFilter,
+		url.QueryEscape(to.Format(time.RFC3339)),
+		url.QueryEscape(from.Format(time.RFC3339)),
+	)
+	if err := s.doRequest("DELETE", endpoint, nil, nil); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]interface{}, len(blocks))
+	for i, block := range blocks {
+		row := map[string]interface{}{
+			"date_time":    block.DateTime,
+			"end_time":     block.EndTime,
+			"external_uid": block.ExternalUID,
+		}
+		if providerID != "" {
+			row["provider_id"] = providerID
+		}
+		rows[i] = row
+	}
+	return s.doRequest("POST", "busy_blocks", rows, nil)
+}
+
+// Waitlist operations
+func (s *SupabaseClient) CreateWaitlistEntry(entry *models.WaitlistEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	var result []models.WaitlistEntry
+	if err := s.doRequest("POST", "waitlist", entry, &result); err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		*entry = result[0]
+	}
+	return nil
+}
+
+func (s *SupabaseClient) GetWaitlistEntries(status string) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	endpoint := fmt.Sprintf("waitlist?status=eq.%s&order=created_at.asc", url.QueryEscape(status))
+
+	if err := s.doRequest("GET", endpoint, nil, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *SupabaseClient) GetWaitlistEntryByID(id string) (*models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	endpoint := fmt.Sprintf("waitlist?id=eq.%s", url.QueryEscape(id))
+
+	if err := s.doRequest("GET", endpoint, nil, &entries); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return &entries[0], nil
+}
+
+func (s *SupabaseClient) UpdateWaitlistEntry(entry *models.WaitlistEntry) error {
+	endpoint := fmt.Sprintf("waitlist?id=eq.%s", entry.ID)
+	entry.UpdatedAt = time.Now()
+	return s.doRequest("PATCH", endpoint, entry, nil)
+}
+
+// Provider operations
+func (s *SupabaseClient) GetProviders() ([]models.Provider, error) {
+	var providers []models.Provider
+	if err := s.doRequest("GET", "providers?active=eq.true&order=name.asc", nil, &providers); err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+func (s *SupabaseClient) GetProviderByID(id string) (*models.Provider, error) {
+	var providers []models.Provider
+	endpoint := fmt.Sprintf("providers?id=eq.%s", url.QueryEscape(id))
+
+	if err := s.doRequest("GET", endpoint, nil, &providers); err != nil {
+		return nil, err
+	}
+
+	if len(providers) == 0 {
+		return nil, nil
+	}
+
+	return &providers[0], nil
+}
+
+func (s *SupabaseClient) CreateProvider(provider *models.Provider) error {
+	if provider.ID == "" {
+		provider.ID = uuid.New().String()
+	}
+	var result []models.Provider
+	if err := s.doRequest("POST", "providers", provider, &result); err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		*provider = result[0]
+	}
+	return nil
+}
+
+// Service catalog operations
+func (s *SupabaseClient) GetServices() ([]models.Service, error) {
+	var services []models.Service
+	if err := s.doRequest("GET", "services?active=eq.true&order=name.asc", nil, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (s *SupabaseClient) GetServiceByID(id string) (*models.Service, error) {
+	var services []models.Service
+	endpoint := fmt.Sprintf("services?id=eq.%s", url.QueryEscape(id))
+
+	if err := s.doRequest("GET", endpoint, nil, &services); err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	return &services[0], nil
+}
+
+func (s *SupabaseClient) CreateService(service *models.Service) error {
+	if service.ID == "" {
+		service.ID = uuid.New().String()
+	}
+	var result []models.Service
+	if err := s.doRequest("POST", "services", service, &result); err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		*service = result[0]
+	}
+	return nil
+}
+
+// calendarToken is a row of the calendar_tokens table
+type calendarToken struct {
+	Token       string `json:"token"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// Calendar feed token operations
+func (s *SupabaseClient) SaveCalendarToken(phone, token string) (string, error) {
+	existing, err := s.calendarTokens("phone_number", phone)
+	if err != nil {
+		return "", err
+	}
+	if len(existing) > 0 {
+		return existing[0].Token, nil
+	}
+
+	if err := s.doRequest("POST", "calendar_tokens", calendarToken{Token: token, PhoneNumber: phone}, nil); err != nil {
+		// Another request may have saved one for phone first
+		if existing, _ := s.calendarTokens("phone_number", phone); len(existing) > 0 {
+			return existing[0].Token, nil
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *SupabaseClient) GetPhoneByCalendarToken(token string) (string, error) {
+	existing, err := s.calendarTokens("token", token)
+	if err != nil || len(existing) == 0 {
+		return "", err
+	}
+	return existing[0].PhoneNumber, nil
+}
+
+func (s *SupabaseClient) calendarTokens(column, value string) ([]calendarToken, error) {
+	var tokens []calendarToken
+	endpoint := fmt.Sprintf("calendar_tokens?%s=eq.%s", column, url.QueryEscape(value))
+	if err := s.doRequest("GET", endpoint, nil, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
 // Call Summary operations
 func (s *SupabaseClient) SaveCallSummary(summary *models.CallSummary) error {
 	var result []models.CallSummary
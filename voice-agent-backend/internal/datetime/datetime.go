@@ -0,0 +1,760 @@
+// Package datetime resolves spoken date and time expressions such as "next
+// Tuesday afternoon", "the 3rd at half past two" or "in two weeks" into
+// concrete times, so the LLM does not have to do calendar arithmetic.
+package datetime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resolution is what an expression refers to: an exact time when a time of
+// day was given, otherwise a range such as a day, an afternoon or a week.
+type Resolution struct {
+	Start time.Time // the resolved time, or the start of the range
+	End   time.Time // end of the range (exclusive); equal to Start when Exact
+	Exact bool
+	Part  string // "morning", "afternoon" or "evening" when the range is part of a day
+}
+
+// Parts of the day, as [start, end) hours
+var dayParts = map[string][2]int{
+	"morning":   {8, 12},
+	"afternoon": {12, 17},
+	"evening":   {17, 21},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11,
+	"twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+	"seventeen": 17, "eighteen": 18, "nineteen": 19, "twenty": 20, "thirty": 30,
+	"forty": 40, "fifty": 50, "couple": 2, "few": 3,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6,
+	"seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10, "eleventh": 11,
+	"twelfth": 12, "thirteenth": 13, "fourteenth": 14, "fifteenth": 15,
+	"sixteenth": 16, "seventeenth": 17, "eighteenth": 18, "nineteenth": 19,
+	"twentieth": 20, "thirtieth": 30,
+}
+
+// Words that carry no meaning of their own
+var fillers = map[string]bool{
+	"on": true, "at": true, "the": true, "of": true, "in": true, "around": true,
+	"about": true, "for": true, "by": true, "please": true, "and": true,
+	"maybe": true, "say": true, "sometime": true, "some": true, "time": true,
+}
+
+// Layouts tried before reading an expression as words
+var isoLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// Resolve resolves expr relative to now in loc. "Tuesday" and "this
+// Tuesday" are the coming Tuesday (today if it is one), "next Tuesday" is
+// the Tuesday of next week, and a date or time without a year or day that
+// has already passed is the next one to come. Hours from 1 to 7 without am
+// or pm are taken to be in the afternoon.
+func Resolve(expr string, now time.Time, loc *time.Location) (*Resolution, error) {
+	now = now.In(loc)
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty date expression")
+	}
+
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return &Resolution{Start: t, End: t, Exact: true}, nil
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.ParseInLocation(layout, expr, loc); err == nil {
+			return &Resolution{Start: t, End: t, Exact: true}, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", expr, loc); err == nil {
+		return &Resolution{Start: t, End: t.AddDate(0, 0, 1)}, nil
+	}
+
+	p := &parser{tokens: tokenize(expr), now: now, loc: loc, hour: -1}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.resolve()
+}
+
+// token is one word of an expression; numbers keep their value
+type token struct {
+	text    string
+	date    bool // "2024-01-15"
+	num     int
+	isNum   bool
+	ordinal bool // "3rd", "third"
+	clock   bool // "14:30"
+	minute  int  // minutes of a clock token
+}
+
+func tokenize(expr string) []token {
+	s := strings.ToLower(expr)
+	s = strings.NewReplacer("a.m.", " am ", "p.m.", " pm ", "o'clock", " oclock ", "o’clock", " oclock ").Replace(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ',', ';', '!', '?':
+			return ' '
+		}
+		return r
+	}, s)
+
+	var fields []string
+	for _, field := range strings.Fields(s) {
+		// Keep "2024-01-15" whole; other dashes, slashes and dots separate words
+		if _, err := time.Parse("2006-01-02", field); err == nil {
+			fields = append(fields, field)
+			continue
+		}
+		fields = append(fields, strings.Fields(strings.NewReplacer("-", " ", "/", " ", ".", " ").Replace(field))...)
+	}
+
+	var tokens []token
+	for _, field := range fields {
+		// Split "3pm" and "3:30am" into the time and the meridiem
+		for _, suffix := range []string{"am", "pm"} {
+			if rest := strings.TrimSuffix(field, suffix); rest != field && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+				tokens = append(tokens, readToken(rest), token{text: suffix})
+				field = ""
+				break
+			}
+		}
+		if field != "" {
+			tokens = append(tokens, readToken(field))
+		}
+	}
+	return tokens
+}
+
+func readToken(field string) token {
+	t := token{text: field}
+	if _, err := time.Parse("2006-01-02", field); err == nil {
+		t.date = true
+		return t
+	}
+	if h, m, ok := strings.Cut(field, ":"); ok {
+		hour, err1 := strconv.Atoi(h)
+		minute, err2 := strconv.Atoi(m)
+		if err1 == nil && err2 == nil && len(m) == 2 {
+			t.num, t.minute, t.isNum, t.clock = hour, minute, true, true
+		}
+		return t
+	}
+	digits := field
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if rest := strings.TrimSuffix(field, suffix); rest != field && rest != "" {
+			digits = rest
+			t.ordinal = true
+			break
+		}
+	}
+	if n, err := strconv.Atoi(digits); err == nil {
+		t.num, t.isNum = n, true
+		return t
+	}
+	t.ordinal = false
+	if n, ok := ordinalWords[field]; ok {
+		t.num, t.isNum, t.ordinal = n, true, true
+	} else if n, ok := numberWords[field]; ok {
+		t.num, t.isNum = n, true
+	}
+	return t
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+	loc    *time.Location
+
+	date     time.Time // set by today, tomorrow, "in 3 days", ...
+	hasDate  bool
+	month    time.Month // set by a month name
+	day      int        // day of the month
+	year     int
+	weekday  *time.Weekday
+	nextWeek bool   // "next Tuesday", "Tuesday next week", "next week"
+	span     string // "week", "weekend" or "month" for ranges longer than a day
+
+	hour, minute int // hour is -1 until a time is given
+	meridiem     string
+	part         string
+	offset       time.Duration // "in 2 hours"
+	hasOffset    bool
+}
+
+func (p *parser) peek(n int) token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return token{}
+}
+
+func (p *parser) parse() error {
+	for p.pos < len(p.tokens) {
+		n, err := p.step()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("could not understand %q", p.tokens[p.pos].text)
+		}
+		p.pos += n
+	}
+	return nil
+}
+
+// step consumes the phrase at the current token and returns how many tokens it used
+func (p *parser) step() (int, error) {
+	tok := p.peek(0)
+
+	if tok.date {
+		d, _ := time.ParseInLocation("2006-01-02", tok.text, p.loc)
+		p.setDate(d)
+		return 1, nil
+	}
+	if n := p.relative(); n > 0 {
+		return n, nil
+	}
+
+	switch tok.text {
+	case "today":
+		p.setDate(p.today())
+		return 1, nil
+	case "tonight":
+		p.setDate(p.today())
+		p.part = "evening"
+		return 1, nil
+	case "tomorrow", "tmrw", "tomorow":
+		p.setDate(p.today().AddDate(0, 0, 1))
+		return 1, nil
+	case "day":
+		if p.peek(1).text == "after" && p.peek(2).text == "tomorrow" {
+			p.setDate(p.today().AddDate(0, 0, 2))
+			return 3, nil
+		}
+	case "next", "following", "this", "coming":
+		next := tok.text == "next" || tok.text == "following"
+		following := p.peek(1)
+		switch {
+		case following.text == "week":
+			p.nextWeek = p.nextWeek || next
+			p.span = "week"
+			return 2, nil
+		case following.text == "weekend":
+			p.nextWeek = p.nextWeek || next
+			p.span = "weekend"
+			return 2, nil
+		case following.text == "month":
+			p.span = "month"
+			p.setDate(p.monthStart(next))
+			return 2, nil
+		case isWeekday(following.text):
+			wd := weekdays[following.text]
+			p.weekday = &wd
+			p.nextWeek = p.nextWeek || next
+			return 2, nil
+		case dayParts[following.text] != [2]int{}:
+			p.part = following.text
+			return 2, nil
+		}
+		if tok.text == "this" {
+			return 1, nil
+		}
+	case "week":
+		p.span = "week"
+		return 1, nil
+	case "weekend":
+		p.span = "weekend"
+		return 1, nil
+	case "noon", "midday":
+		p.setTime(12, 0)
+		p.meridiem = "pm"
+		return 1, nil
+	case "midnight":
+		p.setTime(0, 0)
+		p.meridiem = "am"
+		return 1, nil
+	case "am", "pm":
+		p.meridiem = tok.text
+		return 1, nil
+	case "oclock":
+		return 1, nil
+	case "night":
+		p.part = "evening"
+		return 1, nil
+	case "half", "quarter":
+		return p.pastTo()
+	}
+
+	if isWeekday(tok.text) {
+		wd := weekdays[tok.text]
+		p.weekday = &wd
+		return 1, nil
+	}
+	if _, ok := dayParts[tok.text]; ok {
+		p.part = tok.text
+		return 1, nil
+	}
+	if month, ok := months[tok.text]; ok && !(tok.text == "may" && !p.peek(1).isNum && p.peek(1).text != "") {
+		p.month = month
+		// "March 3", "March 3rd", "March 3rd 2025"
+		if next := p.peek(1); next.isNum && !next.clock && next.num >= 1 && next.num <= 31 && (p.peek(2).text != "oclock" && !isMeridiem(p.peek(2).text)) {
+			p.day = next.num
+			if year := p.peek(2); year.isNum && year.num >= 1000 {
+				p.year = year.num
+				return 3, nil
+			}
+			return 2, nil
+		}
+		return 1, nil
+	}
+
+	if tok.isNum {
+		return p.number(tok)
+	}
+	if fillers[tok.text] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// relative reads "in 2 weeks", "3 days from now" and "a week from today"
+func (p *parser) relative() int {
+	start := 0
+	if p.peek(0).text == "in" {
+		start = 1
+	}
+	qty, n := p.quantity(start)
+	if n == 0 {
+		return 0
+	}
+	unit := p.peek(start + n).text
+	d, days, months, ok := unitOf(unit)
+	if !ok {
+		return 0
+	}
+	used := start + n + 1
+	if p.peek(used).text == "from" && (p.peek(used+1).text == "now" || p.peek(used+1).text == "today") {
+		used += 2
+	} else if p.peek(used).text == "later" {
+		used++
+	} else if start == 0 {
+		// "2 weeks" on its own is a duration, not a date
+		return 0
+	}
+
+	switch {
+	case d > 0:
+		p.offset = time.Duration(qty) * d
+		p.hasOffset = true
+	case months > 0:
+		p.setDate(p.today().AddDate(0, qty*months, 0))
+	default:
+		p.setDate(p.today().AddDate(0, 0, qty*days))
+	}
+	return used
+}
+
+// quantity reads "2", "two", "a couple of" or "a few" starting at token i
+func (p *parser) quantity(i int) (int, int) {
+	tok := p.peek(i)
+	if !tok.isNum || tok.ordinal || tok.clock {
+		return 0, 0
+	}
+	if (tok.text == "a" || tok.text == "an") && (p.peek(i+1).text == "couple" || p.peek(i+1).text == "few") {
+		n := numberWords[p.peek(i+1).text]
+		if p.peek(i+2).text == "of" {
+			return n, 3
+		}
+		return n, 2
+	}
+	return tok.num, 1
+}
+
+func unitOf(unit string) (d time.Duration, days, months int, ok bool) {
+	switch strings.TrimSuffix(unit, "s") {
+	case "minute", "min":
+		return time.Minute, 0, 0, true
+	case "hour", "hr":
+		return time.Hour, 0, 0, true
+	case "day":
+		return 0, 1, 0, true
+	case "week", "wk":
+		return 0, 7, 0, true
+	case "fortnight":
+		return 0, 14, 0, true
+	case "month":
+		return 0, 0, 1, true
+	}
+	return 0, 0, 0, false
+}
+
+// pastTo reads "half past two", "quarter past 3" and "quarter to 4"
+func (p *parser) pastTo() (int, error) {
+	minutes := 30
+	if p.peek(0).text == "quarter" {
+		minutes = 15
+	}
+	dir, hour := p.peek(1).text, p.peek(2)
+	if (dir != "past" && dir != "to") || !hour.isNum || hour.ordinal || hour.clock {
+		return 0, nil
+	}
+	if dir == "to" {
+		if minutes == 30 {
+			return 0, nil
+		}
+		p.setTime(hourBefore(hour.num), 60-minutes)
+	} else {
+		p.setTime(hour.num, minutes)
+	}
+	return 3, nil
+}
+
+// number reads a bare number: a day of the month when it is an ordinal or
+// next to a month name, a number of minutes "past" or "to" an hour, and
+// otherwise a time of day
+func (p *parser) number(tok token) (int, error) {
+	switch tok.text {
+	case "a", "an":
+		return 1, nil
+	case "couple", "few":
+		return 0, nil
+	}
+	if tok.clock {
+		p.setTime(tok.num, tok.minute)
+		return 1, nil
+	}
+
+	// "3rd of March", "3 March"
+	next := p.peek(1)
+	monthAt := 1
+	if next.text == "of" {
+		monthAt = 2
+	}
+	if month, ok := months[p.peek(monthAt).text]; ok && tok.num >= 1 && tok.num <= 31 {
+		p.month, p.day = month, tok.num
+		if year := p.peek(monthAt + 1); year.isNum && year.num >= 1000 {
+			p.year = year.num
+			return monthAt + 2, nil
+		}
+		return monthAt + 1, nil
+	}
+	// "twenty first"
+	if (tok.num == 20 || tok.num == 30) && next.ordinal && next.num >= 1 && next.num <= 9 && !tok.ordinal {
+		tok = token{text: tok.text + " " + next.text, num: tok.num + next.num, isNum: true, ordinal: true}
+		if n, err := p.number(tok); n == 0 || err != nil {
+			return n, err
+		}
+		return 2, nil
+	}
+	if tok.ordinal {
+		if tok.num < 1 || tok.num > 31 {
+			return 0, fmt.Errorf("there is no %s day of a month", tok.text)
+		}
+		p.day = tok.num
+		return 1, nil
+	}
+	if tok.num >= 1000 {
+		p.year = tok.num
+		return 1, nil
+	}
+
+	// "20 past 3", "10 to 4"
+	if (next.text == "past" || next.text == "to") && p.peek(2).isNum && tok.num < 60 {
+		hour := p.peek(2).num
+		if next.text == "to" {
+			p.setTime(hourBefore(hour), 60-tok.num)
+		} else {
+			p.setTime(hour, tok.num)
+		}
+		if p.peek(3).text == "minutes" || p.peek(3).text == "minute" {
+			return 0, nil
+		}
+		return 3, nil
+	}
+	if (next.text == "minutes" || next.text == "minute") && (p.peek(2).text == "past" || p.peek(2).text == "to") && p.peek(3).isNum {
+		hour := p.peek(3).num
+		if p.peek(2).text == "to" {
+			p.setTime(hourBefore(hour), 60-tok.num)
+		} else {
+			p.setTime(hour, tok.num)
+		}
+		return 4, nil
+	}
+
+	// "two thirty", "10 45", "four fifty five"
+	if next.isNum && !next.ordinal && !next.clock && next.num >= 10 && next.num < 60 && tok.num <= 24 {
+		minute, used := next.num, 2
+		if after := p.peek(2); next.num%10 == 0 && next.num >= 20 && after.isNum && !after.ordinal && after.num >= 1 && after.num <= 9 {
+			minute += after.num
+			used = 3
+		}
+		p.setTime(tok.num, minute)
+		return used, nil
+	}
+
+	if tok.num > 24 {
+		return 0, fmt.Errorf("%d is not a time of day", tok.num)
+	}
+	p.setTime(tok.num, 0)
+	return 1, nil
+}
+
+func (p *parser) setDate(d time.Time) {
+	p.date = d
+	p.hasDate = true
+}
+
+func (p *parser) setTime(hour, minute int) {
+	p.hour, p.minute = hour, minute
+}
+
+// hourBefore is the hour "to" counts back from, so quarter to one is 12:45
+func hourBefore(hour int) int {
+	if hour <= 1 {
+		return hour + 11
+	}
+	return hour - 1
+}
+
+// today is midnight of the current day
+func (p *parser) today() time.Time {
+	y, m, d := p.now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, p.loc)
+}
+
+// monthStart is the first of next month, or today for the current one
+func (p *parser) monthStart(next bool) time.Time {
+	if !next {
+		return p.today()
+	}
+	y, m, _ := p.now.Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, p.loc)
+}
+
+// weekStart is the Monday starting the current week, or the next one
+func (p *parser) weekStart(next bool) time.Time {
+	today := p.today()
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	if next {
+		monday = monday.AddDate(0, 0, 7)
+	}
+	return monday
+}
+
+func (p *parser) resolve() (*Resolution, error) {
+	if p.hasOffset {
+		if p.hasDate || p.weekday != nil || p.day != 0 || p.hour >= 0 {
+			return nil, fmt.Errorf("cannot combine a number of hours or minutes from now with another date or time")
+		}
+		t := p.now.Add(p.offset).Truncate(time.Minute)
+		return &Resolution{Start: t, End: t, Exact: true}, nil
+	}
+
+	start, end, err := p.dateRange()
+	if err != nil {
+		return nil, err
+	}
+
+	if (p.hour >= 0 || p.part != "") && end.Sub(start) > 25*time.Hour { // a day can be 25 hours across DST
+		return nil, fmt.Errorf("say which day you mean")
+	}
+
+	if p.hour >= 0 {
+		hour, err := p.clockHour()
+		if err != nil {
+			return nil, err
+		}
+		t := time.Date(start.Year(), start.Month(), start.Day(), hour, p.minute, 0, 0, p.loc)
+		// A time alone that has passed today is tomorrow's
+		if !p.hasDate && p.weekday == nil && p.day == 0 && p.span == "" && t.Before(p.now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &Resolution{Start: t, End: t, Exact: true}, nil
+	}
+
+	if p.part != "" {
+		hours := dayParts[p.part]
+		from := time.Date(start.Year(), start.Month(), start.Day(), hours[0], 0, 0, 0, p.loc)
+		to := time.Date(start.Year(), start.Month(), start.Day(), hours[1], 0, 0, 0, p.loc)
+		return &Resolution{Start: from, End: to, Part: p.part}, nil
+	}
+
+	return &Resolution{Start: start, End: end}, nil
+}
+
+// dateRange works out the day, or longer range, the expression refers to
+func (p *parser) dateRange() (time.Time, time.Time, error) {
+	today := p.today()
+
+	if p.day != 0 || p.month != 0 || p.year != 0 {
+		if p.hasDate || p.nextWeek || p.span != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("conflicting dates")
+		}
+		if p.day == 0 && p.month == 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("say which day in %d you mean", p.year)
+		}
+		d, err := p.calendarDate(today)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if p.day == 0 {
+			// A whole month: from today if it is the current one
+			end := d.AddDate(0, 1, 0)
+			if d.Before(today) {
+				d = today
+			}
+			return d, end, nil
+		}
+		if p.weekday != nil && d.Weekday() != *p.weekday {
+			return time.Time{}, time.Time{}, fmt.Errorf("%s is a %s, not a %s", d.Format("January 2, 2006"), d.Weekday(), *p.weekday)
+		}
+		return d, d.AddDate(0, 0, 1), nil
+	}
+
+	if p.weekday != nil {
+		var d time.Time
+		if p.nextWeek {
+			d = p.weekStart(true).AddDate(0, 0, (int(*p.weekday)+6)%7)
+		} else {
+			d = today.AddDate(0, 0, (int(*p.weekday)-int(today.Weekday())+7)%7)
+		}
+		if p.hasDate && !p.date.Equal(d) {
+			return time.Time{}, time.Time{}, fmt.Errorf("%s is a %s, not a %s", p.date.Format("January 2, 2006"), p.date.Weekday(), *p.weekday)
+		}
+		return d, d.AddDate(0, 0, 1), nil
+	}
+
+	switch p.span {
+	case "week":
+		if p.nextWeek {
+			start := p.weekStart(true)
+			return start, start.AddDate(0, 0, 7), nil
+		}
+		return today, p.weekStart(true), nil
+	case "weekend":
+		saturday := p.weekStart(p.nextWeek).AddDate(0, 0, 5)
+		if saturday.Before(today) {
+			// Sunday of the current weekend
+			return today, today.AddDate(0, 0, 1), nil
+		}
+		return saturday, saturday.AddDate(0, 0, 2), nil
+	case "month":
+		y, m, _ := p.date.Date()
+		return p.date, time.Date(y, m+1, 1, 0, 0, 0, 0, p.loc), nil
+	}
+
+	if p.hasDate {
+		return p.date, p.date.AddDate(0, 0, 1), nil
+	}
+	if p.hour >= 0 || p.part != "" {
+		return today, today.AddDate(0, 0, 1), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("no date or time found")
+}
+
+// calendarDate builds the date from the month, day and year given, filling
+// in the next one to come for whatever is missing
+func (p *parser) calendarDate(today time.Time) (time.Time, error) {
+	year, month := p.year, p.month
+	if month == 0 {
+		// "the 3rd": this month's, or the next month that has that day
+		y, m, _ := today.Date()
+		for i := 0; i < 12; i++ {
+			d := time.Date(y, m+time.Month(i), p.day, 0, 0, 0, 0, p.loc)
+			if d.Day() == p.day && !d.Before(today) {
+				return d, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("there is no day %d coming up", p.day)
+	}
+
+	day := p.day
+	if day == 0 {
+		day = 1
+	}
+	explicitYear := year != 0
+	if !explicitYear {
+		year = today.Year()
+	}
+	d := time.Date(year, month, day, 0, 0, 0, 0, p.loc)
+	if d.Day() != day {
+		return time.Time{}, fmt.Errorf("%s has no day %d", month, day)
+	}
+	if !explicitYear {
+		last := d.AddDate(0, 0, 1)
+		if p.day == 0 {
+			last = d.AddDate(0, 1, 0)
+		}
+		if !last.After(today) {
+			d = d.AddDate(1, 0, 0)
+		}
+	}
+	return d, nil
+}
+
+// clockHour applies am/pm to the hour given
+func (p *parser) clockHour() (int, error) {
+	hour := p.hour
+	if hour > 24 || p.minute < 0 || p.minute > 59 {
+		return 0, fmt.Errorf("%d:%02d is not a time of day", p.hour, p.minute)
+	}
+	switch {
+	case p.meridiem == "pm" && hour < 12:
+		hour += 12
+	case p.meridiem == "am" && hour == 12:
+		hour = 0
+	case p.meridiem == "" && (p.part == "afternoon" || p.part == "evening") && hour < 12:
+		hour += 12
+	case p.meridiem == "" && p.part == "" && hour >= 1 && hour <= 7:
+		hour += 12
+	}
+	if hour == 24 {
+		hour = 0
+	}
+	if hour > 23 {
+		return 0, fmt.Errorf("%d %s is not a time of day", p.hour, p.meridiem)
+	}
+	return hour, nil
+}
+
+func isWeekday(word string) bool {
+	_, ok := weekdays[word]
+	return ok
+}
+
+func isMeridiem(word string) bool {
+	return word == "am" || word == "pm"
+}
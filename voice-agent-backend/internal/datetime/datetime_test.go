@@ -0,0 +1,108 @@
+package datetime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Friday March 6, 2026, 3pm; clocks go forward early on Sunday March 8
+	now := time.Date(2026, 3, 6, 15, 0, 0, 0, newYork)
+	const layout = "2006-01-02 15:04 MST"
+
+	tests := []struct {
+		expr       string
+		start, end string // end is empty for an exact time
+		part       string
+	}{
+		{"2026-03-10T09:30:00", "2026-03-10 09:30 EDT", "", ""},
+		{"2026-03-10", "2026-03-10 00:00 EDT", "2026-03-11 00:00 EDT", ""},
+		{"tomorrow", "2026-03-07 00:00 EST", "2026-03-08 00:00 EST", ""},
+		{"Sunday", "2026-03-08 00:00 EST", "2026-03-09 00:00 EDT", ""},
+		{"Sunday afternoon", "2026-03-08 12:00 EDT", "2026-03-08 17:00 EDT", "afternoon"},
+		{"in 2 days at 10", "2026-03-08 10:00 EDT", "", ""},
+		{"a week from today at 9am", "2026-03-13 09:00 EDT", "", ""},
+		{"next Monday at 9", "2026-03-09 09:00 EDT", "", ""},
+		{"this Friday at 4", "2026-03-06 16:00 EST", "", ""},
+		{"half past two", "2026-03-07 14:30 EST", "", ""},
+		{"quarter to one tomorrow", "2026-03-07 12:45 EST", "", ""},
+		{"the 3rd", "2026-04-03 00:00 EDT", "2026-04-04 00:00 EDT", ""},
+		{"March 31st at 11:15 am", "2026-03-31 11:15 EDT", "", ""},
+		{"next week", "2026-03-09 00:00 EDT", "2026-03-16 00:00 EDT", ""},
+		{"this weekend", "2026-03-07 00:00 EST", "2026-03-09 00:00 EDT", ""},
+		{"tonight", "2026-03-06 17:00 EST", "2026-03-06 21:00 EST", "evening"},
+		{"in 3 hours", "2026-03-06 18:00 EST", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.expr, now, newYork)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if start := got.Start.In(newYork).Format(layout); start != tt.start {
+			t.Errorf("%q: start %s, want %s", tt.expr, start, tt.start)
+		}
+		if tt.end == "" {
+			if !got.Exact || !got.End.Equal(got.Start) {
+				t.Errorf("%q: expected an exact time, got %+v", tt.expr, got)
+			}
+		} else if end := got.End.In(newYork).Format(layout); got.Exact || end != tt.end {
+			t.Errorf("%q: end %s, want %s (exact %v)", tt.expr, end, tt.end, got.Exact)
+		}
+		if got.Part != tt.part {
+			t.Errorf("%q: part %q, want %q", tt.expr, got.Part, tt.part)
+		}
+	}
+}
+
+func TestResolveAcrossTheClockChange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	tests := []struct {
+		expr string
+		now  time.Time
+		want string
+	}{
+		// Hours from now are elapsed time, so they skip the missing hour
+		{"in 3 hours", time.Date(2026, 3, 7, 23, 30, 0, 0, newYork), "2026-03-08 03:30 EDT"},
+		{"in 3 hours", time.Date(2026, 10, 31, 23, 30, 0, 0, newYork), "2026-11-01 01:30 EST"},
+		// Days from now keep the time of day
+		{"in 1 day at 9:30 am", time.Date(2026, 3, 7, 9, 0, 0, 0, newYork), "2026-03-08 09:30 EDT"},
+		{"tomorrow at 9am", time.Date(2026, 10, 31, 9, 0, 0, 0, newYork), "2026-11-01 09:00 EST"},
+		{"in two weeks at 10", time.Date(2026, 10, 26, 9, 0, 0, 0, newYork), "2026-11-09 10:00 EST"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.expr, tt.now, newYork)
+		if err != nil {
+			t.Errorf("%q at %s: %v", tt.expr, tt.now, err)
+			continue
+		}
+		if start := got.Start.In(newYork).Format("2006-01-02 15:04 MST"); start != tt.want {
+			t.Errorf("%q at %s: got %s, want %s", tt.expr, tt.now, start, tt.want)
+		}
+	}
+}
+
+func TestResolveRejectsWhatItCannotPlace(t *testing.T) {
+	now := time.Date(2026, 3, 6, 15, 0, 0, 0, time.UTC)
+	for _, expr := range []string{
+		"",
+		"whenever suits",
+		"Monday March 10",
+		"February 30",
+		"next week at 3pm",
+		"the 32nd",
+		"in 2 hours on Monday",
+		"this month at 9",
+	} {
+		if got, err := Resolve(expr, now, time.UTC); err == nil {
+			t.Errorf("%q: expected an error, got %+v", expr, got)
+		}
+	}
+}
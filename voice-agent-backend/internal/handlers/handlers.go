@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/calendar"
 	"github.com/voice-agent/backend/internal/config"
 	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/lifecycle"
 	"github.com/voice-agent/backend/internal/models"
 	"github.com/voice-agent/backend/internal/services/avatar"
 	"github.com/voice-agent/backend/internal/services/livekit"
@@ -18,15 +24,21 @@ import (
 // Handler holds all HTTP handlers
 type Handler struct {
 	config         *config.Config
+	store          database.Store
+	slots          *availability.Engine
+	feeds          *calendar.Feeds
 	livekitService *livekit.Service
 	avatarService  *avatar.Service
 	wsManager      *websocket.Manager
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(cfg *config.Config, lkService *livekit.Service, avService *avatar.Service, wsManager *websocket.Manager) *Handler {
+func NewHandler(cfg *config.Config, store database.Store, slots *availability.Engine, feeds *calendar.Feeds, lkService *livekit.Service, avService *avatar.Service, wsManager *websocket.Manager) *Handler {
 	return &Handler{
 		config:         cfg,
+		store:          store,
+		slots:          slots,
+		feeds:          feeds,
 		livekitService: lkService,
 		avatarService:  avService,
 		wsManager:      wsManager,
@@ -182,7 +194,7 @@ func (h *Handler) GetAppointments(c *gin.Context) {
 		return
 	}
 
-	appointments, err := database.DB.GetAppointmentsByPhone(phone)
+	appointments, err := h.store.GetAppointmentsByPhone(phone)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("Failed to get appointments: %v", err),
@@ -196,6 +208,136 @@ func (h *Handler) GetAppointments(c *gin.Context) {
 	})
 }
 
+// UpdateAppointmentStatus moves an appointment along its lifecycle, e.g. to
+// check a caller in at the front desk. Cancelling and rescheduling go through
+// the agent so freed slots reach the waitlist.
+func (h *Handler) UpdateAppointmentStatus(c *gin.Context) {
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	switch req.Status {
+	case models.StatusConfirmed, models.StatusCheckedIn, models.StatusCompleted, models.StatusNoShow:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be one of confirmed, checked_in, completed or no_show",
+		})
+		return
+	}
+
+	appointment, err := h.store.GetAppointmentByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get appointment: %v", err),
+		})
+		return
+	}
+	if appointment == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Appointment not found",
+		})
+		return
+	}
+
+	if err := lifecycle.Transition(appointment, req.Status, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  err.Error(),
+			"status": appointment.Status,
+			"next":   lifecycle.Next(appointment.Status),
+		})
+		return
+	}
+	if err := h.store.UpdateAppointment(appointment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to update appointment: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appointment": appointment,
+	})
+}
+
+// GetCalendarFeed serves a user's upcoming appointments as an iCalendar
+// subscription. The token in the URL identifies and authorizes the user.
+func (h *Handler) GetCalendarFeed(c *gin.Context) {
+	phone, ok := h.calendarToken(c, c.Param("token"))
+	if !ok {
+		return
+	}
+
+	appointments, err := h.store.GetAppointmentsByPhone(phone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get appointments: %v", err),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, calendar.ContentType, h.feeds.Feed(appointments, time.Now()))
+}
+
+// GetAppointmentInvite serves one appointment as an iCalendar invite, or as a
+// METHOD:CANCEL event once it has been cancelled
+func (h *Handler) GetAppointmentInvite(c *gin.Context) {
+	phone, ok := h.calendarToken(c, c.Param("token")+calendar.Extension)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSuffix(c.Param("appointment"), calendar.Extension)
+	appointment, err := h.store.GetAppointmentByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get appointment: %v", err),
+		})
+		return
+	}
+	if appointment == nil || appointment.UserPhone != phone {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Appointment not found",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%s.ics"`, appointment.ID))
+	c.Data(http.StatusOK, calendar.ContentType, h.feeds.Invite(appointment, time.Now()))
+}
+
+// calendarToken looks up a "<token>.ics" path segment and returns the phone
+// number it was issued for, writing the error response if it is invalid
+func (h *Handler) calendarToken(c *gin.Context, segment string) (string, bool) {
+	token, ok := strings.CutSuffix(segment, calendar.Extension)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Calendar not found",
+		})
+		return "", false
+	}
+
+	phone, err := h.feeds.Phone(token)
+	if errors.Is(err, calendar.ErrInvalidToken) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Invalid calendar link",
+		})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to check calendar link: %v", err),
+		})
+		return "", false
+	}
+	return phone, true
+}
+
 // GetAvailableSlots gets available appointment slots for a date
 func (h *Handler) GetAvailableSlots(c *gin.Context) {
 	date := c.Query("date")
@@ -206,7 +348,7 @@ func (h *Handler) GetAvailableSlots(c *gin.Context) {
 		return
 	}
 
-	parsedDate, err := time.Parse("2006-01-02", date)
+	parsedDate, err := h.slots.Schedule().ParseDate(date)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid date format. Use YYYY-MM-DD",
@@ -214,32 +356,268 @@ func (h *Handler) GetAvailableSlots(c *gin.Context) {
 		return
 	}
 
-	// Generate slots (9 AM to 5 PM)
+	duration := h.slots.DefaultDuration()
+	if d := c.Query("duration"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "duration must be a positive number of minutes",
+			})
+			return
+		}
+		duration = parsed
+	}
+
+	service, provider, providers, ok := h.slotQuery(c)
+	if !ok {
+		return
+	}
+
+	// A catalog service sets the duration and buffer
+	buffer := 0
+	if service != nil {
+		if !h.matchesService(c, service, duration) {
+			return
+		}
+		duration = service.Duration
+		buffer = service.BufferMinutes
+	}
+
+	// Without a provider, a time is available if anyone is free. Slots held
+	// by callers mid-booking are reported as held.
+	var timeSlots []models.TimeSlot
+	if provider != nil {
+		timeSlots, err = h.slots.Slots(parsedDate, duration, buffer, provider, availability.Holder{})
+	} else {
+		timeSlots, err = h.slots.AnySlots(parsedDate, duration, buffer, providers, availability.Holder{})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get slots: %v", err),
+		})
+		return
+	}
+
 	slots := []gin.H{}
-	loc := time.Local
+	for _, slot := range timeSlots {
+		entry := gin.H{
+			"date_time": slot.DateTime.Format(time.RFC3339),
+			"time":      h.slots.Schedule().FormatTime(slot.DateTime),
+			"available": slot.Available,
+			"held":      slot.Held,
+			"duration":  slot.Duration,
+		}
+		if slot.ProviderID != "" {
+			entry["provider_id"] = slot.ProviderID
+		}
+		slots = append(slots, entry)
+	}
 
-	for hour := 9; hour < 17; hour++ {
-		for _, minute := range []int{0, 30} {
-			slotTime := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), hour, minute, 0, 0, loc)
+	c.JSON(http.StatusOK, gin.H{
+		"date":     date,
+		"timezone": h.slots.Schedule().Location.String(),
+		"slots":    slots,
+	})
+}
 
-			if slotTime.Before(time.Now()) {
-				continue
+// GetNextAvailableSlots searches forward across days for the earliest free slots
+func (h *Handler) GetNextAvailableSlots(c *gin.Context) {
+	opts := availability.SearchOptions{
+		Duration: h.slots.DefaultDuration(),
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := h.slots.Schedule().ParseDate(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+		opts.From = parsed
+	}
+	if weekdays := c.Query("weekdays"); weekdays != "" {
+		days, err := availability.ParseWeekdays(weekdays)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid weekdays: %v", err),
+			})
+			return
+		}
+		opts.Weekdays = days
+	}
+	if window := c.Query("window"); window != "" {
+		windows, err := availability.ParseTimeRanges(window)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid window: %v", err),
+			})
+			return
+		}
+		opts.Windows = windows
+	}
+	for name, target := range map[string]*int{"duration": &opts.Duration, "count": &opts.Limit, "days": &opts.Days} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("%s must be a positive number", name),
+				})
+				return
 			}
+			*target = parsed
+		}
+	}
 
-			available, _ := database.DB.CheckSlotAvailability(slotTime, 30)
+	service, provider, providers, ok := h.slotQuery(c)
+	if !ok {
+		return
+	}
+	if service != nil {
+		if !h.matchesService(c, service, opts.Duration) {
+			return
+		}
+		opts.Duration = service.Duration
+		opts.Buffer = service.BufferMinutes
+	}
+	opts.Providers = providers
+	if provider != nil {
+		opts.Providers = []models.Provider{*provider}
+	}
 
-			slots = append(slots, gin.H{
-				"date_time":  slotTime.Format(time.RFC3339),
-				"time":       slotTime.Format("3:04 PM"),
-				"available":  available,
-				"duration":   30,
+	found, err := h.slots.FindNext(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to search slots: %v", err),
+		})
+		return
+	}
+
+	slots := []gin.H{}
+	for _, slot := range found {
+		entry := gin.H{
+			"date_time": slot.DateTime.Format(time.RFC3339),
+			"time":      h.slots.Schedule().FormatDateTime(slot.DateTime),
+			"duration":  slot.Duration,
+		}
+		if slot.ProviderID != "" {
+			entry["provider_id"] = slot.ProviderID
+		}
+		slots = append(slots, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"timezone": h.slots.Schedule().Location.String(),
+		"slots":    slots,
+		"count":    len(slots),
+	})
+}
+
+// matchesService rejects a duration query that differs from the service's
+// length, reporting whether the request can go on
+func (h *Handler) matchesService(c *gin.Context, service *models.Service, duration int) bool {
+	if c.Query("duration") == "" || duration == service.Duration {
+		return true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": fmt.Sprintf("%s takes %d minutes; omit duration or use %d", service.Name, service.Duration, service.Duration),
+	})
+	return false
+}
+
+// slotQuery resolves the service and provider query parameters of the slot
+// endpoints. Without a provider it returns the providers eligible for the
+// service. On failure it writes the error response and returns ok=false.
+func (h *Handler) slotQuery(c *gin.Context) (*models.Service, *models.Provider, []models.Provider, bool) {
+	var service *models.Service
+	if serviceID := c.Query("service"); serviceID != "" {
+		var err error
+		service, err = h.store.GetServiceByID(serviceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to get service: %v", err),
 			})
+			return nil, nil, nil, false
 		}
+		if service == nil || !service.Active {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Service not found",
+			})
+			return nil, nil, nil, false
+		}
+	}
+
+	if providerID := c.Query("provider"); providerID != "" {
+		provider, err := h.store.GetProviderByID(providerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to get provider: %v", err),
+			})
+			return nil, nil, nil, false
+		}
+		if provider == nil || (service != nil && !service.OfferedBy(provider.ID)) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Provider not found",
+			})
+			return nil, nil, nil, false
+		}
+		return service, provider, nil, true
+	}
+
+	providers, err := h.store.GetProviders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get providers: %v", err),
+		})
+		return nil, nil, nil, false
+	}
+	if service != nil {
+		var eligible []models.Provider
+		for _, p := range providers {
+			if service.OfferedBy(p.ID) {
+				eligible = append(eligible, p)
+			}
+		}
+		providers = eligible
+	}
+	return service, nil, providers, true
+}
+
+// GetProviders lists the active providers (staff and rooms)
+func (h *Handler) GetProviders(c *gin.Context) {
+	providers, err := h.store.GetProviders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get providers: %v", err),
+		})
+		return
+	}
+	if providers == nil {
+		providers = []models.Provider{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"providers": providers,
+		"count":     len(providers),
+	})
+}
+
+// GetServices lists the service catalog
+func (h *Handler) GetServices(c *gin.Context) {
+	services, err := h.store.GetServices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to get services: %v", err),
+		})
+		return
+	}
+	if services == nil {
+		services = []models.Service{}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"date":  date,
-		"slots": slots,
+		"services": services,
+		"count":    len(services),
 	})
 }
 
@@ -253,7 +631,7 @@ func (h *Handler) GetCallSummaries(c *gin.Context) {
 		return
 	}
 
-	summaries, err := database.DB.GetCallSummariesByPhone(phone)
+	summaries, err := h.store.GetCallSummariesByPhone(phone)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("Failed to get call summaries: %v", err),
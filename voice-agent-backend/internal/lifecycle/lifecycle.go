@@ -0,0 +1,78 @@
+// Package lifecycle owns appointment status changes. Every change goes
+// through Transition, which rejects moves the lifecycle does not allow and
+// records when each one happened.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// ErrInvalidTransition is returned for a status change the lifecycle does not allow
+var ErrInvalidTransition = errors.New("invalid status change")
+
+// transitions lists the statuses each status may move to. Completed, no-show
+// and cancelled appointments are final.
+var transitions = map[string][]string{
+	models.StatusBooked:      {models.StatusConfirmed, models.StatusRescheduled, models.StatusCheckedIn, models.StatusNoShow, models.StatusCancelled},
+	models.StatusConfirmed:   {models.StatusRescheduled, models.StatusCheckedIn, models.StatusNoShow, models.StatusCancelled},
+	models.StatusRescheduled: {models.StatusConfirmed, models.StatusRescheduled, models.StatusCheckedIn, models.StatusNoShow, models.StatusCancelled},
+	models.StatusCheckedIn:   {models.StatusCompleted},
+}
+
+// CanTransition reports whether an appointment in status from may move to status to
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the statuses an appointment in the given status may move to
+func Next(status string) []string {
+	return append([]string(nil), transitions[status]...)
+}
+
+// Transition moves the appointment to status to and stamps the matching
+// timestamp with now. The caller saves the appointment.
+func Transition(apt *models.Appointment, to string, now time.Time) error {
+	if !CanTransition(apt.Status, to) {
+		return fmt.Errorf("%w: a %s appointment cannot become %s", ErrInvalidTransition, Describe(apt.Status), Describe(to))
+	}
+
+	at := now
+	switch to {
+	case models.StatusConfirmed:
+		apt.ConfirmedAt = &at
+	case models.StatusRescheduled:
+		apt.RescheduledAt = &at
+	case models.StatusCheckedIn:
+		apt.CheckedInAt = &at
+	case models.StatusCompleted:
+		apt.CompletedAt = &at
+	case models.StatusNoShow:
+		apt.NoShowAt = &at
+	case models.StatusCancelled:
+		apt.CancelledAt = &at
+	}
+	apt.Status = to
+	return nil
+}
+
+// Describe spells a status the way it is read out, e.g. "checked in"
+func Describe(status string) string {
+	switch status {
+	case models.StatusCheckedIn:
+		return "checked in"
+	case models.StatusNoShow:
+		return "no-show"
+	case "":
+		return "new"
+	}
+	return status
+}
@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestTransition(t *testing.T) {
+	now := time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC)
+	stamps := func(apt *models.Appointment) map[string]*time.Time {
+		return map[string]*time.Time{
+			models.StatusConfirmed:   apt.ConfirmedAt,
+			models.StatusRescheduled: apt.RescheduledAt,
+			models.StatusCheckedIn:   apt.CheckedInAt,
+			models.StatusCompleted:   apt.CompletedAt,
+			models.StatusNoShow:      apt.NoShowAt,
+			models.StatusCancelled:   apt.CancelledAt,
+		}
+	}
+
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{models.StatusBooked, models.StatusConfirmed, true},
+		{models.StatusBooked, models.StatusCheckedIn, true},
+		{models.StatusBooked, models.StatusCompleted, false},
+		{models.StatusConfirmed, models.StatusConfirmed, false},
+		{models.StatusRescheduled, models.StatusRescheduled, true},
+		{models.StatusRescheduled, models.StatusNoShow, true},
+		{models.StatusCheckedIn, models.StatusCompleted, true},
+		{models.StatusCheckedIn, models.StatusCancelled, false},
+		{models.StatusCompleted, models.StatusCancelled, false},
+		{models.StatusNoShow, models.StatusCheckedIn, false},
+		{models.StatusCancelled, models.StatusBooked, false},
+		{"", models.StatusBooked, false},
+	}
+	for _, tt := range tests {
+		apt := &models.Appointment{Status: tt.from}
+		err := Transition(apt, tt.to, now)
+
+		if tt.ok != (err == nil) || tt.ok != CanTransition(tt.from, tt.to) {
+			t.Errorf("%s -> %s: got %v, want allowed %v", tt.from, tt.to, err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			if !errors.Is(err, ErrInvalidTransition) || apt.Status != tt.from {
+				t.Errorf("%s -> %s: expected ErrInvalidTransition and no change, got %v and %s", tt.from, tt.to, err, apt.Status)
+			}
+			continue
+		}
+		if apt.Status != tt.to {
+			t.Errorf("%s -> %s: status is %s", tt.from, tt.to, apt.Status)
+		}
+		for status, stamp := range stamps(apt) {
+			if (status == tt.to) != (stamp != nil) || (stamp != nil && !stamp.Equal(now)) {
+				t.Errorf("%s -> %s: %s timestamp is %v", tt.from, tt.to, status, stamp)
+			}
+		}
+	}
+}
+
+func TestNextIsACopy(t *testing.T) {
+	next := Next(models.StatusCheckedIn)
+	if len(next) != 1 || next[0] != models.StatusCompleted {
+		t.Fatalf("expected a checked-in appointment to only complete, got %v", next)
+	}
+	next[0] = models.StatusCancelled
+	if CanTransition(models.StatusCheckedIn, models.StatusCancelled) {
+		t.Fatal("changing the result of Next changed the lifecycle")
+	}
+	if final := Next(models.StatusCompleted); len(final) != 0 {
+		t.Fatalf("expected completed to be final, got %v", final)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]string{
+		models.StatusCheckedIn: "checked in",
+		models.StatusNoShow:    "no-show",
+		"":                     "new",
+		models.StatusBooked:    "booked",
+	}
+	for status, want := range tests {
+		if got := Describe(status); got != want {
+			t.Errorf("Describe(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
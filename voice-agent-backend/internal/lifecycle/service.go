@@ -0,0 +1,108 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// DefaultNoShowGrace is how long after its start an appointment without a
+// check-in is marked a no-show
+const DefaultNoShowGrace = 15 * time.Minute
+
+// sweepLookback bounds how far back each sweep looks for appointments that
+// were never closed, e.g. while the server was down
+const sweepLookback = 7 * 24 * time.Hour
+
+// Service closes appointments that have passed: ones never checked in become
+// no-shows once the grace period is over, and checked-in ones are completed
+// when they end.
+type Service struct {
+	store  database.Store
+	grace  time.Duration
+	ticker *time.Ticker
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewService creates the lifecycle service and starts its sweep loop
+func NewService(cfg *config.Config, store database.Store) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	grace := time.Duration(cfg.NoShowGraceMinutes) * time.Minute
+	if grace <= 0 {
+		grace = DefaultNoShowGrace
+	}
+
+	s := &Service{
+		store:  store,
+		grace:  grace,
+		ticker: time.NewTicker(1 * time.Minute),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	go s.sweepLoop()
+
+	return s
+}
+
+// Stop stops the sweep loop
+func (s *Service) Stop() {
+	s.ticker.Stop()
+	s.cancel()
+}
+
+// Sweep marks no-shows and completes checked-in appointments as of now
+func (s *Service) Sweep(now time.Time) error {
+	appointments, err := s.store.GetUpcomingAppointmentsInWindow(now.Add(-sweepLookback), now)
+	if err != nil {
+		return fmt.Errorf("failed to get started appointments: %w", err)
+	}
+
+	for i := range appointments {
+		apt := &appointments[i]
+
+		var to string
+		switch {
+		case apt.Status == models.StatusCheckedIn:
+			if apt.DateTime.Add(time.Duration(apt.Duration) * time.Minute).After(now) {
+				continue
+			}
+			to = models.StatusCompleted
+		case !apt.DateTime.Add(s.grace).After(now):
+			to = models.StatusNoShow
+		default:
+			continue
+		}
+
+		if err := Transition(apt, to, now); err != nil {
+			log.Printf("[lifecycle] Skipping appointment %s: %v", apt.ID, err)
+			continue
+		}
+		if err := s.store.UpdateAppointment(apt); err != nil {
+			log.Printf("[lifecycle] Failed to mark appointment %s %s: %v", apt.ID, to, err)
+			continue
+		}
+		log.Printf("[lifecycle] Appointment %s marked %s", apt.ID, to)
+	}
+	return nil
+}
+
+func (s *Service) sweepLoop() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.ticker.C:
+			if err := s.Sweep(time.Now()); err != nil {
+				log.Printf("[lifecycle] %v", err)
+			}
+		}
+	}
+}
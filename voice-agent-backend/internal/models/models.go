@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -10,36 +11,197 @@ type User struct {
 	PhoneNumber  string    `json:"phone_number"`
 	Name         string    `json:"name,omitempty"`
 	Email        string    `json:"email,omitempty"`
+	Timezone     string    `json:"timezone,omitempty"` // IANA name, e.g. "Europe/London"
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // Appointment represents a booked appointment
 type Appointment struct {
+	ID              string     `json:"id"`
+	UserPhone       string     `json:"user_phone"`
+	UserName        string     `json:"user_name,omitempty"`
+	ProviderID      string     `json:"provider_id,omitempty"` // empty when the business has no providers
+	ServiceID       string     `json:"service_id,omitempty"`
+	SeriesID        string     `json:"series_id,omitempty"` // set on occurrences of a recurring series
+	DateTime        time.Time  `json:"date_time"`
+	Duration        int        `json:"duration"`                 // in minutes
+	BufferMinutes   int        `json:"buffer_minutes,omitempty"` // kept free after the appointment
+	PriceCents      int64      `json:"price_cents,omitempty"`
+	Purpose         string     `json:"purpose,omitempty"`
+	Status          string     `json:"status"` // see the AppointmentStatus constants
+	Notes           string     `json:"notes,omitempty"`
+	RescheduleCount int        `json:"reschedule_count,omitempty"`
+	FeeCents        int64      `json:"fee_cents,omitempty"`      // late cancellation fee charged
+	FeePaymentID    string     `json:"fee_payment_id,omitempty"` // payment opened for the fee
+	PaymentID       string     `json:"payment_id,omitempty"`     // Stripe charge or payment intent for the price
+	PaymentStatus   string     `json:"payment_status,omitempty"` // Stripe's status, e.g. "succeeded"
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	RescheduledAt   *time.Time `json:"rescheduled_at,omitempty"` // last reschedule
+	CheckedInAt     *time.Time `json:"checked_in_at,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	NoShowAt        *time.Time `json:"no_show_at,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AppointmentSeries is a recurring booking. Each occurrence is stored as an
+// Appointment carrying the series ID.
+type AppointmentSeries struct {
+	ID        string    `json:"id"`
+	UserPhone string    `json:"user_phone"`
+	RRule     string    `json:"rrule"`    // iCalendar RRULE, e.g. "FREQ=WEEKLY;BYDAY=TU;COUNT=6"
+	DTStart   time.Time `json:"dtstart"`  // first occurrence
+	Timezone  string    `json:"timezone"` // IANA zone the rule is expanded in
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BlockEnd returns when the appointment stops occupying its calendar, including buffer time
+func (a *Appointment) BlockEnd() time.Time {
+	return a.DateTime.Add(time.Duration(a.Duration+a.BufferMinutes) * time.Minute)
+}
+
+// AppointmentStatus constants. An appointment is booked, may be confirmed
+// and rescheduled, and ends checked in then completed, a no-show or
+// cancelled.
+const (
+	StatusBooked      = "booked"
+	StatusConfirmed   = "confirmed"
+	StatusRescheduled = "rescheduled"
+	StatusCheckedIn   = "checked_in"
+	StatusCompleted   = "completed"
+	StatusNoShow      = "no_show"
+	StatusCancelled   = "cancelled"
+)
+
+// ActiveStatuses are the statuses of appointments that still occupy their slot
+var ActiveStatuses = []string{StatusBooked, StatusConfirmed, StatusRescheduled, StatusCheckedIn}
+
+// IsActive reports whether the appointment still occupies its slot
+func (a *Appointment) IsActive() bool {
+	for _, status := range ActiveStatuses {
+		if a.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Provider is a bookable resource with its own calendar, such as a staff member or a room
+type Provider struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`            // staff, room
+	Hours     string    `json:"hours,omitempty"` // weekly hours, e.g. "mon-fri 09:00-17:00"; empty uses business hours
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Service is an appointment type offered by the business
+type Service struct {
 	ID            string    `json:"id"`
-	UserPhone     string    `json:"user_phone"`
-	UserName      string    `json:"user_name,omitempty"`
-	DateTime      time.Time `json:"date_time"`
-	Duration      int       `json:"duration"` // in minutes
-	Purpose       string    `json:"purpose,omitempty"`
-	Status        string    `json:"status"` // booked, cancelled, completed
-	Notes         string    `json:"notes,omitempty"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	Duration      int       `json:"duration"`       // default length in minutes
+	BufferMinutes int       `json:"buffer_minutes"` // kept free after each appointment
+	PriceCents    int64     `json:"price_cents"`
+	ProviderIDs   []string  `json:"provider_ids,omitempty"` // eligible providers; empty means any
+	Active        bool      `json:"active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
-// AppointmentStatus constants
+// OfferedBy reports whether the provider may perform the service
+func (s *Service) OfferedBy(providerID string) bool {
+	if len(s.ProviderIDs) == 0 {
+		return true
+	}
+	for _, id := range s.ProviderIDs {
+		if id == providerID {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderKind constants
 const (
-	StatusBooked    = "booked"
-	StatusCancelled = "cancelled"
-	StatusCompleted = "completed"
+	ProviderKindStaff = "staff"
+	ProviderKindRoom  = "room"
+)
+
+// SlotHold reserves time on a provider's calendar without booking it, such
+// as a slot a caller is deciding on or a freed slot offered to a waitlisted
+// caller. Other callers cannot book over an unexpired hold.
+type SlotHold struct {
+	ID              string    `json:"id"`
+	ProviderID      string    `json:"provider_id,omitempty"`
+	DateTime        time.Time `json:"date_time"`
+	EndTime         time.Time `json:"end_time"`
+	SessionID       string    `json:"session_id,omitempty"`
+	HolderPhone     string    `json:"holder_phone,omitempty"`
+	WaitlistEntryID string    `json:"waitlist_entry_id,omitempty"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// OwnedBy reports whether the hold belongs to the given session or caller
+func (h *SlotHold) OwnedBy(sessionID, phone string) bool {
+	return (h.SessionID != "" && h.SessionID == sessionID) || (h.HolderPhone != "" && h.HolderPhone == phone)
+}
+
+// BusyBlock is busy time pulled from a provider's external calendar. Slots
+// overlapping it are unavailable, like booked appointments.
+type BusyBlock struct {
+	ID          string    `json:"id"`
+	ProviderID  string    `json:"provider_id,omitempty"`
+	DateTime    time.Time `json:"date_time"`
+	EndTime     time.Time `json:"end_time"`
+	ExternalUID string    `json:"external_uid,omitempty"` // UID of the source calendar event
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WaitlistEntry is a caller waiting for a slot to open up
+type WaitlistEntry struct {
+	ID              string     `json:"id"`
+	UserPhone       string     `json:"user_phone"`
+	UserName        string     `json:"user_name"`
+	ProviderID      string     `json:"provider_id,omitempty"`
+	ServiceID       string     `json:"service_id,omitempty"`
+	Duration        int        `json:"duration"`
+	BufferMinutes   int        `json:"buffer_minutes,omitempty"`
+	FromDate        string     `json:"from_date"`                 // YYYY-MM-DD in the business timezone
+	ToDate          string     `json:"to_date"`                   // inclusive
+	PreferredTimes  string     `json:"preferred_times,omitempty"` // e.g. "09:00-12:00,14:00-17:00"
+	Status          string     `json:"status"`
+	OfferDateTime   *time.Time `json:"offer_date_time,omitempty"`
+	OfferProviderID string     `json:"offer_provider_id,omitempty"`
+	OfferExpiresAt  *time.Time `json:"offer_expires_at,omitempty"`
+	AppointmentID   string     `json:"appointment_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// WaitlistStatus constants
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistBooked    = "booked"
+	WaitlistExpired   = "expired"
+	WaitlistCancelled = "cancelled"
 )
 
 // TimeSlot represents an available time slot
 type TimeSlot struct {
-	DateTime  time.Time `json:"date_time"`
-	Available bool      `json:"available"`
-	Duration  int       `json:"duration"`
+	DateTime   time.Time `json:"date_time"`
+	Available  bool      `json:"available"`
+	Held       bool      `json:"held,omitempty"` // free but held for another caller
+	Duration   int       `json:"duration"`
+	ProviderID string    `json:"provider_id,omitempty"`
 }
 
 // CallSession represents an active voice call session
@@ -57,9 +219,19 @@ type CallSession struct {
 
 // ConversationMsg represents a message in the conversation
 type ConversationMsg struct {
-	Role      string    `json:"role"` // user, assistant, system
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
+	Role       string                 `json:"role"` // user, assistant, system, tool
+	Content    string                 `json:"content"`
+	ToolCalls  []ConversationToolCall `json:"tool_calls,omitempty"`   // assistant: the tools it called
+	ToolCallID string                 `json:"tool_call_id,omitempty"` // tool: the call this is the result of
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// ConversationToolCall is a tool call kept in the conversation history so
+// later turns still see what was looked up, e.g. appointment IDs
+type ConversationToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // ToolCallRecord represents a tool call made during the conversation
@@ -106,6 +278,7 @@ type WSMessage struct {
 const (
 	WSTypeTranscript     = "transcript"
 	WSTypeAgentResponse  = "agent_response"
+	WSTypeResponseDelta  = "agent_response_delta"
 	WSTypeToolCall       = "tool_call"
 	WSTypeToolResult     = "tool_result"
 	WSTypeCallSummary    = "call_summary"
@@ -115,20 +288,29 @@ const (
 	WSTypeCostUpdate     = "cost_update"
 )
 
+// ResponseDeltaPayload for WebSocket: one spoken chunk of a reply while it
+// is being generated. The whole reply follows as agent_response.
+type ResponseDeltaPayload struct {
+	ResponseID string `json:"response_id"`
+	Delta      string `json:"delta"`
+}
+
 // ToolCallPayload for WebSocket
 type ToolCallPayload struct {
 	ID        string                 `json:"id"`
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
-	Status    string                 `json:"status"` // pending, executing, completed, failed
+	Status    string                 `json:"status"`            // pending, confirmed, declined, expired, executing, completed, failed
+	Summary   string                 `json:"summary,omitempty"` // what a pending action will do, for the caller to agree to
 }
 
 // ToolResultPayload for WebSocket
 type ToolResultPayload struct {
-	ID     string      `json:"id"`
-	Name   string      `json:"name"`
-	Result interface{} `json:"result"`
-	Error  string      `json:"error,omitempty"`
+	ID     string                 `json:"id"`
+	Name   string                 `json:"name"`
+	Result interface{}            `json:"result"`
+	Client map[string]interface{} `json:"client,omitempty"` // for the client only, never sent to the LLM
+	Error  string                 `json:"error,omitempty"`
 }
 
 // LLM Tool definitions
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// Booking guard codes
+const (
+	GuardMaxUpcoming = "max_upcoming" // the caller has too many upcoming appointments
+	GuardOverlap     = "overlap"      // the caller already has an appointment at that time
+	GuardDuplicate   = "duplicate"    // the caller has a near-identical appointment close by
+)
+
+// Guard is a booking limit an appointment ran into. Warnings may be booked
+// anyway once the caller confirms; other guards refuse the booking.
+type Guard struct {
+	Code     string              `json:"code"`
+	Message  string              `json:"message"`
+	Warning  bool                `json:"warning,omitempty"`
+	Existing *models.Appointment `json:"-"` // the appointment it clashes with, if any
+}
+
+// CheckLimit reports whether booking adding more appointments would take
+// the caller over the maximum number of upcoming appointments
+func (e *Engine) CheckLimit(upcoming []models.Appointment, adding int) *Guard {
+	if e.policy.MaxUpcoming <= 0 || len(upcoming)+adding <= e.policy.MaxUpcoming {
+		return nil
+	}
+	return &Guard{
+		Code:    GuardMaxUpcoming,
+		Message: fmt.Sprintf("You already have %d upcoming appointment(s) and we allow at most %d per caller", len(upcoming), e.policy.MaxUpcoming),
+	}
+}
+
+// CheckConflicts compares an appointment with the caller's other upcoming
+// appointments: one at the same time refuses it, and a near-identical one
+// within the duplicate window is a warning. The appointment itself is
+// skipped, so a rescheduled appointment can be checked too.
+func (e *Engine) CheckConflicts(apt *models.Appointment, upcoming []models.Appointment) *Guard {
+	end := apt.DateTime.Add(time.Duration(apt.Duration) * time.Minute)
+
+	if e.policy.PreventOverlap {
+		for i := range upcoming {
+			other := &upcoming[i]
+			if other.ID == apt.ID {
+				continue
+			}
+			otherEnd := other.DateTime.Add(time.Duration(other.Duration) * time.Minute)
+			if other.DateTime.Before(end) && otherEnd.After(apt.DateTime) {
+				return &Guard{
+					Code:     GuardOverlap,
+					Message:  "You already have an appointment at that time",
+					Existing: other,
+				}
+			}
+		}
+	}
+
+	if e.policy.DuplicateWindow > 0 {
+		for i := range upcoming {
+			other := &upcoming[i]
+			if other.ID == apt.ID || !sameKind(apt, other) {
+				continue
+			}
+			gap := other.DateTime.Sub(apt.DateTime)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap < e.policy.DuplicateWindow {
+				return &Guard{
+					Code:     GuardDuplicate,
+					Message:  fmt.Sprintf("You already have a %s appointment within %s of that time", other.Purpose, formatDays(e.policy.DuplicateWindow)),
+					Warning:  true,
+					Existing: other,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameKind reports whether two appointments are for the same service or,
+// without one, the same purpose
+func sameKind(a, b *models.Appointment) bool {
+	if a.ServiceID != "" || b.ServiceID != "" {
+		return a.ServiceID == b.ServiceID
+	}
+	purpose := strings.ToLower(strings.TrimSpace(a.Purpose))
+	return purpose != "" && purpose == strings.ToLower(strings.TrimSpace(b.Purpose))
+}
+
+// formatDays spells a whole number of days, e.g. "7 days"
+func formatDays(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
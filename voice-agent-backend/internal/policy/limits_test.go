@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestCheckLimitAndConflicts(t *testing.T) {
+	at := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	upcoming := []models.Appointment{
+		{ID: "cut", DateTime: at, Duration: 60, Purpose: "Haircut"},
+		{ID: "color", DateTime: at.Add(72 * time.Hour), Duration: 60, ServiceID: "svc-color"},
+	}
+	engine := NewEngine(&Policy{MaxUpcoming: 3, PreventOverlap: true, DuplicateWindow: 7 * 24 * time.Hour}, nil)
+
+	if guard := engine.CheckLimit(upcoming, 1); guard != nil {
+		t.Fatalf("expected a third appointment allowed, got %+v", guard)
+	}
+	if guard := engine.CheckLimit(upcoming, 2); guard == nil || guard.Code != GuardMaxUpcoming {
+		t.Fatalf("expected a fourth appointment refused, got %+v", guard)
+	}
+
+	tests := []struct {
+		name     string
+		apt      models.Appointment
+		code     string
+		existing string
+	}{
+		{"overlaps the end", models.Appointment{DateTime: at.Add(30 * time.Minute), Duration: 30, Purpose: "Massage"}, GuardOverlap, "cut"},
+		{"ends as the other starts", models.Appointment{DateTime: at.Add(-30 * time.Minute), Duration: 30, Purpose: "Massage"}, "", ""},
+		{"same purpose, different letter case", models.Appointment{DateTime: at.Add(48 * time.Hour), Duration: 30, Purpose: " haircut "}, GuardDuplicate, "cut"},
+		{"same service", models.Appointment{DateTime: at.Add(24 * time.Hour), Duration: 30, ServiceID: "svc-color"}, GuardDuplicate, "color"},
+		{"service against a purpose", models.Appointment{DateTime: at.Add(48 * time.Hour), Duration: 30, ServiceID: "svc-cut", Purpose: "Haircut"}, "", ""},
+		{"outside the window", models.Appointment{DateTime: at.Add(8 * 24 * time.Hour), Duration: 30, Purpose: "Haircut"}, "", ""},
+		{"itself when rescheduled", models.Appointment{ID: "cut", DateTime: at.Add(time.Hour), Duration: 60, Purpose: "Haircut"}, "", ""},
+	}
+	for _, tt := range tests {
+		guard := engine.CheckConflicts(&tt.apt, upcoming)
+		switch {
+		case tt.code == "" && guard != nil:
+			t.Errorf("%s: expected no guard, got %+v", tt.name, guard)
+		case tt.code != "" && (guard == nil || guard.Code != tt.code || guard.Existing.ID != tt.existing):
+			t.Errorf("%s: expected %s against %s, got %+v", tt.name, tt.code, tt.existing, guard)
+		case guard != nil && guard.Warning != (tt.code == GuardDuplicate):
+			t.Errorf("%s: only duplicates should be warnings, got %+v", tt.name, guard)
+		}
+	}
+}
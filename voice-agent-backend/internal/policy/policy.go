@@ -0,0 +1,148 @@
+// Package policy decides whether a caller may book, cancel or reschedule an
+// appointment, and what it costs, under the business's booking limits and
+// cancellation policy.
+package policy
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// Policy is the business's booking limits and cancellation policy. Zero
+// values switch the matching rule off.
+type Policy struct {
+	MaxUpcoming     int           // upcoming appointments one caller may have
+	PreventOverlap  bool          // refuse appointments overlapping the caller's own
+	DuplicateWindow time.Duration // warn about a near-identical appointment this close
+
+	CancelNotice       time.Duration // cancelling with less notice is refused
+	LateCancelWindow   time.Duration // cancelling with less notice than this is charged LateCancelFeeCents
+	LateCancelFeeCents int64
+	RescheduleNotice   time.Duration // rescheduling with less notice is refused
+	MaxReschedules     int           // times one appointment may be rescheduled
+}
+
+// Decision is the policy's answer for one change
+type Decision struct {
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason,omitempty"`    // why it was refused, or what the fee is for
+	FeeCents int64  `json:"fee_cents,omitempty"` // charged if the caller goes ahead
+}
+
+// Fee returns the fee formatted for the caller, e.g. "$20.00"
+func (d Decision) Fee() string {
+	return payment.FormatAmount(d.FeeCents)
+}
+
+// Engine applies the policy and collects fees
+type Engine struct {
+	policy   *Policy
+	payments *payment.PaymentService // nil records fees without collecting them
+}
+
+// FromConfig builds the policy from the booking limit and cancellation settings
+func FromConfig(cfg *config.Config) (*Policy, error) {
+	if cfg.MaxUpcomingAppointments < 0 || cfg.DuplicateWindowDays < 0 || cfg.CancelNoticeMinutes < 0 || cfg.LateCancelHours < 0 || cfg.LateCancelFeeCents < 0 ||
+		cfg.RescheduleNoticeMinutes < 0 || cfg.MaxReschedules < 0 {
+		return nil, fmt.Errorf("booking limit and cancellation policy settings must not be negative")
+	}
+	return &Policy{
+		MaxUpcoming:     cfg.MaxUpcomingAppointments,
+		PreventOverlap:  !cfg.AllowOverlappingBookings,
+		DuplicateWindow: time.Duration(cfg.DuplicateWindowDays) * 24 * time.Hour,
+
+		CancelNotice:       time.Duration(cfg.CancelNoticeMinutes) * time.Minute,
+		LateCancelWindow:   time.Duration(cfg.LateCancelHours) * time.Hour,
+		LateCancelFeeCents: cfg.LateCancelFeeCents,
+		RescheduleNotice:   time.Duration(cfg.RescheduleNoticeMinutes) * time.Minute,
+		MaxReschedules:     cfg.MaxReschedules,
+	}, nil
+}
+
+// NewEngine creates a policy engine. Fees are collected through payments
+// when it is non-nil.
+func NewEngine(policy *Policy, payments *payment.PaymentService) *Engine {
+	return &Engine{policy: policy, payments: payments}
+}
+
+// Cancel decides whether the appointment may be cancelled at now
+func (e *Engine) Cancel(apt *models.Appointment, now time.Time) Decision {
+	notice := apt.DateTime.Sub(now)
+	if notice <= 0 {
+		return Decision{Reason: "The appointment has already started"}
+	}
+	if notice < e.policy.CancelNotice {
+		return Decision{
+			Reason: fmt.Sprintf("Appointments can't be cancelled with less than %s notice", formatNotice(e.policy.CancelNotice)),
+		}
+	}
+	if e.policy.LateCancelFeeCents > 0 && notice < e.policy.LateCancelWindow {
+		return Decision{
+			Allowed:  true,
+			Reason:   fmt.Sprintf("Cancelling within %s of the appointment incurs a %s fee", formatNotice(e.policy.LateCancelWindow), payment.FormatAmount(e.policy.LateCancelFeeCents)),
+			FeeCents: e.policy.LateCancelFeeCents,
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// Reschedule decides whether the appointment may be moved to another time at now
+func (e *Engine) Reschedule(apt *models.Appointment, now time.Time) Decision {
+	notice := apt.DateTime.Sub(now)
+	if notice <= 0 {
+		return Decision{Reason: "The appointment has already started"}
+	}
+	if notice < e.policy.RescheduleNotice {
+		return Decision{
+			Reason: fmt.Sprintf("Appointments can't be rescheduled with less than %s notice", formatNotice(e.policy.RescheduleNotice)),
+		}
+	}
+	if e.policy.MaxReschedules > 0 && apt.RescheduleCount >= e.policy.MaxReschedules {
+		return Decision{
+			Reason: fmt.Sprintf("This appointment has already been rescheduled the maximum of %d time(s)", e.policy.MaxReschedules),
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// ChargeFee records the decision's fee on the appointment and, with a payment
+// service, opens a payment intent for the caller to pay it with, which it
+// returns. A failed payment leaves the fee recorded for staff to collect and
+// returns nil. The caller saves the appointment.
+func (e *Engine) ChargeFee(apt *models.Appointment, userName string, d Decision) *payment.PaymentIntent {
+	if d.FeeCents <= 0 {
+		return nil
+	}
+	apt.FeeCents = d.FeeCents
+	if e.payments == nil || !e.payments.CollectsOnScreen() {
+		return nil
+	}
+
+	intent, err := e.payments.CreatePaymentIntent(apt.UserPhone, userName, apt.ID, d.FeeCents, d.Reason)
+	if err != nil {
+		log.Printf("[policy] Failed to open payment for the fee on appointment %s: %v", apt.ID, err)
+		return nil
+	}
+	apt.FeePaymentID = intent.ID
+	return intent
+}
+
+// formatNotice spells a notice period, e.g. "24 hours" or "90 minutes"
+func formatNotice(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d == time.Minute:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestCancelAndReschedule(t *testing.T) {
+	engine := NewEngine(&Policy{
+		CancelNotice:       time.Hour,
+		LateCancelWindow:   24 * time.Hour,
+		LateCancelFeeCents: 2500,
+		RescheduleNotice:   2 * time.Hour,
+		MaxReschedules:     2,
+	}, nil)
+	now := time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		notice       time.Duration
+		rescheduled  int
+		cancel       bool
+		fee          int64
+		reschedule   bool
+		cancelReason string
+	}{
+		{"plenty of notice", 48 * time.Hour, 0, true, 0, true, ""},
+		{"inside the late window", 3 * time.Hour, 0, true, 2500, true, "Cancelling within 24 hours of the appointment incurs a $25.00 fee"},
+		{"under the reschedule notice", 90 * time.Minute, 0, true, 2500, false, "Cancelling within 24 hours of the appointment incurs a $25.00 fee"},
+		{"under the cancel notice", 30 * time.Minute, 0, false, 0, false, "Appointments can't be cancelled with less than 1 hour notice"},
+		{"already started", -time.Minute, 0, false, 0, false, "The appointment has already started"},
+		{"rescheduled too often", 48 * time.Hour, 2, true, 0, false, ""},
+	}
+	for _, tt := range tests {
+		apt := &models.Appointment{DateTime: now.Add(tt.notice), RescheduleCount: tt.rescheduled}
+
+		cancel := engine.Cancel(apt, now)
+		if cancel.Allowed != tt.cancel || cancel.FeeCents != tt.fee || cancel.Reason != tt.cancelReason {
+			t.Errorf("%s: cancel got %+v", tt.name, cancel)
+		}
+		if reschedule := engine.Reschedule(apt, now); reschedule.Allowed != tt.reschedule || (!reschedule.Allowed && reschedule.Reason == "") {
+			t.Errorf("%s: reschedule got %+v", tt.name, reschedule)
+		}
+	}
+}
+
+func TestChargeFeeRecordsTheFeeWithoutPayments(t *testing.T) {
+	engine := NewEngine(&Policy{}, nil)
+	apt := &models.Appointment{ID: "apt-1"}
+
+	if intent := engine.ChargeFee(apt, "Caller", Decision{Allowed: true}); intent != nil || apt.FeeCents != 0 {
+		t.Fatalf("expected no fee, got %v and %d", intent, apt.FeeCents)
+	}
+	if intent := engine.ChargeFee(apt, "Caller", Decision{Allowed: true, FeeCents: 1500}); intent != nil || apt.FeeCents != 1500 || apt.FeePaymentID != "" {
+		t.Fatalf("expected the fee recorded for staff, got %v and %+v", intent, apt)
+	}
+}
+
+func TestFormatNotice(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:      "1 minute",
+		90 * time.Minute: "90 minutes",
+		time.Hour:        "1 hour",
+		48 * time.Hour:   "48 hours",
+	}
+	for d, want := range tests {
+		if got := formatNotice(d); got != want {
+			t.Errorf("formatNotice(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
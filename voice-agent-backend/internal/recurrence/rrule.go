@@ -0,0 +1,252 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences caps how many appointments a single series may create
+const MaxOccurrences = 52
+
+// Frequency is the RRULE FREQ part
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Rule is the subset of an iCalendar (RFC 5545) RRULE the scheduler supports:
+// FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, BYDAY (weekly only) and an end
+// given by COUNT or UNTIL.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+var dayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var dayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=TU;COUNT=6".
+// A leading "RRULE:" is accepted. Naive UNTIL values are read in loc.
+func Parse(value string, loc *time.Location) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "RRULE:"), "rrule:")
+	if value == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%q: expected NAME=VALUE", part)
+		}
+		name, val := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch name {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly:
+				rule.Freq = Frequency(val)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q (use DAILY, WEEKLY or MONTHLY)", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, ok := dayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			// Weeks always start on Monday
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return nil, fmt.Errorf("the series needs an end: add COUNT or UNTIL")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot both be set")
+	}
+	if rule.Count > MaxOccurrences {
+		return nil, fmt.Errorf("a series can have at most %d occurrences", MaxOccurrences)
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayOffset(rule.ByDay[i]) < mondayOffset(rule.ByDay[j])
+	})
+	return rule, nil
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		// A date-only UNTIL includes the whole day
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q (use YYYYMMDD or YYYYMMDDTHHMMSSZ)", value)
+}
+
+// String formats the rule as an RRULE value
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = dayNames[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences expands the rule from start. Each occurrence keeps start's
+// wall-clock time in loc, so a 10:00 series stays at 10:00 across DST
+// changes. At most MaxOccurrences times are returned.
+func (r *Rule) Occurrences(start time.Time, loc *time.Location) []time.Time {
+	local := start.In(loc)
+	hour, min, sec := local.Clock()
+	at := func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, min, sec, 0, loc)
+	}
+
+	var occurrences []time.Time
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		occurrences = append(occurrences, t)
+		if r.Count > 0 && len(occurrences) >= r.Count {
+			return false
+		}
+		return len(occurrences) < MaxOccurrences
+	}
+
+	// Guard against rules that never produce a valid date
+	const maxPeriods = 1000
+
+	switch r.Freq {
+	case Daily:
+		for i := 0; i < maxPeriods; i++ {
+			if !emit(at(local.AddDate(0, 0, i*r.Interval))) {
+				break
+			}
+		}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{local.Weekday()}
+		}
+		weekStart := local.AddDate(0, 0, -mondayOffset(local.Weekday()))
+	weeks:
+		for i := 0; i < maxPeriods; i++ {
+			week := weekStart.AddDate(0, 0, 7*i*r.Interval)
+			for _, day := range days {
+				if !emit(at(week.AddDate(0, 0, mondayOffset(day)))) {
+					break weeks
+				}
+			}
+		}
+	case Monthly:
+		for i := 0; i < maxPeriods; i++ {
+			// Months without this day (e.g. the 31st) are skipped, per RFC 5545
+			first := time.Date(local.Year(), local.Month()+time.Month(i*r.Interval), 1, 0, 0, 0, 0, loc)
+			date := first.AddDate(0, 0, local.Day()-1)
+			if date.Month() != first.Month() {
+				continue
+			}
+			if !emit(at(date)) {
+				break
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// ShiftDays moves BYDAY weekdays by n days, for when a series is rescheduled
+// to a different day of the week
+func (r *Rule) ShiftDays(n int) {
+	for i, day := range r.ByDay {
+		r.ByDay[i] = time.Weekday(((int(day)+n)%7 + 7) % 7)
+	}
+	sort.Slice(r.ByDay, func(i, j int) bool {
+		return mondayOffset(r.ByDay[i]) < mondayOffset(r.ByDay[j])
+	})
+}
+
+// EndBefore changes the rule to stop before t, for splitting a series
+func (r *Rule) EndBefore(t time.Time) {
+	r.Count = 0
+	r.Until = t.Add(-time.Second).UTC()
+}
+
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
@@ -0,0 +1,116 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsUnsupportedRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"no frequency", "COUNT=3"},
+		{"no end", "FREQ=WEEKLY;BYDAY=MO"},
+		{"both COUNT and UNTIL", "FREQ=DAILY;COUNT=3;UNTIL=20260401"},
+		{"COUNT over the cap", "FREQ=WEEKLY;COUNT=53"},
+		{"yearly", "FREQ=YEARLY;COUNT=2"},
+		{"BYDAY with a daily rule", "FREQ=DAILY;BYDAY=MO;COUNT=3"},
+		{"positional BYDAY", "FREQ=WEEKLY;BYDAY=1MO;COUNT=3"},
+		{"unsupported part", "FREQ=MONTHLY;BYMONTHDAY=2;COUNT=3"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0;COUNT=3"},
+		{"bad UNTIL", "FREQ=DAILY;UNTIL=next-week"},
+	}
+	for _, tt := range tests {
+		if rule, err := Parse(tt.value, time.UTC); err == nil {
+			t.Errorf("%s: expected %q to be rejected, got %v", tt.name, tt.value, rule)
+		}
+	}
+}
+
+func TestParseAndStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=TH,TU;COUNT=6", "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=6"},
+		{"freq=daily;interval=2;count=5", "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{"FREQ=WEEKLY;BYDAY=SU,MO;WKST=SU;UNTIL=20260401T000000Z", "FREQ=WEEKLY;BYDAY=MO,SU;UNTIL=20260401T000000Z"},
+		{"FREQ=MONTHLY;UNTIL=20260401", "FREQ=MONTHLY;UNTIL=20260401T235959Z"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.value, time.UTC)
+		if err != nil {
+			t.Errorf("%q: %v", tt.value, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Tuesday March 3, 2026, 10:00 in New York; DST starts on March 8
+	start := time.Date(2026, 3, 3, 10, 0, 0, 0, newYork)
+	date := func(month time.Month, day int) string {
+		return time.Date(2026, month, day, 0, 0, 0, 0, newYork).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		count int
+		first []string // leading dates expected, in order
+	}{
+		{"COUNT", "FREQ=DAILY;COUNT=3", start, 3, []string{date(3, 3), date(3, 4), date(3, 5)}},
+		{"BYDAY", "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4", start, 4, []string{date(3, 3), date(3, 5), date(3, 10), date(3, 12)}},
+		{"BYDAY before the start is skipped", "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", start, 3, []string{date(3, 4), date(3, 9), date(3, 11)}},
+		{"INTERVAL", "FREQ=WEEKLY;INTERVAL=2;COUNT=3", start, 3, []string{date(3, 3), date(3, 17), date(3, 31)}},
+		{"date-only UNTIL includes its day", "FREQ=WEEKLY;UNTIL=20260317", start, 3, []string{date(3, 3), date(3, 10), date(3, 17)}},
+		{"UNTIL before the time of day", "FREQ=DAILY;UNTIL=20260305T120000Z", start, 2, []string{date(3, 3), date(3, 4)}},
+		{"UNTIL past the cap", "FREQ=DAILY;UNTIL=20270101", start, MaxOccurrences, []string{date(3, 3)}},
+		{"COUNT at the cap", "FREQ=WEEKLY;COUNT=52", start, MaxOccurrences, []string{date(3, 3), date(3, 10)}},
+		{"monthly skips months without the day", "FREQ=MONTHLY;COUNT=3", time.Date(2026, 1, 31, 10, 0, 0, 0, newYork), 3, []string{date(1, 31), date(3, 31), date(5, 31)}},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule, newYork)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		occurrences := rule.Occurrences(tt.start, newYork)
+		if len(occurrences) != tt.count {
+			t.Errorf("%s: got %d occurrences, want %d", tt.name, len(occurrences), tt.count)
+			continue
+		}
+		for i, want := range tt.first {
+			if got := occurrences[i].Format("2006-01-02"); got != want {
+				t.Errorf("%s: occurrence %d is %s, want %s", tt.name, i, got, want)
+			}
+		}
+		for _, occurrence := range occurrences {
+			if local := occurrence.In(newYork); local.Hour() != 10 || local.Minute() != 0 {
+				t.Errorf("%s: expected 10:00 local across DST, got %s", tt.name, local)
+			}
+		}
+	}
+}
+
+func TestShiftDaysAndEndBefore(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=FR,SA;COUNT=10", time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	rule.ShiftDays(2)
+	cut := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	rule.EndBefore(cut)
+	if got, want := rule.String(), "FREQ=WEEKLY;BYDAY=MO,SU;UNTIL=20260310T085959Z"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
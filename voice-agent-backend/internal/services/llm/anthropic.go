@@ -0,0 +1,266 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	anthropicAPIURL  = "https://api.anthropic.com"
+	anthropicVersion = "2023-06-01"
+	// The Messages API requires max_tokens
+	anthropicMaxTokens = 1024
+)
+
+// AnthropicProvider talks to the Anthropic Messages API
+type AnthropicProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+}
+
+// NewAnthropicProvider creates a provider; an empty baseURL uses Anthropic's
+func NewAnthropicProvider(apiKey, baseURL, model string) *AnthropicProvider {
+	return &AnthropicProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(withDefault(baseURL, anthropicAPIURL), "/"),
+		model:   model,
+	}
+}
+
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicBlock `json:"content"`
+	Usage   anthropicUsage   `json:"usage"`
+}
+
+// anthropicEvent is one server-sent event of a streamed reply
+type anthropicEvent struct {
+	Type         string            `json:"type"`
+	Index        int               `json:"index"`
+	Message      anthropicResponse `json:"message"`       // message_start
+	ContentBlock anthropicBlock    `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`         // text_delta
+		PartialJSON string `json:"partial_json"` // input_json_delta
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"` // message_delta
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Chat sends a conversation to the Messages API. The system prompt travels
+// separately, tool calls are tool_use blocks and their results are
+// tool_result blocks in a user turn.
+func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var resp anthropicResponse
+	if err := postJSON(ctx, p.baseURL+"/v1/messages", p.headers(), p.request(req), &resp); err != nil {
+		return nil, fmt.Errorf("anthropic error: %w", err)
+	}
+	return anthropicResult(resp), nil
+}
+
+// ChatStream streams a reply as server-sent events. Text and tool input
+// arrive as deltas of numbered content blocks.
+func (p *AnthropicProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(text string)) (*ChatResponse, error) {
+	body := p.request(req)
+	body.Stream = true
+
+	stream, err := post(ctx, p.baseURL+"/v1/messages", p.headers(), body)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic error: %w", err)
+	}
+	defer stream.Close()
+
+	var resp anthropicResponse
+	var inputs []string
+	err = scanLines(stream, func(line string) error {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return nil
+		}
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return fmt.Errorf("invalid event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			resp.Usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_start":
+			for len(resp.Content) <= event.Index {
+				resp.Content = append(resp.Content, anthropicBlock{})
+				inputs = append(inputs, "")
+			}
+			resp.Content[event.Index] = event.ContentBlock
+		case "content_block_delta":
+			if event.Index >= len(resp.Content) {
+				return nil
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				resp.Content[event.Index].Text += event.Delta.Text
+				onDelta(event.Delta.Text)
+			case "input_json_delta":
+				inputs[event.Index] += event.Delta.PartialJSON
+			}
+		case "message_delta":
+			resp.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("%s", event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anthropic stream error: %w", err)
+	}
+
+	for i, input := range inputs {
+		if input != "" {
+			resp.Content[i].Input = json.RawMessage(input)
+		}
+	}
+	return anthropicResult(resp), nil
+}
+
+func (p *AnthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+// request converts a ChatRequest to the Messages API format
+func (p *AnthropicProvider) request(req ChatRequest) anthropicRequest {
+	body := anthropicRequest{
+		Model:       p.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = anthropicMaxTokens
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case RoleSystem:
+			system = append(system, msg.Content)
+		case RoleTool:
+			body.Messages = appendAnthropic(body.Messages, RoleUser, anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		case RoleAssistant:
+			var blocks []anthropicBlock
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				blocks = append(blocks, anthropicBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Name,
+					Input: arguments(tc.Arguments),
+				})
+			}
+			body.Messages = appendAnthropic(body.Messages, RoleAssistant, blocks...)
+		default:
+			body.Messages = appendAnthropic(body.Messages, RoleUser, anthropicBlock{Type: "text", Text: msg.Content})
+		}
+	}
+	body.System = strings.Join(system, "\n\n")
+
+	for _, spec := range req.Tools {
+		schema := spec.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object"}
+		}
+		body.Tools = append(body.Tools, anthropicTool{
+			Name:        spec.Name,
+			Description: spec.Description,
+			InputSchema: schema,
+		})
+	}
+
+	return body
+}
+
+// anthropicResult converts a reply's content blocks
+func anthropicResult(resp anthropicResponse) *ChatResponse {
+	result := &ChatResponse{TokensUsed: resp.Usage.InputTokens + resp.Usage.OutputTokens}
+	var text []string
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			result.ToolCalls = append(result.ToolCalls, ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: arguments(block.Input),
+			})
+		}
+	}
+	result.Content = strings.Join(text, "")
+	return result
+}
+
+// Complete answers a single prompt
+func (p *AnthropicProvider) Complete(ctx context.Context, req CompletionRequest) (*ChatResponse, error) {
+	return p.Chat(ctx, req.chat())
+}
+
+// appendAnthropic adds blocks to the conversation, merging them into the
+// last message when it has the same role: the API wants turns to alternate,
+// and all results of one round of tool calls go back in a single user turn.
+func appendAnthropic(messages []anthropicMessage, role string, blocks ...anthropicBlock) []anthropicMessage {
+	if len(blocks) == 0 {
+		return messages
+	}
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// clauseMinChars is how long a chunk must be before it may end at a comma,
+// semicolon or colon rather than at the end of a sentence. Shorter clauses
+// are spoken together with what follows.
+const clauseMinChars = 40
+
+// abbreviations end with a period that does not end a sentence
+var abbreviations = map[string]bool{
+	"dr": true, "mr": true, "mrs": true, "ms": true, "st": true, "jr": true, "sr": true,
+	"vs": true, "e.g": true, "i.e": true, "a.m": true, "p.m": true,
+}
+
+// chunker splits streamed text into pieces that can be spoken on their own:
+// sentences, or clauses once they are long enough. Tool call announcements
+// the model writes out are dropped from each piece.
+type chunker struct {
+	buf    strings.Builder
+	say    func(chunk string)
+	spoken []string
+}
+
+func newChunker(say func(chunk string)) *chunker {
+	return &chunker{say: say}
+}
+
+// write adds streamed text and passes on every chunk it completes
+func (c *chunker) write(delta string) {
+	c.buf.WriteString(delta)
+	for {
+		text := c.buf.String()
+		cut := chunkEnd(text)
+		if cut < 0 {
+			return
+		}
+		c.buf.Reset()
+		c.buf.WriteString(text[cut:])
+		c.emit(text[:cut])
+	}
+}
+
+// flush passes on whatever text is left, e.g. when the model stops
+func (c *chunker) flush() {
+	text := c.buf.String()
+	c.buf.Reset()
+	c.emit(text)
+}
+
+// text returns everything passed on so far
+func (c *chunker) text() string {
+	return strings.Join(c.spoken, " ")
+}
+
+func (c *chunker) emit(text string) {
+	chunk := filterToolCallAnnouncements(text)
+	if chunk == "" {
+		return
+	}
+	c.spoken = append(c.spoken, chunk)
+	c.say(chunk)
+}
+
+// chunkEnd returns where the first complete chunk of text ends, or -1. A
+// boundary needs the whitespace after it, so "2.30" and "1,000" are not
+// split, and is never inside [brackets] so announcements stay whole.
+func chunkEnd(text string) int {
+	depth := 0
+	for i, r := range text {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		}
+		if depth > 0 {
+			continue
+		}
+
+		next := i + len(string(r))
+		if r == '\n' {
+			return next
+		}
+		if next >= len(text) || !unicode.IsSpace(rune(text[next])) {
+			continue
+		}
+
+		switch r {
+		case '.':
+			if !abbreviations[lastWord(text[:i])] {
+				return next
+			}
+		case '!', '?':
+			return next
+		case ',', ';', ':':
+			if next >= clauseMinChars {
+				return next
+			}
+		}
+	}
+	return -1
+}
+
+// lastWord returns the lower-cased word at the end of text
+func lastWord(text string) string {
+	start := strings.LastIndexFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '('
+	})
+	return strings.ToLower(text[start+1:])
+}
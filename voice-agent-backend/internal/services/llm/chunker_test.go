@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkerSplitsSpeakablePieces(t *testing.T) {
+	tests := []struct {
+		name   string
+		deltas []string
+		want   []string
+	}{
+		{"sentences", []string{"Sure. ", "You're booked! Anything else?"}, []string{"Sure.", "You're booked!", "Anything else?"}},
+		{"sentence split across deltas", []string{"I have", " you down for ", "Tuesday.", " Bye"}, []string{"I have you down for Tuesday.", "Bye"}},
+		{"abbreviations", []string{"Dr. Lee sees you at 3 p.m. sharp. Ok"}, []string{"Dr. Lee sees you at 3 p.m. sharp.", "Ok"}},
+		{"numbers", []string{"That costs $1,000.50 in total. Ok"}, []string{"That costs $1,000.50 in total.", "Ok"}},
+		{"short clause waits", []string{"Yes, that works. "}, []string{"Yes, that works."}},
+		{"long clause", []string{"Your appointment with the hygienist is on Monday, and it lasts an hour"}, []string{"Your appointment with the hygienist is on Monday,", "and it lasts an hour"}},
+		{"newline", []string{"First line\nsecond line"}, []string{"First line", "second line"}},
+		{"announcement dropped", []string{"[Calling the book_appointment tool. Please wait.] ", "Done. "}, []string{"Done."}},
+		{"announcement only", []string{"[Calling check_availability]"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		c := newChunker(func(chunk string) { got = append(got, chunk) })
+		for _, delta := range tt.deltas {
+			c.write(delta)
+		}
+		c.flush()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if want := strings.Join(tt.want, " "); c.text() != want {
+			t.Errorf("%s: text %q, want %q", tt.name, c.text(), want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/tools"
+)
+
+const (
+	// keepRecentTurns is how many of the caller's latest turns, with the
+	// replies and tool calls that followed, are never summarized
+	keepRecentTurns = 2
+	// memoryMaxTokens bounds the summary of older turns
+	memoryMaxTokens = 300
+	// messageOverheadTokens is what each message costs besides its text
+	messageOverheadTokens = 4
+)
+
+// charsPerToken approximates each model family's tokenizer. The first
+// entry contained in the model name wins, so longer names come first.
+var charsPerToken = []struct {
+	model string
+	chars float64
+}{
+	{"gpt-4o", 4.2},
+	{"gpt-4", 4.0},
+	{"gpt-3.5", 4.0},
+	{"claude", 3.5},
+	{"llama", 3.8},
+	{"mistral", 3.6},
+	{"qwen", 3.6},
+}
+
+const defaultCharsPerToken = 4.0
+
+// TokenEstimator estimates how many tokens text takes for a model without
+// running its tokenizer. Estimates err on the high side.
+type TokenEstimator struct {
+	charsPerToken float64
+}
+
+// NewTokenEstimator returns the estimator for a model name
+func NewTokenEstimator(model string) TokenEstimator {
+	model = strings.ToLower(model)
+	for _, family := range charsPerToken {
+		if strings.Contains(model, family.model) {
+			return TokenEstimator{charsPerToken: family.chars}
+		}
+	}
+	return TokenEstimator{charsPerToken: defaultCharsPerToken}
+}
+
+// Text estimates the tokens in text
+func (t TokenEstimator) Text(text string) int {
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / t.charsPerToken))
+}
+
+// Messages estimates the tokens in messages, tool calls included
+func (t TokenEstimator) Messages(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += messageOverheadTokens + t.Text(msg.Content)
+		for _, tc := range msg.ToolCalls {
+			total += t.Text(tc.Name) + t.Text(string(tc.Arguments))
+		}
+	}
+	return total
+}
+
+// ContextManager decides what part of a call's history is sent with each
+// request. The system prompt always goes first. Once the history would take
+// the context over budget, the oldest turns are summarized into a memory
+// message, and from then on the facts that must not be lost (who the caller
+// is, actions waiting for their confirmation) are pinned next to it.
+type ContextManager struct {
+	provider      Provider
+	estimator     TokenEstimator
+	budget        int // estimated tokens per request; 0 for no limit
+	maxToolResult int // longest tool result replayed from history; 0 for no limit
+
+	memory     string // summary of history[:summarized]
+	summarized int
+}
+
+// NewContextManager creates a manager for one call, summarizing with
+// provider
+func NewContextManager(provider Provider, model string, budget, maxToolResult int) *ContextManager {
+	return &ContextManager{
+		provider:      provider,
+		estimator:     NewTokenEstimator(model),
+		budget:        budget,
+		maxToolResult: maxToolResult,
+	}
+}
+
+// Build returns the messages to send for history, summarizing older turns
+// first if they no longer fit, and the tokens spent doing so
+func (m *ContextManager) Build(ctx context.Context, system string, history []models.ConversationMsg, executor *tools.ToolExecutor) ([]Message, int) {
+	// A different, shorter history means a new conversation
+	if m.summarized > len(history) {
+		m.memory, m.summarized = "", 0
+	}
+
+	tokensUsed := 0
+	if cut := m.overflow(system, history, executor); cut > m.summarized {
+		memory, tokens, err := m.summarize(ctx, history[m.summarized:cut])
+		tokensUsed = tokens
+		if err != nil {
+			// Better over budget than missing part of the call
+			log.Printf("[Build] Failed to summarize %d messages, sending them whole: %v", cut-m.summarized, err)
+		} else {
+			log.Printf("[Build] Summarized %d messages into memory", cut-m.summarized)
+			m.memory, m.summarized = memory, cut
+		}
+	}
+
+	return append(m.pinned(system, executor), convertMessages(history[m.summarized:], m.maxToolResult)...), tokensUsed
+}
+
+// pinned returns the messages sent ahead of the history
+func (m *ContextManager) pinned(system string, executor *tools.ToolExecutor) []Message {
+	messages := []Message{{Role: RoleSystem, Content: system}}
+	if m.memory == "" {
+		return messages
+	}
+
+	var memory strings.Builder
+	memory.WriteString("Summary of the earlier part of this call:\n")
+	memory.WriteString(m.memory)
+	if facts := pinnedFacts(executor); len(facts) > 0 {
+		memory.WriteString("\n\nStill true now:\n- ")
+		memory.WriteString(strings.Join(facts, "\n- "))
+	}
+	return append(messages, Message{Role: RoleSystem, Content: memory.String()})
+}
+
+// pinnedFacts lists what the rest of the call depends on, straight from the
+// executor so it is never lost to summarization
+func pinnedFacts(executor *tools.ToolExecutor) []string {
+	if executor == nil {
+		return nil
+	}
+	var facts []string
+	if phone := executor.GetUserPhone(); phone != "" {
+		facts = append(facts, fmt.Sprintf("The caller is identified: %s, phone %s.", withDefault(executor.GetUserName(), "name unknown"), phone))
+	}
+	for _, action := range executor.PendingActions() {
+		facts = append(facts, fmt.Sprintf("Waiting for the caller to confirm: %s (confirmation_token %s).", action.Summary, action.ID))
+	}
+	return facts
+}
+
+// overflow returns where the history should be cut so the rest fits the
+// budget, or m.summarized when nothing needs summarizing. Cuts fall just
+// before a caller's turn, so tool calls stay with their results, and never
+// reach the last keepRecentTurns turns.
+func (m *ContextManager) overflow(system string, history []models.ConversationMsg, executor *tools.ToolExecutor) int {
+	if m.budget <= 0 {
+		return m.summarized
+	}
+
+	recent := history[m.summarized:]
+	costs := make([]int, len(recent))
+	remaining := 0
+	for i := range recent {
+		costs[i] = m.estimator.Messages(convertMessages(recent[i:i+1], m.maxToolResult))
+		remaining += costs[i]
+	}
+	fixed := m.estimator.Messages(m.pinned(system, executor))
+	if fixed+remaining <= m.budget {
+		return m.summarized
+	}
+
+	var turns []int
+	for i, msg := range recent {
+		if msg.Role == "user" && i > 0 {
+			turns = append(turns, i)
+		}
+	}
+	if len(turns) < keepRecentTurns {
+		return m.summarized
+	}
+	turns = turns[:len(turns)-keepRecentTurns+1]
+
+	// The memory is rewritten with what is cut, so allow for its full size
+	fixed = m.estimator.Messages([]Message{{Role: RoleSystem, Content: system}}) + memoryMaxTokens + messageOverheadTokens
+	cut := 0
+	for _, turn := range turns {
+		for ; cut < turn; cut++ {
+			remaining -= costs[cut]
+		}
+		if fixed+remaining <= m.budget {
+			break
+		}
+	}
+	return m.summarized + cut
+}
+
+// summarize folds messages into the memory, returning the new memory
+func (m *ContextManager) summarize(ctx context.Context, messages []models.ConversationMsg) (string, int, error) {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case "tool":
+			fmt.Fprintf(&transcript, "Result: %s\n", truncate(msg.Content, 1000))
+		case "assistant":
+			if msg.Content != "" {
+				fmt.Fprintf(&transcript, "Agent: %s\n", msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&transcript, "Agent called %s %s\n", tc.Name, arguments(tc.Arguments))
+			}
+		case "user":
+			fmt.Fprintf(&transcript, "User: %s\n", msg.Content)
+		}
+	}
+
+	resp, err := m.provider.Complete(ctx, CompletionRequest{
+		System: `You keep the memory of a phone call between a caller and an AI appointment assistant. The assistant will only see your memory, not the conversation it replaces.
+
+Rewrite the memory so far to include the new part of the conversation. Keep everything the rest of the call may depend on: who the caller is, appointment IDs with their dates, times and services, what was booked, cancelled, changed or paid, what is still undecided, and the caller's stated preferences. Drop small talk. Write short plain sentences, at most 150 words, with no preamble.`,
+		Prompt:      fmt.Sprintf("Memory so far:\n%s\n\nNew part of the conversation:\n%s", withDefault(m.memory, "(none)"), transcript.String()),
+		Temperature: 0.2,
+		MaxTokens:   memoryMaxTokens,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	memory := strings.TrimSpace(resp.Content)
+	if memory == "" {
+		return "", resp.TokensUsed, fmt.Errorf("empty summary")
+	}
+	return memory, resp.TokensUsed, nil
+}
@@ -7,8 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/sashabaranov/go-openai"
 	"github.com/voice-agent/backend/internal/config"
 	"github.com/voice-agent/backend/internal/models"
 	"github.com/voice-agent/backend/internal/tools"
@@ -39,23 +39,28 @@ func filterToolCallAnnouncements(content string) string {
 	return result
 }
 
-// getSystemPrompt returns the system prompt with current date
-func getSystemPrompt() string {
-	currentDate := time.Now().Format("January 2, 2006")
-	currentYear := time.Now().Year()
+// getSystemPrompt returns the system prompt with current date in the business timezone
+func getSystemPrompt(loc *time.Location) string {
+	now := time.Now().In(loc)
+	currentDate := now.Format("Monday, January 2, 2006 at 3:04 PM MST")
 
 	return fmt.Sprintf(`You are a friendly and professional AI voice assistant for an appointment scheduling service. Your name is "Ava".
 
-IMPORTANT: Today's date is %s. The current year is %d. When users say "tomorrow", "next week", etc., calculate dates relative to TODAY.
+IMPORTANT: Today's date is %s. Do not work out dates yourself: when users say "tomorrow", "next Tuesday afternoon", "the 3rd" etc., call resolve_datetime with their words and read back the weekday and date it returns. fetch_slots, find_next_available, hold_slot, book_appointment and modify_appointment also accept the caller's words directly.
+All appointment times are in the business timezone (%s). Pass date_time values in that local time without an offset. If the user mentions a different timezone, convert their time to business time before calling tools, and pass their IANA timezone to identify_user.
 
 Your capabilities:
 1. Help users identify themselves intelligently (ask phone first, then name/email only if they're new)
-2. Check available appointment time slots
-3. Book new appointments
-4. Retrieve existing appointments
-5. Cancel appointments
-6. Modify appointment details
-7. End conversations politely
+2. Tell users which services we offer (list_services) - book a service_id from that list rather than guessing durations
+3. Check available appointment time slots - use find_next_available when the caller wants the soonest time or is flexible on the date
+4. Hold the slot the caller picks (hold_slot) while you confirm the details, then book it. Book new appointments, including recurring series ("every Tuesday for six weeks" → recurrence FREQ=WEEKLY;BYDAY=TU;COUNT=6) - read back any conflicts the tool reports. If their time is taken, offer the waitlist (join_waitlist). If a booking is refused with a guard, relay its message; for a duplicate, ask whether they really want another appointment and only book again with confirm_duplicate if they say yes
+5. Retrieve existing appointments, and confirm the caller will attend one when they say so (confirm_appointment)
+6. Cancel appointments - for recurring ones, ask whether they mean just this one, this and following, or the whole series. If the result says fee_required, nothing was cancelled: tell the caller about the fee, and only if they accept it call cancel_appointment again with accept_fee, which you confirm with them like any other change. When a cancellation or reschedule is refused, explain the policy reason
+7. Modify appointment details - with the same scope question for recurring ones
+8. Take payment for an appointment when the caller wants to pay now (process_payment) - never promise a payment went through unless the tool says so; if it says a payment form has been sent to their screen, ask them to finish it there
+9. End conversations politely
+
+Confirming changes: book_appointment, cancel_appointment and modify_appointment do not act straight away. They return a summary and a confirmation_token. Read the summary to the caller, wait for their answer, and only call confirm_action with the token once they have said yes. Never call confirm_action in the same turn you asked. Ask about one change at a time; a yes only confirms the change you asked about last. If they say no, ask what they would like instead.
 
 CRITICAL - Smart User Identification:
 The identify_user tool is intelligent. It checks the database automatically:
@@ -101,46 +106,54 @@ Guidelines:
 - When ending a call, summarize any actions taken
 - Use natural language for dates and times (e.g., "tomorrow at 2 PM" instead of ISO format)
 - If user seems confused, offer to help guide them
-- When using fetch_slots tool, always use dates in YYYY-MM-DD format
+- If resolve_datetime says a date and weekday do not match, ask the caller which one they meant
 
 Important:
 - You MUST use tools to perform actions - don't just say you'll do something, actually call the tool
 - After identifying a user, greet them by name
 - Double-check details before making bookings
 - Be proactive in offering help but don't be pushy
-- ALWAYS use the current year %d for any dates
 - For identify_user: pass phone_number always, name and email only when available
-- Listen to the tool's error messages - they guide you on what's needed`, currentDate, currentYear, currentYear)
+- Listen to the tool's error messages - they guide you on what's needed
+- Never read URLs aloud (ics_url, calendar_feed_url); say the calendar link has been sent with their confirmation`, currentDate, loc.String())
 }
 
 // Service handles LLM interactions
 type Service struct {
-	client     *openai.Client
-	model      string
+	provider   Provider
 	tokenCount int
-	toolDefs   []openai.Tool
+	toolDefs   []ToolSpec
+	location   *time.Location
+	history    *ContextManager
 }
 
-// NewService creates a new LLM service
-func NewService(cfg *config.Config) *Service {
-	clientConfig := openai.DefaultConfig(cfg.LLMAPIKey)
-	if cfg.LLMBaseURL != "" && cfg.LLMBaseURL != "https://api.openai.com/v1" {
-		clientConfig.BaseURL = cfg.LLMBaseURL
+// NewService creates a new LLM service using the configured provider
+func NewService(cfg *config.Config) (*Service, error) {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Service{
-		client:   openai.NewClientWithConfig(clientConfig),
-		model:    cfg.LLMModel,
-		toolDefs: tools.GetToolDefinitions(),
+	// An invalid timezone is rejected at startup when the schedule is built
+	location, err := cfg.BusinessLocation()
+	if err != nil {
+		location = time.Local
 	}
+
+	service := NewServiceWithProvider(provider, location)
+	service.history = NewContextManager(provider, ModelName(cfg), cfg.LLMContextTokens, cfg.LLMToolResultMaxChars)
+	return service, nil
 }
 
-// Message represents a conversation message
-type Message struct {
-	Role       string            `json:"role"`
-	Content    string            `json:"content"`
-	ToolCalls  []openai.ToolCall `json:"tool_calls,omitempty"`
-	ToolCallID string            `json:"tool_call_id,omitempty"`
+// NewServiceWithProvider creates a service that talks to provider, with
+// dates in the system prompt given in location. It sends the whole history.
+func NewServiceWithProvider(provider Provider, location *time.Location) *Service {
+	return &Service{
+		provider: provider,
+		toolDefs: toolSpecs(tools.GetToolDefinitions()),
+		location: location,
+		history:  NewContextManager(provider, "", 0, 0),
+	}
 }
 
 // Response represents an LLM response
@@ -149,67 +162,97 @@ type Response struct {
 	ToolCalls  []ToolCall
 	TokensUsed int
 	ShouldEnd  bool
+	// Messages is the turn as it belongs in the history: each round of tool
+	// calls with their results, then the reply
+	Messages []models.ConversationMsg
 }
 
 // ToolCall represents a tool call from the LLM
 type ToolCall struct {
-	ID        string
-	Name      string
-	Arguments json.RawMessage
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
 }
 
 // Chat sends a message and gets a response with tool support
 func (s *Service) Chat(ctx context.Context, messages []models.ConversationMsg, toolExecutor *tools.ToolExecutor) (*Response, error) {
-	// Convert to OpenAI messages
-	openAIMessages := s.convertMessages(messages)
+	return s.converse(ctx, messages, toolExecutor, nil)
+}
 
-	// Add system prompt at the beginning (with current date)
-	openAIMessages = append([]openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: getSystemPrompt(),
-		},
-	}, openAIMessages...)
+// ChatStream is Chat for a live call: the reply is streamed and passed to
+// onChunk a sentence or long clause at a time, ready to be spoken, while the
+// model is still writing. Text the model writes before calling tools ("let
+// me check") is passed on too, and the returned Content is everything that
+// was passed on.
+func (s *Service) ChatStream(ctx context.Context, messages []models.ConversationMsg, toolExecutor *tools.ToolExecutor, onChunk func(chunk string)) (*Response, error) {
+	return s.converse(ctx, messages, toolExecutor, newChunker(onChunk))
+}
+
+// send makes one model request, streaming its text into chunks when given
+func (s *Service) send(ctx context.Context, req ChatRequest, chunks *chunker) (*ChatResponse, error) {
+	if chunks == nil {
+		return s.provider.Chat(ctx, req)
+	}
+	if streamer, ok := s.provider.(Streamer); ok {
+		return streamer.ChatStream(ctx, req, chunks.write)
+	}
+	resp, err := s.provider.Chat(ctx, req)
+	if err == nil {
+		chunks.write(resp.Content)
+	}
+	return resp, err
+}
+
+// converse runs the model until it answers without calling tools
+func (s *Service) converse(ctx context.Context, messages []models.ConversationMsg, toolExecutor *tools.ToolExecutor, chunks *chunker) (*Response, error) {
+	// System prompt (with current date) first, then as much of the history
+	// as fits the context budget
+	chatMessages, tokens := s.history.Build(ctx, getSystemPrompt(s.location), messages, toolExecutor)
+	s.tokenCount += tokens
+	var turn []models.ConversationMsg
 
 	for {
 		// Make the API call
-		resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:       s.model,
-			Messages:    openAIMessages,
+		resp, err := s.send(ctx, ChatRequest{
+			Messages:    chatMessages,
 			Tools:       s.toolDefs,
 			Temperature: 0.7,
 			MaxTokens:   500,
-		})
+		}, chunks)
 		if err != nil {
-			return nil, fmt.Errorf("chat completion failed: %w", err)
+			return nil, err
 		}
 
-		if len(resp.Choices) == 0 {
-			return nil, fmt.Errorf("no choices in response")
+		s.tokenCount += resp.TokensUsed
+		if chunks != nil {
+			chunks.flush()
 		}
 
-		choice := resp.Choices[0]
-		s.tokenCount += resp.Usage.TotalTokens
-
 		// Check if there are tool calls
-		if len(choice.Message.ToolCalls) > 0 {
+		if len(resp.ToolCalls) > 0 {
 			// Add assistant message with tool calls
-			openAIMessages = append(openAIMessages, choice.Message)
+			chatMessages = append(chatMessages, Message{
+				Role:      RoleAssistant,
+				Content:   resp.Content,
+				ToolCalls: resp.ToolCalls,
+			})
+			turn = append(turn, historyMessage(chatMessages[len(chatMessages)-1]))
 
 			// Execute each tool call
 			shouldEnd := false
-			for _, tc := range choice.Message.ToolCalls {
-				result, err := toolExecutor.ExecuteTool(tc.Function.Name, json.RawMessage(tc.Function.Arguments))
+			for _, tc := range resp.ToolCalls {
+				result, err := toolExecutor.ExecuteTool(tc.Name, tc.Arguments)
 
 				var resultStr string
 				if err != nil {
-					resultStr = fmt.Sprintf(`{"error": "%s"}`, err.Error())
+					resultBytes, _ := json.Marshal(map[string]interface{}{"success": false, "error": err.Error()})
+					resultStr = string(resultBytes)
 				} else {
 					resultBytes, _ := json.Marshal(result)
 					resultStr = string(resultBytes)
 
 					// Check if this is an end conversation call
-					if tc.Function.Name == tools.ToolEndConversation {
+					if tc.Name == tools.ToolEndConversation {
 						if resultMap, ok := result.(map[string]interface{}); ok {
 							if end, ok := resultMap["should_end"].(bool); ok && end {
 								shouldEnd = true
@@ -219,40 +262,39 @@ func (s *Service) Chat(ctx context.Context, messages []models.ConversationMsg, t
 				}
 
 				// Add tool result message
-				openAIMessages = append(openAIMessages, openai.ChatCompletionMessage{
-					Role:       openai.ChatMessageRoleTool,
+				chatMessages = append(chatMessages, Message{
+					Role:       RoleTool,
 					Content:    resultStr,
 					ToolCallID: tc.ID,
 				})
+				turn = append(turn, historyMessage(chatMessages[len(chatMessages)-1]))
 			}
 
 			// If should end, return immediately with appropriate message
 			if shouldEnd {
-				// Get final response
-				finalResp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-					Model:       s.model,
-					Messages:    openAIMessages,
+				// Get final response. The history holds tool calls, which
+				// providers only accept alongside the tool definitions; any
+				// further calls are ignored as the call is ending.
+				finalResp, err := s.send(ctx, ChatRequest{
+					Messages:    chatMessages,
+					Tools:       s.toolDefs,
 					Temperature: 0.7,
 					MaxTokens:   200,
-				})
+				}, chunks)
 				if err != nil {
-					return &Response{
-						Content:    "Thank you for calling. Goodbye!",
-						ShouldEnd:  true,
-						TokensUsed: s.tokenCount,
-					}, nil
+					finalResp = &ChatResponse{Content: "Thank you for calling. Goodbye!"}
+					if chunks != nil {
+						chunks.write(finalResp.Content)
+					}
 				}
 
-				s.tokenCount += finalResp.Usage.TotalTokens
-				content := ""
-				if len(finalResp.Choices) > 0 {
-					content = filterToolCallAnnouncements(finalResp.Choices[0].Message.Content)
-				}
+				s.tokenCount += finalResp.TokensUsed
 
 				return &Response{
-					Content:    content,
+					Content:    replyText(finalResp, chunks),
 					ShouldEnd:  true,
 					TokensUsed: s.tokenCount,
+					Messages:   append(turn, reply(finalResp)),
 				}, nil
 			}
 
@@ -262,13 +304,34 @@ func (s *Service) Chat(ctx context.Context, messages []models.ConversationMsg, t
 
 		// No tool calls, return the content (filtered)
 		return &Response{
-			Content:    filterToolCallAnnouncements(choice.Message.Content),
+			Content:    replyText(resp, chunks),
 			TokensUsed: s.tokenCount,
 			ShouldEnd:  false,
+			Messages:   append(turn, reply(resp)),
 		}, nil
 	}
 }
 
+// replyText is the reply as the caller gets it: when streaming, every chunk
+// already passed on
+func replyText(resp *ChatResponse, chunks *chunker) string {
+	if chunks == nil {
+		return filterToolCallAnnouncements(resp.Content)
+	}
+	chunks.flush()
+	return chunks.text()
+}
+
+// reply is the history message for the model's final answer of a turn.
+// Text spoken before tool calls is kept with those calls, not repeated here.
+func reply(resp *ChatResponse) models.ConversationMsg {
+	return models.ConversationMsg{
+		Role:      "assistant",
+		Content:   filterToolCallAnnouncements(resp.Content),
+		Timestamp: time.Now(),
+	}
+}
+
 // GenerateSummary creates a call summary
 func (s *Service) GenerateSummary(ctx context.Context, messages []models.ConversationMsg, appointments []models.Appointment) (*models.CallSummary, error) {
 	summaryPrompt := `You are analyzing a call between a user and an AI appointment assistant. Generate a comprehensive call summary.
@@ -288,6 +351,10 @@ Guidelines:
 	// Build conversation text
 	convText := "Conversation History:\n"
 	for _, msg := range messages {
+		// Tool traffic is not part of what was said; appointments follow below
+		if msg.Role == "tool" || msg.Content == "" {
+			continue
+		}
 		role := msg.Role
 		if role == "assistant" {
 			role = "Agent"
@@ -302,7 +369,7 @@ Guidelines:
 		convText += "\n\nCurrent User Appointments:\n"
 		for _, apt := range appointments {
 			convText += fmt.Sprintf("- %s: %s (%d min) - Status: %s\n",
-				apt.DateTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+				apt.DateTime.In(s.location).Format("Monday, January 2, 2006 at 3:04 PM MST"),
 				apt.Purpose,
 				apt.Duration,
 				apt.Status,
@@ -310,18 +377,9 @@ Guidelines:
 		}
 	}
 
-	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: s.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: summaryPrompt,
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: convText,
-			},
-		},
+	resp, err := s.provider.Complete(ctx, CompletionRequest{
+		System:      summaryPrompt,
+		Prompt:      convText,
 		Temperature: 0.3,
 		MaxTokens:   500,
 	})
@@ -329,13 +387,9 @@ Guidelines:
 		return nil, fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	s.tokenCount += resp.Usage.TotalTokens
+	s.tokenCount += resp.TokensUsed
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response for summary")
-	}
-
-	responseContent := resp.Choices[0].Message.Content
+	responseContent := resp.Content
 
 	// Parse the JSON response
 	var summaryData struct {
@@ -416,20 +470,66 @@ func (s *Service) ResetTokenCount() {
 	s.tokenCount = 0
 }
 
-func (s *Service) convertMessages(messages []models.ConversationMsg) []openai.ChatCompletionMessage {
-	result := make([]openai.ChatCompletionMessage, 0, len(messages))
+// convertMessages turns the stored history back into model messages, tool
+// calls and results included. Tool results longer than maxToolResult are cut.
+func convertMessages(messages []models.ConversationMsg, maxToolResult int) []Message {
+	result := make([]Message, 0, len(messages))
 	for _, msg := range messages {
-		role := openai.ChatMessageRoleUser
+		role := RoleUser
 		switch msg.Role {
 		case "assistant":
-			role = openai.ChatMessageRoleAssistant
+			role = RoleAssistant
 		case "system":
-			role = openai.ChatMessageRoleSystem
+			role = RoleSystem
+		case "tool":
+			role = RoleTool
 		}
-		result = append(result, openai.ChatCompletionMessage{
-			Role:    role,
-			Content: msg.Content,
-		})
+		m := Message{
+			Role:       role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+		}
+		if role == RoleTool {
+			m.Content = truncate(msg.Content, maxToolResult)
+		}
+		for _, tc := range msg.ToolCalls {
+			m.ToolCalls = append(m.ToolCalls, ToolCall{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments})
+		}
+		result = append(result, m)
 	}
 	return result
 }
+
+// historyMessage converts a tool call or tool result message for storing
+func historyMessage(msg Message) models.ConversationMsg {
+	stored := models.ConversationMsg{
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCallID: msg.ToolCallID,
+		Timestamp:  time.Now(),
+	}
+	if msg.Role == RoleAssistant {
+		stored.Content = filterToolCallAnnouncements(msg.Content)
+	}
+	for _, tc := range msg.ToolCalls {
+		stored.ToolCalls = append(stored.ToolCalls, models.ConversationToolCall{
+			ID:        tc.ID,
+			Name:      tc.Name,
+			Arguments: arguments(tc.Arguments),
+		})
+	}
+	return stored
+}
+
+// truncate cuts text to at most max bytes, on a character boundary, saying
+// how much was left out; max 0 keeps it whole
+func truncate(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... [truncated %d characters]", text[:cut], utf8.RuneCountInString(text[cut:]))
+}
@@ -0,0 +1,245 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/tools"
+)
+
+func TestChatRunsScriptedToolCallsOffline(t *testing.T) {
+	mock := NewMockProvider(
+		ChatResponse{ToolCalls: []ToolCall{{
+			Name:      tools.ToolIdentifyUser,
+			Arguments: json.RawMessage(`{"phone_number": "+1 555 010 2000", "name": "Dana Reyes", "email": "dana@example.com"}`),
+		}}},
+		ChatResponse{Content: "Thanks Dana, you're all set."},
+	)
+	service := NewServiceWithProvider(mock, time.UTC)
+	store := database.NewMemoryStore()
+	executor := tools.NewToolExecutor(tools.Services{Store: store}, "session", nil, nil)
+
+	resp, err := service.Chat(context.Background(), []models.ConversationMsg{{Role: "user", Content: "Hi, I'm Dana"}}, executor)
+	if err != nil || resp.Content != "Thanks Dana, you're all set." {
+		t.Fatalf("expected the scripted reply, got %+v %v", resp, err)
+	}
+	if executor.GetUserName() != "Dana Reyes" {
+		t.Fatalf("expected the scripted tool call to run, got user %q", executor.GetUserName())
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 2 || len(requests[0].Tools) == 0 || requests[0].Messages[0].Role != RoleSystem {
+		t.Fatalf("expected two requests with the system prompt and tools, got %+v", requests)
+	}
+	followUp := requests[1].Messages
+	call, result := followUp[len(followUp)-2], followUp[len(followUp)-1]
+	if call.Role != RoleAssistant || len(call.ToolCalls) != 1 || result.Role != RoleTool || result.ToolCallID != call.ToolCalls[0].ID || call.ToolCalls[0].ID == "" {
+		t.Fatalf("expected the tool call and its result to be paired, got %+v %+v", call, result)
+	}
+
+	if _, err := service.Chat(context.Background(), nil, executor); err == nil {
+		t.Fatal("expected an exhausted script to fail")
+	}
+}
+
+func TestToolErrorsAreSentAsValidJSON(t *testing.T) {
+	mock := NewMockProvider(
+		ChatResponse{ToolCalls: []ToolCall{{Name: `say "hi"\now`, Arguments: json.RawMessage(`{}`)}}},
+		ChatResponse{Content: "Sorry, something went wrong."},
+	)
+	service := NewServiceWithProvider(mock, time.UTC)
+	executor := tools.NewToolExecutor(tools.Services{Store: database.NewMemoryStore()}, "session", nil, nil)
+
+	if _, err := service.Chat(context.Background(), []models.ConversationMsg{{Role: "user", Content: "Hello"}}, executor); err != nil {
+		t.Fatal(err)
+	}
+	followUp := mock.Requests()[1].Messages
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(followUp[len(followUp)-1].Content), &result); err != nil {
+		t.Fatalf("expected the tool error as valid JSON, got %q: %v", followUp[len(followUp)-1].Content, err)
+	}
+	if result["success"] != false || !strings.Contains(result["error"].(string), `say "hi"`) {
+		t.Fatalf("unexpected tool error result %v", result)
+	}
+}
+
+func TestEndingTheCallSendsToolsWithTheFinalRequest(t *testing.T) {
+	mock := NewMockProvider(
+		ChatResponse{ToolCalls: []ToolCall{{Name: tools.ToolEndConversation, Arguments: json.RawMessage(`{"reason": "caller is done"}`)}}},
+		ChatResponse{Content: "Thanks for calling, goodbye!"},
+	)
+	service := NewServiceWithProvider(mock, time.UTC)
+	executor := tools.NewToolExecutor(tools.Services{Store: database.NewMemoryStore()}, "session", nil, nil)
+
+	resp, err := service.Chat(context.Background(), []models.ConversationMsg{{Role: "user", Content: "That's all, bye"}}, executor)
+	if err != nil || !resp.ShouldEnd || resp.Content != "Thanks for calling, goodbye!" {
+		t.Fatalf("expected the call to end with the goodbye, got %+v %v", resp, err)
+	}
+	requests := mock.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected two requests, got %d", len(requests))
+	}
+	final := requests[1]
+	if len(final.Tools) != len(requests[0].Tools) || len(final.Tools) == 0 {
+		t.Fatalf("expected the final request to carry the tool definitions, got %d of %d", len(final.Tools), len(requests[0].Tools))
+	}
+	if last := final.Messages[len(final.Messages)-1]; last.Role != RoleTool {
+		t.Fatalf("expected the end_conversation result last, got %+v", last)
+	}
+}
+
+func TestAnthropicProviderNormalizesToolCalls(t *testing.T) {
+	var got anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Checking."}, {"type": "tool_use", "id": "toolu_2", "name": "fetch_slots", "input": {"date": "2025-03-04"}}], "usage": {"input_tokens": 30, "output_tokens": 12}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider("key", server.URL, "model")
+	resp, err := provider.Chat(context.Background(), ChatRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Book me in"},
+			{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "toolu_1", Name: "identify_user"}, {ID: "toolu_1b", Name: "list_services"}}},
+			{Role: RoleTool, ToolCallID: "toolu_1", Content: `{"success": true}`},
+			{Role: RoleTool, ToolCallID: "toolu_1b", Content: `{"services": []}`},
+		},
+		Tools: []ToolSpec{{Name: "fetch_slots", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.System != "Be brief." || len(got.Messages) != 3 || got.MaxTokens == 0 || got.Tools[0].InputSchema == nil {
+		t.Fatalf("unexpected request body %+v", got)
+	}
+	results := got.Messages[2]
+	if results.Role != RoleUser || len(results.Content) != 2 || results.Content[1].ToolUseID != "toolu_1b" {
+		t.Fatalf("expected both tool results in one user turn, got %+v", results)
+	}
+	if string(got.Messages[1].Content[0].Input) != "{}" {
+		t.Fatalf("expected missing arguments to be sent as an object, got %s", got.Messages[1].Content[0].Input)
+	}
+
+	if resp.Content != "Checking." || resp.TokensUsed != 42 || len(resp.ToolCalls) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if tc := resp.ToolCalls[0]; tc.ID != "toolu_2" || tc.Name != "fetch_slots" || !strings.Contains(string(tc.Arguments), "2025-03-04") {
+		t.Fatalf("unexpected tool call %+v", tc)
+	}
+}
+
+func TestChatStreamSpeaksWholeSentences(t *testing.T) {
+	mock := NewMockProvider(ChatResponse{Content: "Sure, Dr. Lee is free at 2.30 tomorrow. Shall I book it? [Calling check_availability]"})
+	service := NewServiceWithProvider(mock, time.UTC)
+	executor := tools.NewToolExecutor(tools.Services{Store: database.NewMemoryStore()}, "session", nil, nil)
+
+	var chunks []string
+	resp, err := service.ChatStream(context.Background(), []models.ConversationMsg{{Role: "user", Content: "Is Dr. Lee free?"}}, executor, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Sure, Dr. Lee is free at 2.30 tomorrow.", "Shall I book it?"}
+	if len(chunks) != len(want) || chunks[0] != want[0] || chunks[1] != want[1] {
+		t.Fatalf("expected sentence chunks %q, got %q", want, chunks)
+	}
+	if resp.Content != strings.Join(want, " ") {
+		t.Fatalf("expected the reply to be what was spoken, got %q", resp.Content)
+	}
+}
+
+func TestToolHistoryIsReplayedInLaterTurns(t *testing.T) {
+	mock := NewMockProvider(
+		ChatResponse{ToolCalls: []ToolCall{{
+			Name:      tools.ToolIdentifyUser,
+			Arguments: json.RawMessage(`{"phone_number": "+1 555 010 2000", "name": "Dana Reyes"}`),
+		}}},
+		ChatResponse{Content: "Hi Dana."},
+		ChatResponse{Content: "Anything else?"},
+	)
+	service := NewServiceWithProvider(mock, time.UTC)
+	service.history.maxToolResult = 20
+	executor := tools.NewToolExecutor(tools.Services{Store: database.NewMemoryStore()}, "session", nil, nil)
+
+	history := []models.ConversationMsg{{Role: "user", Content: "Hi, I'm Dana"}}
+	resp, err := service.Chat(context.Background(), history, executor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 3 || len(resp.Messages[0].ToolCalls) != 1 || resp.Messages[1].ToolCallID != resp.Messages[0].ToolCalls[0].ID || resp.Messages[2].Content != "Hi Dana." {
+		t.Fatalf("expected the tool call, its result and the reply in the turn, got %+v", resp.Messages)
+	}
+
+	history = append(append(history, resp.Messages...), models.ConversationMsg{Role: "user", Content: "Thanks"})
+	if _, err := service.Chat(context.Background(), history, executor); err != nil {
+		t.Fatal(err)
+	}
+	replayed := mock.Requests()[2].Messages
+	call, result := replayed[2], replayed[3]
+	if call.Role != RoleAssistant || len(call.ToolCalls) != 1 || call.ToolCalls[0].Name != tools.ToolIdentifyUser || result.Role != RoleTool || result.ToolCallID != call.ToolCalls[0].ID {
+		t.Fatalf("expected the earlier tool call to be replayed, got %+v %+v", call, result)
+	}
+	if !strings.Contains(result.Content, "[truncated") || !strings.HasPrefix(resp.Messages[1].Content, result.Content[:20]) {
+		t.Fatalf("expected the replayed result to be truncated, got %q", result.Content)
+	}
+}
+
+func TestOlderTurnsAreSummarizedOverBudget(t *testing.T) {
+	mock := NewMockProvider(
+		ChatResponse{Content: "Dana Reyes has appointment apt-42 on Friday at 10 AM."},
+		ChatResponse{Content: "Sure."},
+	)
+	service := NewServiceWithProvider(mock, time.UTC)
+	service.history = NewContextManager(mock, "gpt-4o", 2200, 0)
+	executor := tools.NewToolExecutor(tools.Services{Store: database.NewMemoryStore()}, "session", nil, nil)
+	executor.SetUserIdentity("+15550102000", "Dana Reyes")
+
+	filler := strings.Repeat("Let me say a little more about what I would like. ", 12)
+	history := []models.ConversationMsg{
+		{Role: "user", Content: "What do I have booked?"},
+		{Role: "assistant", ToolCalls: []models.ConversationToolCall{{ID: "call_1", Name: tools.ToolRetrieveAppointments, Arguments: json.RawMessage(`{}`)}}},
+		{Role: "tool", ToolCallID: "call_1", Content: `{"appointments": [{"id": "apt-42"}]}`},
+		{Role: "assistant", Content: "You have a haircut on Friday at 10 AM."},
+	}
+	for i := 0; i < 4; i++ {
+		history = append(history,
+			models.ConversationMsg{Role: "user", Content: filler},
+			models.ConversationMsg{Role: "assistant", Content: filler},
+		)
+	}
+	history = append(history, models.ConversationMsg{Role: "user", Content: "Move it to Monday."})
+
+	if _, err := service.Chat(context.Background(), history, executor); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 2 || !strings.Contains(requests[0].Messages[1].Content, "Agent called retrieve_appointments") || !strings.Contains(requests[0].Messages[1].Content, "apt-42") {
+		t.Fatalf("expected the older turns, tool calls included, to be summarized, got %+v", requests)
+	}
+
+	sent := requests[1].Messages
+	if sent[0].Role != RoleSystem || sent[1].Role != RoleSystem || !strings.Contains(sent[1].Content, "apt-42 on Friday") || !strings.Contains(sent[1].Content, "Dana Reyes, phone +15550102000") {
+		t.Fatalf("expected the memory with pinned facts after the system prompt, got %+v", sent[:2])
+	}
+	if last := sent[len(sent)-1]; last.Content != "Move it to Monday." || sent[2].Role != RoleUser || len(sent) >= len(history) {
+		t.Fatalf("expected only the recent turns after the memory, got %d messages", len(sent))
+	}
+	if estimate := NewTokenEstimator("gpt-4o").Messages(sent); estimate > 2200 {
+		t.Fatalf("expected the request to fit the budget, estimated %d tokens", estimate)
+	}
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MockProvider replays queued responses instead of calling a model, so the
+// agent can be driven end to end offline, e.g. in CI. Each Chat, ChatStream
+// or Complete call takes the next response in the queue.
+type MockProvider struct {
+	mu        sync.Mutex
+	responses []ChatResponse
+	requests  []ChatRequest
+	calls     int
+}
+
+// NewMockProvider creates a provider that will answer with responses in order
+func NewMockProvider(responses ...ChatResponse) *MockProvider {
+	return &MockProvider{responses: responses}
+}
+
+// LoadMockProvider reads the queued responses from a JSON file holding an
+// array of {"content": ..., "tool_calls": [{"name": ..., "arguments": {...}}]}
+func LoadMockProvider(path string) (*MockProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LLM_MOCK_SCRIPT: %w", err)
+	}
+	var responses []ChatResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("invalid LLM_MOCK_SCRIPT %s: %w", path, err)
+	}
+	return NewMockProvider(responses...), nil
+}
+
+// Enqueue adds responses to the end of the queue
+func (p *MockProvider) Enqueue(responses ...ChatResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.responses = append(p.responses, responses...)
+}
+
+// Requests returns every request received so far
+func (p *MockProvider) Requests() []ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ChatRequest(nil), p.requests...)
+}
+
+// Chat returns the next queued response
+func (p *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, req)
+	if len(p.responses) == 0 {
+		return nil, fmt.Errorf("mock provider has no response queued for request %d", len(p.requests))
+	}
+	resp := p.responses[0]
+	p.responses = p.responses[1:]
+
+	// Scripts may leave tool call IDs out
+	resp.ToolCalls = append([]ToolCall(nil), resp.ToolCalls...)
+	for i := range resp.ToolCalls {
+		p.calls++
+		if resp.ToolCalls[i].ID == "" {
+			resp.ToolCalls[i].ID = fmt.Sprintf("call_%d", p.calls)
+		}
+		resp.ToolCalls[i].Arguments = arguments(resp.ToolCalls[i].Arguments)
+	}
+	return &resp, nil
+}
+
+// ChatStream returns the next queued response, passing its text on a word
+// at a time as a streaming model would
+func (p *MockProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(text string)) (*ChatResponse, error) {
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		if word != "" {
+			onDelta(word)
+		}
+	}
+	return resp, nil
+}
+
+// Complete returns the next queued response
+func (p *MockProvider) Complete(ctx context.Context, req CompletionRequest) (*ChatResponse, error) {
+	return p.Chat(ctx, req.chat())
+}
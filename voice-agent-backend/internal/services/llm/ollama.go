@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const ollamaAPIURL = "http://localhost:11434"
+
+// OllamaProvider talks to a local Ollama server's native chat API, so the
+// agent can run without any hosted model
+type OllamaProvider struct {
+	baseURL string
+	model   string
+}
+
+// NewOllamaProvider creates a provider; an empty baseURL uses Ollama's
+// default local address
+func NewOllamaProvider(baseURL, model string) *OllamaProvider {
+	return &OllamaProvider{
+		baseURL: strings.TrimSuffix(withDefault(baseURL, ollamaAPIURL), "/"),
+		model:   model,
+	}
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"` // an object, not a string
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaMessage        `json:"messages"`
+	Tools    []ollamaTool           `json:"tools,omitempty"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+// Chat sends a conversation to /api/chat. Ollama does not identify tool
+// calls, so each one is given an ID here to pair it with its result.
+func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var resp ollamaResponse
+	if err := postJSON(ctx, p.baseURL+"/api/chat", nil, p.request(req), &resp); err != nil {
+		return nil, fmt.Errorf("ollama error: %w", err)
+	}
+	return ollamaResult(resp), nil
+}
+
+// ChatStream streams a reply as one JSON object per line. Tool calls come
+// whole in one of them and the last carries the token counts.
+func (p *OllamaProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(text string)) (*ChatResponse, error) {
+	body := p.request(req)
+	body.Stream = true
+
+	stream, err := post(ctx, p.baseURL+"/api/chat", nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("ollama error: %w", err)
+	}
+	defer stream.Close()
+
+	var resp ollamaResponse
+	var content strings.Builder
+	err = scanLines(stream, func(line string) error {
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return fmt.Errorf("invalid chunk: %w", err)
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			onDelta(chunk.Message.Content)
+		}
+		resp.Message.ToolCalls = append(resp.Message.ToolCalls, chunk.Message.ToolCalls...)
+		if chunk.Done {
+			resp.PromptEvalCount, resp.EvalCount = chunk.PromptEvalCount, chunk.EvalCount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ollama stream error: %w", err)
+	}
+
+	resp.Message.Content = content.String()
+	return ollamaResult(resp), nil
+}
+
+// request converts a ChatRequest to Ollama's format
+func (p *OllamaProvider) request(req ChatRequest) ollamaRequest {
+	body := ollamaRequest{
+		Model:   p.model,
+		Options: map[string]interface{}{"temperature": req.Temperature},
+	}
+	if req.MaxTokens > 0 {
+		body.Options["num_predict"] = req.MaxTokens
+	}
+
+	for _, msg := range req.Messages {
+		m := ollamaMessage{Role: msg.Role, Content: msg.Content}
+		for _, tc := range msg.ToolCalls {
+			var call ollamaToolCall
+			call.Function.Name = tc.Name
+			call.Function.Arguments = arguments(tc.Arguments)
+			m.ToolCalls = append(m.ToolCalls, call)
+		}
+		body.Messages = append(body.Messages, m)
+	}
+
+	for _, spec := range req.Tools {
+		tool := ollamaTool{Type: "function"}
+		tool.Function.Name = spec.Name
+		tool.Function.Description = spec.Description
+		tool.Function.Parameters = spec.Parameters
+		body.Tools = append(body.Tools, tool)
+	}
+
+	return body
+}
+
+// ollamaResult converts a reply, giving each tool call an ID
+func ollamaResult(resp ollamaResponse) *ChatResponse {
+	result := &ChatResponse{
+		Content:    resp.Message.Content,
+		TokensUsed: resp.PromptEvalCount + resp.EvalCount,
+	}
+	for _, tc := range resp.Message.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        "call_" + uuid.New().String(),
+			Name:      tc.Function.Name,
+			Arguments: arguments(tc.Function.Arguments),
+		})
+	}
+	return result
+}
+
+// Complete answers a single prompt
+func (p *OllamaProvider) Complete(ctx context.Context, req CompletionRequest) (*ChatResponse, error) {
+	return p.Chat(ctx, req.chat())
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// OpenAIProvider talks to the OpenAI chat completions API or any server
+// compatible with it (Azure gateways, vLLM, llama.cpp, ...)
+type OpenAIProvider struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIProvider creates a provider; an empty baseURL uses OpenAI's
+func NewOpenAIProvider(apiKey, baseURL, model string) *OpenAIProvider {
+	clientConfig := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		clientConfig.BaseURL = baseURL
+	}
+	return &OpenAIProvider{
+		client: openai.NewClientWithConfig(clientConfig),
+		model:  model,
+	}
+}
+
+// Chat sends a conversation to the chat completions endpoint
+func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.request(req))
+	if err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := resp.Choices[0].Message
+	result := &ChatResponse{
+		Content:    choice.Content,
+		TokensUsed: resp.Usage.TotalTokens,
+	}
+	for _, tc := range choice.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: json.RawMessage(tc.Function.Arguments),
+		})
+	}
+	return result, nil
+}
+
+// ChatStream streams a reply. Tool calls arrive in fragments keyed by their
+// index and are assembled before being returned.
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(text string)) (*ChatResponse, error) {
+	request := p.request(req)
+	request.Stream = true
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := p.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	defer stream.Close()
+
+	result := &ChatResponse{}
+	var content strings.Builder
+	var calls []ToolCall
+	var args []string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chat completion stream failed: %w", err)
+		}
+		if chunk.Usage != nil {
+			result.TokensUsed = chunk.Usage.TotalTokens
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			onDelta(delta.Content)
+		}
+		for _, tc := range delta.ToolCalls {
+			// Fragments without an index continue the latest call
+			i := len(calls) - 1
+			if tc.Index != nil {
+				i = *tc.Index
+			}
+			if i < 0 {
+				i = 0
+			}
+			for len(calls) <= i {
+				calls = append(calls, ToolCall{})
+				args = append(args, "")
+			}
+			if tc.ID != "" {
+				calls[i].ID = tc.ID
+			}
+			if tc.Function.Name != "" {
+				calls[i].Name = tc.Function.Name
+			}
+			args[i] += tc.Function.Arguments
+		}
+	}
+
+	result.Content = content.String()
+	for i, call := range calls {
+		call.Arguments = json.RawMessage(args[i])
+		result.ToolCalls = append(result.ToolCalls, call)
+	}
+	return result, nil
+}
+
+// request converts a ChatRequest to the OpenAI wire format
+func (p *OpenAIProvider) request(req ChatRequest) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		m := openai.ChatCompletionMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+		}
+		for _, tc := range msg.ToolCalls {
+			m.ToolCalls = append(m.ToolCalls, openai.ToolCall{
+				ID:   tc.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      tc.Name,
+					Arguments: string(arguments(tc.Arguments)),
+				},
+			})
+		}
+		messages = append(messages, m)
+	}
+
+	var tools []openai.Tool
+	for _, spec := range req.Tools {
+		tools = append(tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        spec.Name,
+				Description: spec.Description,
+				Parameters:  spec.Parameters,
+			},
+		})
+	}
+
+	return openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    messages,
+		Tools:       tools,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
+}
+
+// Complete answers a single prompt
+func (p *OpenAIProvider) Complete(ctx context.Context, req CompletionRequest) (*ChatResponse, error) {
+	return p.Chat(ctx, req.chat())
+}
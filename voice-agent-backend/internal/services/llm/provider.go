@@ -0,0 +1,229 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/voice-agent/backend/internal/config"
+)
+
+// Roles of a Message
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message is one turn of a conversation in the form every provider accepts
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant turns that call tools
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool turns: the call this answers
+}
+
+// ToolSpec describes a tool the model may call
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments
+}
+
+// ChatRequest is a conversation sent to a provider. A leading system
+// message is the system prompt.
+type ChatRequest struct {
+	Messages    []Message
+	Tools       []ToolSpec
+	Temperature float32
+	MaxTokens   int
+}
+
+// CompletionRequest is a single prompt answered without tools
+type CompletionRequest struct {
+	System      string
+	Prompt      string
+	Temperature float32
+	MaxTokens   int
+}
+
+func (r CompletionRequest) chat() ChatRequest {
+	return ChatRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: r.System},
+			{Role: RoleUser, Content: r.Prompt},
+		},
+		Temperature: r.Temperature,
+		MaxTokens:   r.MaxTokens,
+	}
+}
+
+// ChatResponse is the model's reply: text, tool calls, or both
+type ChatResponse struct {
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	TokensUsed int        `json:"tokens_used,omitempty"`
+}
+
+// Provider is a chat model backend. Implementations translate to and from
+// their vendor's wire format so tool calls look the same whoever made them.
+type Provider interface {
+	// Chat sends a conversation with the tools the model may call
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// Complete answers a single prompt, e.g. to summarize a call
+	Complete(ctx context.Context, req CompletionRequest) (*ChatResponse, error)
+}
+
+// requestTimeout bounds a request whose reply is not streamed
+const requestTimeout = 60 * time.Second
+
+// Streamer is implemented by providers that can stream a reply. ChatStream
+// behaves like Chat but passes each piece of text to onDelta as soon as it
+// arrives; tool calls are only returned once complete.
+type Streamer interface {
+	ChatStream(ctx context.Context, req ChatRequest, onDelta func(text string)) (*ChatResponse, error)
+}
+
+// defaultModels is the model each provider uses when LLM_MODEL is not set
+var defaultModels = map[string]string{
+	"":          "gpt-4o",
+	"openai":    "gpt-4o",
+	"llamacpp":  "local",
+	"llama.cpp": "local",
+	"anthropic": "claude-3-5-sonnet-latest",
+	"ollama":    "llama3.1",
+}
+
+// ModelName returns the model LLM_MODEL names, or the provider's default
+func ModelName(cfg *config.Config) string {
+	return withDefault(cfg.LLMModel, defaultModels[strings.ToLower(cfg.LLMProvider)])
+}
+
+// NewProvider returns the provider named by LLM_PROVIDER
+func NewProvider(cfg *config.Config) (Provider, error) {
+	model := ModelName(cfg)
+	switch strings.ToLower(cfg.LLMProvider) {
+	case "", "openai":
+		return NewOpenAIProvider(cfg.LLMAPIKey, cfg.LLMBaseURL, model), nil
+	case "llamacpp", "llama.cpp":
+		// llama.cpp's server speaks the OpenAI API and needs no key
+		return NewOpenAIProvider("", withDefault(cfg.LLMBaseURL, "http://localhost:8081/v1"), model), nil
+	case "anthropic":
+		return NewAnthropicProvider(cfg.LLMAPIKey, cfg.LLMBaseURL, model), nil
+	case "ollama":
+		return NewOllamaProvider(cfg.LLMBaseURL, model), nil
+	case "mock":
+		if cfg.LLMMockScript == "" {
+			return NewMockProvider(), nil
+		}
+		return LoadMockProvider(cfg.LLMMockScript)
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q (want openai, anthropic, ollama, llamacpp or mock)", cfg.LLMProvider)
+	}
+}
+
+// toolSpecs converts the registry's tool list
+func toolSpecs(defs []openai.Tool) []ToolSpec {
+	specs := make([]ToolSpec, 0, len(defs))
+	for _, def := range defs {
+		params, _ := def.Function.Parameters.(map[string]interface{})
+		specs = append(specs, ToolSpec{
+			Name:        def.Function.Name,
+			Description: def.Function.Description,
+			Parameters:  params,
+		})
+	}
+	return specs
+}
+
+// arguments returns a tool call's arguments as a JSON object, which some
+// providers require even when the model passed none
+func arguments(raw json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage(`{}`)
+	}
+	return raw
+}
+
+// postJSON sends body to url and decodes the JSON reply into out
+func postJSON(ctx context.Context, url string, headers map[string]string, body, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	respBody, err := post(ctx, url, headers, body)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+
+	if err := json.NewDecoder(respBody).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// post sends body to url and returns the reply for the caller to read and
+// close, e.g. as a stream of events
+func post(ctx context.Context, url string, headers map[string]string, body interface{}) (io.ReadCloser, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// No overall timeout: streamed replies last as long as the model talks.
+	// Callers bound requests with ctx.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return resp.Body, nil
+}
+
+// scanLines calls fn with each non-empty line of a streamed reply
+func scanLines(r io.Reader, fn func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	return nil
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
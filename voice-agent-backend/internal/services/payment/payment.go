@@ -11,11 +11,13 @@ import (
 	"github.com/stripe/stripe-go/v72/invoice"
 	"github.com/stripe/stripe-go/v72/paymentintent"
 	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/models"
 )
 
 // PaymentService handles payment operations via Stripe
 type PaymentService struct {
-	apiKey string
+	apiKey         string
+	publishableKey string
 }
 
 // PaymentRecord represents a payment transaction
@@ -49,10 +51,17 @@ type PaymentIntent struct {
 func NewPaymentService(cfg *config.Config) *PaymentService {
 	stripe.Key = cfg.StripeSecretKey
 	return &PaymentService{
-		apiKey: cfg.StripeSecretKey,
+		apiKey:         cfg.StripeSecretKey,
+		publishableKey: cfg.StripePublishableKey,
 	}
 }
 
+// CollectsOnScreen reports whether the client can show a payment form for a
+// payment intent, which needs the publishable key
+func (ps *PaymentService) CollectsOnScreen() bool {
+	return ps.publishableKey != ""
+}
+
 // CreatePaymentIntent creates a payment intent for appointment booking
 func (ps *PaymentService) CreatePaymentIntent(userPhone, userName string, appointmentID string, amountCents int64, description string) (*PaymentIntent, error) {
 	params := &stripe.PaymentIntentParams{
@@ -73,11 +82,12 @@ func (ps *PaymentService) CreatePaymentIntent(userPhone, userName string, appoin
 	}
 
 	return &PaymentIntent{
-		ID:           pi.ID,
-		ClientSecret: pi.ClientSecret,
-		Amount:       pi.Amount,
-		Currency:     string(pi.Currency),
-		Status:       string(pi.Status),
+		ID:             pi.ID,
+		ClientSecret:   pi.ClientSecret,
+		Amount:         pi.Amount,
+		Currency:       string(pi.Currency),
+		Status:         string(pi.Status),
+		PublishableKey: ps.publishableKey,
 	}, nil
 }
 
@@ -94,14 +104,53 @@ func (ps *PaymentService) ConfirmPaymentIntent(paymentIntentID string, paymentMe
 	}
 
 	return &PaymentIntent{
-		ID:           pi.ID,
-		ClientSecret: pi.ClientSecret,
-		Amount:       pi.Amount,
-		Currency:     string(pi.Currency),
-		Status:       string(pi.Status),
+		ID:             pi.ID,
+		ClientSecret:   pi.ClientSecret,
+		Amount:         pi.Amount,
+		Currency:       string(pi.Currency),
+		Status:         string(pi.Status),
+		PublishableKey: ps.publishableKey,
+	}, nil
+}
+
+// GetPaymentIntent retrieves the current state of a payment intent
+func (ps *PaymentService) GetPaymentIntent(paymentIntentID string) (*PaymentIntent, error) {
+	pi, err := paymentintent.Get(paymentIntentID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment intent: %w", err)
+	}
+
+	return &PaymentIntent{
+		ID:             pi.ID,
+		ClientSecret:   pi.ClientSecret,
+		Amount:         pi.Amount,
+		Currency:       string(pi.Currency),
+		Status:         string(pi.Status),
+		PublishableKey: ps.publishableKey,
 	}, nil
 }
 
+// CancelPaymentIntent cancels a payment intent that has not been paid
+func (ps *PaymentService) CancelPaymentIntent(paymentIntentID string) error {
+	if _, err := paymentintent.Cancel(paymentIntentID, nil); err != nil {
+		return fmt.Errorf("failed to cancel payment intent: %w", err)
+	}
+	return nil
+}
+
+// IsOpenIntentStatus reports whether a payment intent with this status can
+// still be paid or is being paid
+func IsOpenIntentStatus(status string) bool {
+	switch stripe.PaymentIntentStatus(status) {
+	case stripe.PaymentIntentStatusRequiresPaymentMethod,
+		stripe.PaymentIntentStatusRequiresConfirmation,
+		stripe.PaymentIntentStatusRequiresAction,
+		stripe.PaymentIntentStatusProcessing:
+		return true
+	}
+	return false
+}
+
 // ProcessPayment processes a one-time charge
 func (ps *PaymentService) ProcessPayment(userPhone, userName string, amountCents int64, tokenID string, description string) (*PaymentRecord, error) {
 	chargeParams := &stripe.ChargeParams{
@@ -203,27 +252,25 @@ func (ps *PaymentService) ValidatePaymentMethod(token string) (bool, error) {
 	return true, nil
 }
 
-// CalculateAppointmentCost calculates cost for an appointment
-func CalculateAppointmentCost(appointmentType string, durationMinutes int) int64 {
-	// Base cost in cents ($)
-	baseCost := int64(1500) // $15.00
-
-	// Add cost based on duration
-	durationCost := int64(durationMinutes) * 10 // 10 cents per minute
+// Fallback pricing for appointments booked without a catalog service
+const (
+	baseAppointmentCents = 1500 // $15.00
+	perMinuteCents       = 10
+)
 
-	// Add cost based on type
-	var typeMultiplier int64 = 1
-	switch appointmentType {
-	case "consultation":
-		typeMultiplier = 1 // normal cost
-	case "premium":
-		typeMultiplier = 2 // double cost
-	case "VIP":
-		typeMultiplier = 3 // triple cost
+// CalculateAppointmentCost calculates cost for an appointment in cents.
+// Catalog services carry their own price; other appointments pay a base
+// fee plus a per-minute rate.
+func CalculateAppointmentCost(service *models.Service, durationMinutes int) int64 {
+	if service != nil {
+		return service.PriceCents
 	}
+	return baseAppointmentCents + int64(durationMinutes)*perMinuteCents
+}
 
-	totalCost := (baseCost + durationCost) * typeMultiplier
-	return totalCost
+// FormatAmount formats an amount in cents as dollars, e.g. "$45.00"
+func FormatAmount(cents int64) string {
+	return fmt.Sprintf("$%d.%02d", cents/100, cents%100)
 }
 
 // GetPayableAmount returns the amount to be paid (useful for discounts, taxes, etc.)
@@ -0,0 +1,41 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestCalculateAppointmentCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  *models.Service
+		duration int
+		want     int64
+	}{
+		{"no service", nil, 30, baseAppointmentCents + 30*perMinuteCents},
+		{"longer without a service", nil, 90, baseAppointmentCents + 90*perMinuteCents},
+		{"catalog price", &models.Service{Duration: 60, PriceCents: 8500}, 60, 8500},
+		{"catalog price ignores the length", &models.Service{Duration: 60, PriceCents: 8500}, 120, 8500},
+		{"free service", &models.Service{Duration: 15}, 15, 0},
+	}
+	for _, tt := range tests {
+		if got := CalculateAppointmentCost(tt.service, tt.duration); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[int64]string{
+		0:      "$0.00",
+		5:      "$0.05",
+		4500:   "$45.00",
+		123456: "$1234.56",
+	}
+	for cents, want := range tests {
+		if got := FormatAmount(cents); got != want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
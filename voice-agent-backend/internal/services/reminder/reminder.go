@@ -24,6 +24,8 @@ const (
 // ReminderService manages appointment reminders
 type ReminderService struct {
 	config    *config.Config
+	store     database.Store
+	location  *time.Location // business timezone for calendar-day comparisons
 	ticker    *time.Ticker
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -40,11 +42,19 @@ type ReminderRecord struct {
 }
 
 // NewReminderService creates a new reminder service
-func NewReminderService(cfg *config.Config) *ReminderService {
+func NewReminderService(cfg *config.Config, store database.Store) *ReminderService {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	location, err := cfg.BusinessLocation()
+	if err != nil {
+		log.Printf("Reminder service: %v, falling back to server timezone", err)
+		location = time.Local
+	}
+
 	rs := &ReminderService{
 		config:    cfg,
+		store:     store,
+		location:  location,
 		ticker:    time.NewTicker(1 * time.Minute), // Check every minute
 		ctx:       ctx,
 		cancel:    cancel,
@@ -109,13 +119,13 @@ func (rs *ReminderService) checkReminders() {
 
 	for appointmentID, record := range remindersCopy {
 		// Fetch appointment details
-		appointment, err := database.DB.GetAppointmentByID(appointmentID)
+		appointment, err := rs.store.GetAppointmentByID(appointmentID)
 		if err != nil {
 			log.Printf("Failed to fetch appointment %s: %v", appointmentID, err)
 			continue
 		}
 
-		if appointment == nil || appointment.Status != models.StatusBooked {
+		if appointment == nil || !appointment.IsActive() {
 			rs.RemoveAppointment(appointmentID)
 			continue
 		}
@@ -135,7 +145,7 @@ func (rs *ReminderService) checkReminders() {
 		}
 
 		// Check for on-day reminder
-		if !record.RemindersSent[ReminderTypeOnDay] && timeUntilAppointment > 0 && timeUntilAppointment <= 24*time.Hour && isNextDay(now, appointment.DateTime) {
+		if !record.RemindersSent[ReminderTypeOnDay] && timeUntilAppointment > 0 && timeUntilAppointment <= 24*time.Hour && isNextDay(now, appointment.DateTime, rs.locationFor(appointment)) {
 			rs.sendReminder(appointment, ReminderTypeOnDay)
 			rs.markReminderSent(appointmentID, ReminderTypeOnDay)
 		}
@@ -171,9 +181,24 @@ func (rs *ReminderService) markReminderSent(appointmentID string, reminderType R
 	}
 }
 
-// isNextDay checks if two times are on different calendar days
-func isNextDay(now, appointmentTime time.Time) bool {
-	return now.YearDay() != appointmentTime.YearDay() || now.Year() != appointmentTime.Year()
+// locationFor returns the user's timezone if they have one, otherwise the business timezone
+func (rs *ReminderService) locationFor(appointment *models.Appointment) *time.Location {
+	user, err := rs.store.GetUserByPhone(appointment.UserPhone)
+	if err != nil || user == nil || user.Timezone == "" {
+		return rs.location
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return rs.location
+	}
+	return loc
+}
+
+// isNextDay checks if two times are on different calendar days in loc
+func isNextDay(now, appointmentTime time.Time, loc *time.Location) bool {
+	y1, m1, d1 := now.In(loc).Date()
+	y2, m2, d2 := appointmentTime.In(loc).Date()
+	return y1 != y2 || m1 != m2 || d1 != d2
 }
 
 // Stop stops the reminder service
@@ -202,7 +227,7 @@ func (rs *ReminderService) GetReminderStatus(appointmentID string) map[string]in
 func (rs *ReminderService) LoadPendingAppointments() error {
 	now := time.Now()
 	futureDate := now.Add(30 * 24 * time.Hour)
-	appointments, err := database.DB.GetUpcomingAppointmentsInWindow(now, futureDate)
+	appointments, err := rs.store.GetUpcomingAppointmentsInWindow(now, futureDate)
 	if err != nil {
 		return fmt.Errorf("failed to load pending appointments: %w", err)
 	}
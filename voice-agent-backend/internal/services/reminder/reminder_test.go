@@ -0,0 +1,40 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsNextDayUsesTheGivenTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// 7pm and 9pm in New York fall on the same day there but either side
+	// of midnight in UTC, and both on the next morning in Tokyo
+	now := time.Date(2026, 3, 10, 19, 0, 0, 0, newYork)
+	later := time.Date(2026, 3, 10, 21, 0, 0, 0, newYork)
+	tomorrow := time.Date(2026, 3, 11, 9, 0, 0, 0, newYork)
+
+	tests := []struct {
+		name     string
+		now, apt time.Time
+		loc      *time.Location
+		want     bool
+	}{
+		{"same evening in New York", now, later, newYork, false},
+		{"crosses midnight in UTC", now, later, time.UTC, true},
+		{"next morning in New York", now, tomorrow, newYork, true},
+		{"same morning in Tokyo", now, later, tokyo, false},
+	}
+	for _, tt := range tests {
+		if got := isNextDay(tt.now, tt.apt, tt.loc); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"log"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// addCalendarLinks adds the appointment's ICS link (an invite, or a
+// cancellation once cancelled) and the caller's feed URL to a result
+func (e *ToolExecutor) addCalendarLinks(result map[string]interface{}, appointment *models.Appointment) {
+	if e.calendar == nil {
+		return
+	}
+	link, err := e.calendar.AppointmentURL(appointment.UserPhone, appointment.ID)
+	if err != nil {
+		log.Printf("[addCalendarLinks] WARNING: Failed to get calendar link: %v", err)
+		return
+	}
+	result["ics_url"] = link
+	e.addFeedLink(result, appointment.UserPhone)
+}
+
+// addFeedLink adds the caller's calendar feed URL to a result
+func (e *ToolExecutor) addFeedLink(result map[string]interface{}, phone string) {
+	if e.calendar == nil {
+		return
+	}
+	link, err := e.calendar.FeedURL(phone)
+	if err != nil {
+		log.Printf("[addFeedLink] WARNING: Failed to get calendar feed link: %v", err)
+		return
+	}
+	result["calendar_feed_url"] = link
+}
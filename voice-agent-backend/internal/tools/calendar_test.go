@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/calendar"
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestBookingResultCarriesOpaqueCalendarLinks(t *testing.T) {
+	store := database.NewMemoryStore()
+	feeds := calendar.NewFeeds(&config.Config{PublicBaseURL: "https://example.com/"}, store)
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Calendar: feeds, Now: testClock}, "session", nil, nil)
+	executor.SetUserIdentity("+15550000001", "Caller")
+
+	slot := testNow.Add(48 * time.Hour)
+	result, err := executor.bookAppointment(map[string]interface{}{"date_time": slot.Format(time.RFC3339), "purpose": "Checkup, annual"})
+	if err != nil {
+		t.Fatalf("booking: %v", err)
+	}
+	booking := result.(map[string]interface{})
+	appointmentID := booking["appointment_id"].(string)
+
+	link, _ := booking["ics_url"].(string)
+	prefix := "https://example.com/api/calendar/"
+	if !strings.HasPrefix(link, prefix) || !strings.HasSuffix(link, "/"+appointmentID+calendar.Extension) {
+		t.Fatalf("unexpected ics_url %q", link)
+	}
+	if strings.Contains(link, "15550000001") {
+		t.Fatalf("link should not carry the phone number: %q", link)
+	}
+	token := strings.TrimPrefix(strings.TrimSuffix(link, "/"+appointmentID+calendar.Extension), prefix)
+	if feed, _ := booking["calendar_feed_url"].(string); feed != prefix+token+calendar.Extension {
+		t.Fatalf("expected the feed to reuse the caller's token, got %q", feed)
+	}
+
+	tokens := []struct {
+		name  string
+		token string
+		phone string
+		err   error
+	}{
+		{"the caller's token", token, "+15550000001", nil},
+		{"a tampered token", token + "x", "", calendar.ErrInvalidToken},
+	}
+	for _, tt := range tokens {
+		if phone, err := feeds.Phone(tt.token); phone != tt.phone || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %q %v", tt.name, phone, err)
+		}
+	}
+
+	appointments, _ := store.GetAppointmentsByPhone("+15550000001")
+	feed := string(feeds.Feed(appointments, testNow))
+	if _, err := executor.cancelAppointment(map[string]interface{}{"appointment_id": appointmentID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	cancelled, _ := store.GetAppointmentByID(appointmentID)
+	invite := string(feeds.Invite(cancelled, testNow))
+
+	documents := []struct {
+		name string
+		ics  string
+		want []string
+	}{
+		{"feed", feed, []string{"METHOD:PUBLISH", "UID:" + calendar.UID(appointmentID), "SUMMARY:Checkup\\, annual", "DTSTART:" + slot.Format("20060102T150405Z")}},
+		{"cancellation", invite, []string{"METHOD:CANCEL", "STATUS:CANCELLED"}},
+	}
+	for _, tt := range documents {
+		for _, want := range tt.want {
+			if !strings.Contains(tt.ics, want) {
+				t.Errorf("%s missing %q:\n%s", tt.name, want, tt.ics)
+			}
+		}
+	}
+}
+
+func TestExternalCalendarBusyTimeBlocksBookingAndBookingsArePushed(t *testing.T) {
+	dir := t.TempDir()
+	dirSync, err := calendar.NewDirSync(dir, time.UTC)
+	if err != nil {
+		t.Fatalf("dir sync: %v", err)
+	}
+
+	// A staff meeting in the business calendar, written by another tool
+	busy := testNow.Add(48 * time.Hour)
+	meeting := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:meeting@example.com\r\n" +
+		"DTSTART:" + busy.Format("20060102T150405Z") + "\r\nDURATION:PT1H\r\nSUMMARY:Staff meeting\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.MkdirAll(dirSync.CalendarDir(""), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirSync.CalendarDir(""), "meeting.ics"), []byte(meeting), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	syncService := calendar.NewSyncService(&config.Config{}, database.NewMemoryStore(), dirSync)
+	store := syncService.Store()
+	if err := syncService.Pull(); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550000001", "Caller")
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		success bool
+		err     string
+	}{
+		{"during the meeting", busy.Add(30 * time.Minute), false, msgSlotBooked},
+		{"overlapping its end", busy.Add(45 * time.Minute), false, msgSlotBooked},
+		{"after the meeting", busy.Add(time.Hour), true, ""},
+	}
+	var booked []string
+	for _, tt := range tests {
+		result, err := executor.bookAppointment(map[string]interface{}{"date_time": tt.at.Format(time.RFC3339)})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		if m["success"] != tt.success || (tt.err != "" && m["error"] != tt.err) {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+		if id, ok := m["appointment_id"].(string); ok {
+			booked = append(booked, id)
+		}
+	}
+
+	// Stop flushes queued pushes
+	syncService.Stop()
+	for _, id := range booked {
+		if _, err := os.Stat(filepath.Join(dirSync.CalendarDir(""), id+calendar.Extension)); err != nil {
+			t.Errorf("expected the booking to be written to the calendar: %v", err)
+		}
+	}
+	blocks, err := dirSync.PullBusy("", busy, busy.Add(3*time.Hour))
+	if err != nil || len(blocks) != 1 || blocks[0].ExternalUID != "meeting@example.com" {
+		t.Fatalf("expected only the meeting as busy time, got %v %v", blocks, err)
+	}
+}
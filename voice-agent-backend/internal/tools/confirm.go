@@ -0,0 +1,439 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// pendingActionTTL is how long a pending action waits for confirm_action
+const pendingActionTTL = 10 * time.Minute
+
+// Statuses of the tool_call event sent for a pending action
+const (
+	statusPending   = "pending"
+	statusConfirmed = "confirmed"
+	statusDeclined  = "declined"
+	statusExpired   = "expired"
+)
+
+// PendingAction is a call to a ConfirmedTool waiting for the caller's
+// agreement. Its ID is both the tool_call ID sent to the client and the
+// confirmation token given to the LLM.
+type PendingAction struct {
+	ID        string
+	Tool      string
+	Arguments map[string]interface{}
+	Summary   string
+	CreatedAt time.Time
+	turn      int // user turns heard when the action was proposed
+}
+
+// RecordUserTurn notes what the caller just said. confirm_action only runs a
+// pending action when the caller has spoken since it was proposed and what
+// they said is a yes.
+func (e *ToolExecutor) RecordUserTurn(text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.userTurns++
+	e.lastReply = text
+}
+
+// PendingActions returns the actions still waiting for the caller's answer,
+// oldest first
+func (e *ToolExecutor) PendingActions() []PendingAction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var actions []PendingAction
+	for _, action := range e.pending {
+		if e.now().Sub(action.CreatedAt) <= pendingActionTTL {
+			actions = append(actions, *action)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].CreatedAt.Before(actions[j].CreatedAt)
+	})
+	return actions
+}
+
+// holdForConfirmation stores a call to a ConfirmedTool as a pending action
+// and tells the LLM what to ask the caller
+func (e *ToolExecutor) holdForConfirmation(tool ConfirmedTool, args map[string]interface{}, id string) map[string]interface{} {
+	summary := tool.Summarize(e, args)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	action := &PendingAction{
+		ID:        id,
+		Tool:      tool.Name(),
+		Arguments: args,
+		Summary:   summary,
+		CreatedAt: e.now(),
+		turn:      e.userTurns,
+	}
+	if e.pending == nil {
+		e.pending = make(map[string]*PendingAction)
+	}
+	for token, old := range e.pending {
+		if e.now().Sub(old.CreatedAt) > pendingActionTTL {
+			delete(e.pending, token)
+			e.notifyPending(old, statusExpired)
+		}
+	}
+	e.pending[id] = action
+	e.notifyPending(action, statusPending)
+
+	log.Printf("[holdForConfirmation] %s is pending confirmation: %s", action.Tool, action.Summary)
+
+	return map[string]interface{}{
+		"success":               false,
+		"awaiting_confirmation": true,
+		"confirmation_token":    id,
+		"summary":               action.Summary,
+		"message":               "Nothing has been done yet. Read the summary to the caller and ask whether to go ahead; once they say yes, call confirm_action with this confirmation_token.",
+	}
+}
+
+// confirmAction runs a pending action once the caller has agreed to it
+func (e *ToolExecutor) confirmAction(args map[string]interface{}) (interface{}, error) {
+	token, _ := args["confirmation_token"].(string)
+	action, refusal := e.takeConfirmed(token)
+	if refusal != nil {
+		return refusal, nil
+	}
+
+	tool, ok := e.registry.Lookup(action.Tool)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", action.Tool)
+	}
+	log.Printf("[confirmAction] Caller confirmed %s", action.Tool)
+	return tool.Execute(e, action.Arguments)
+}
+
+// takeConfirmed removes and returns the pending action for token when the
+// caller's last reply agrees to it. Otherwise it returns the result to give
+// the LLM instead.
+func (e *ToolExecutor) takeConfirmed(token string) (*PendingAction, map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	action, ok := e.pending[token]
+	if !ok {
+		return nil, map[string]interface{}{
+			"success": false,
+			"error":   "No action is waiting for that confirmation_token. Call the tool again to get a new one.",
+		}
+	}
+
+	if e.now().Sub(action.CreatedAt) > pendingActionTTL {
+		delete(e.pending, token)
+		e.notifyPending(action, statusExpired)
+		return nil, map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("The confirmation has expired. Call %s again and ask the caller once more.", action.Tool),
+		}
+	}
+
+	if e.userTurns == action.turn {
+		return nil, map[string]interface{}{
+			"success": false,
+			"summary": action.Summary,
+			"error":   "The caller has not answered yet. Read them the summary and wait for their reply before calling confirm_action.",
+		}
+	}
+
+	// One reply answers one action: the one the caller was asked about last
+	if newer := e.newerPending(action); newer != nil {
+		return nil, map[string]interface{}{
+			"success": false,
+			"summary": action.Summary,
+			"error":   fmt.Sprintf("The caller was asked about something else since (%s), so their reply answers that. Settle it first, then ask about this one on its own.", newer.Summary),
+		}
+	}
+	if e.answeredTurn == e.userTurns {
+		return nil, map[string]interface{}{
+			"success": false,
+			"summary": action.Summary,
+			"error":   "The caller's reply already answered another action. Read them this summary and wait for their reply before calling confirm_action.",
+		}
+	}
+
+	switch classifyReply(e.lastReply) {
+	case replyNo:
+		e.answeredTurn = e.userTurns
+		delete(e.pending, token)
+		e.notifyPending(action, statusDeclined)
+		log.Printf("[confirmAction] Caller declined %s", action.Tool)
+		return nil, map[string]interface{}{
+			"success":  false,
+			"declined": true,
+			"message":  fmt.Sprintf("The caller did not agree, so nothing was changed (%s). Ask what they would like instead.", action.Summary),
+		}
+	case replyUnclear:
+		return nil, map[string]interface{}{
+			"success": false,
+			"summary": action.Summary,
+			"error":   "The caller's reply was not a clear yes. Ask them again whether to go ahead.",
+		}
+	}
+
+	e.answeredTurn = e.userTurns
+	delete(e.pending, token)
+	e.notifyPending(action, statusConfirmed)
+	return action, nil
+}
+
+// newerPending returns an unexpired action proposed after action, if any.
+// e.mu must be held.
+func (e *ToolExecutor) newerPending(action *PendingAction) *PendingAction {
+	var newest *PendingAction
+	for _, other := range e.pending {
+		if e.now().Sub(other.CreatedAt) > pendingActionTTL || !other.CreatedAt.After(action.CreatedAt) {
+			continue
+		}
+		if newest == nil || other.CreatedAt.After(newest.CreatedAt) {
+			newest = other
+		}
+	}
+	return newest
+}
+
+// notifyPending updates the client's view of a pending action
+func (e *ToolExecutor) notifyPending(action *PendingAction, status string) {
+	if e.onToolCall == nil {
+		return
+	}
+	e.onToolCall(models.ToolCallPayload{
+		ID:        action.ID,
+		Name:      action.Tool,
+		Arguments: action.Arguments,
+		Status:    status,
+		Summary:   action.Summary,
+	})
+}
+
+// summarizeBooking describes a book_appointment call
+func (e *ToolExecutor) summarizeBooking(args map[string]interface{}) string {
+	what := "an appointment"
+	if service, _ := e.findService(stringArg(args, "service_id")); service != nil {
+		what = fmt.Sprintf("a %s appointment", service.Name)
+	} else if purpose := stringArg(args, "purpose"); purpose != "" {
+		what = fmt.Sprintf("an appointment for %s", purpose)
+	}
+
+	summary := fmt.Sprintf("Book %s on %s", what, e.describeTime(stringArg(args, "date_time")))
+	if duration, ok := args["duration"].(float64); ok && duration > 0 {
+		summary += fmt.Sprintf(" for %d minutes", int(duration))
+	}
+	if provider := stringArg(args, "provider"); provider != "" && !strings.EqualFold(provider, "any") {
+		summary += " with " + provider
+	}
+	if rrule := stringArg(args, "recurrence"); rrule != "" {
+		summary += fmt.Sprintf(", repeating by %s", rrule)
+	}
+	return summary
+}
+
+// summarizeCancellation describes a cancel_appointment call
+func (e *ToolExecutor) summarizeCancellation(args map[string]interface{}) string {
+	appointment := e.ownAppointment(stringArg(args, "appointment_id"))
+	summary := "Cancel " + e.describeAppointment(appointment, args)
+	if acceptFee, _ := args["accept_fee"].(bool); acceptFee && appointment != nil {
+		if decision := e.cancelDecision(appointment, e.now()); decision.FeeCents > 0 {
+			summary += fmt.Sprintf(", with a late cancellation fee of %s", payment.FormatAmount(decision.FeeCents))
+		}
+	}
+	return summary
+}
+
+// summarizeModification describes a modify_appointment call
+func (e *ToolExecutor) summarizeModification(args map[string]interface{}) string {
+	var changes []string
+	if value := stringArg(args, "new_date_time"); value != "" {
+		changes = append(changes, "move it to "+e.describeTime(value))
+	}
+	if duration, ok := args["new_duration"].(float64); ok && duration > 0 {
+		changes = append(changes, fmt.Sprintf("make it %d minutes long", int(duration)))
+	}
+	if purpose := stringArg(args, "new_purpose"); purpose != "" {
+		changes = append(changes, fmt.Sprintf("change the purpose to %q", purpose))
+	}
+	if stringArg(args, "new_notes") != "" {
+		changes = append(changes, "update the notes")
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "leave it as it is")
+	}
+
+	appointment := e.ownAppointment(stringArg(args, "appointment_id"))
+	return fmt.Sprintf("Change %s: %s", e.describeAppointment(appointment, args), strings.Join(changes, ", "))
+}
+
+// ownAppointment returns the caller's appointment with the given ID, or nil
+// when it does not exist or belongs to someone else
+func (e *ToolExecutor) ownAppointment(id string) *models.Appointment {
+	if id == "" || e.userPhone == "" {
+		return nil
+	}
+	appointment, err := e.store.GetAppointmentByID(id)
+	if err != nil || appointment == nil || appointment.UserPhone != e.userPhone {
+		return nil
+	}
+	return appointment
+}
+
+// describeAppointment names an appointment and the part of its series that
+// args selects, e.g. "the Checkup on Monday, ... and all later occurrences"
+func (e *ToolExecutor) describeAppointment(appointment *models.Appointment, args map[string]interface{}) string {
+	if appointment == nil {
+		return fmt.Sprintf("appointment %s", stringArg(args, "appointment_id"))
+	}
+
+	what := "the appointment"
+	if appointment.Purpose != "" {
+		what = fmt.Sprintf("the %s appointment", appointment.Purpose)
+	}
+	description := fmt.Sprintf("%s on %s", what, e.formatDateTime(appointment.DateTime))
+	switch seriesScope(args, appointment) {
+	case scopeFollowing:
+		description += " and all later occurrences"
+	case scopeSeries:
+		description = "every occurrence in the series of " + description
+	}
+	return description
+}
+
+// describeTime reads a date_time argument back in the business's format
+// when it names an exact time, and as given otherwise
+func (e *ToolExecutor) describeTime(value string) string {
+	if res, err := e.resolve(value); err == nil && res.Exact {
+		return e.formatDateTime(res.Start)
+	}
+	return value
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	value, _ := args[key].(string)
+	return value
+}
+
+type reply int
+
+const (
+	replyUnclear reply = iota
+	replyYes
+	replyNo
+)
+
+// maxYesReplyWords is the longest reply a single yes word agrees in; longer
+// replies need a yes phrase, since the rest may change what was asked
+const maxYesReplyWords = 5
+
+var (
+	yesWords   = []string{"yes", "yeah", "yep", "yup", "yea", "correct", "confirm", "confirmed", "perfect", "absolutely", "definitely"}
+	yesPhrases = []string{"go ahead", "do it", "sounds good", "of course", "that works", "book it", "that's right", "that's correct", "please do"}
+	// negativeYesPhrases agree even though they are made of negative words
+	negativeYesPhrases = []string{"no problem", "no worries", "not a problem", "don't mind", "dont mind", "why not"}
+	// okWords agree only when the whole reply is made of them and yes words,
+	// as in "okay" or "sure, fine"; elsewhere they are filler
+	okWords = []string{"ok", "okay", "alright", "sure", "fine", "great", "right", "please"}
+	// noWords refuse when the reply opens with them; later on they only
+	// make the reply unclear, as in "yes, but not on Friday"
+	noWords       = []string{"no", "nope", "nah", "not", "don't", "dont", "wait", "stop", "wrong"}
+	noPhrases     = []string{"hold on", "never mind", "hang on"}
+	hedgeWords    = []string{"but", "except", "although", "though", "instead", "actually"}
+	questionWords = []string{"what", "when", "where", "which", "who", "why", "how", "can", "could", "would", "will", "is", "are", "should"}
+)
+
+// classifyReply decides whether the caller's words agree to a proposed
+// action. Only a reply that opens with a negation is a no; a yes with any
+// negation or hedge after it, or a question, is not an answer and the
+// caller is asked again.
+func classifyReply(text string) reply {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	joined := " " + strings.Join(words, " ") + " "
+
+	// "No problem" and "I don't mind" agree, so their words are not negations
+	agreed := false
+	for _, phrase := range negativeYesPhrases {
+		if strings.Contains(joined, " "+phrase+" ") {
+			joined = strings.ReplaceAll(joined, " "+phrase+" ", " ")
+			agreed = true
+		}
+	}
+	words = strings.Fields(joined)
+
+	if !agreed && (len(words) > 0 && containsWord(words[:1], noWords) || startsWithPhrase(joined, noPhrases)) {
+		return replyNo
+	}
+	if containsWord(words, noWords) || containsWord(words, hedgeWords) || containsPhrase(joined, noPhrases) {
+		return replyUnclear
+	}
+	if strings.Contains(text, "?") || (len(words) > 0 && containsWord(words[:1], questionWords)) {
+		return replyUnclear
+	}
+	if agreed || containsPhrase(joined, yesPhrases) {
+		return replyYes
+	}
+	if len(words) <= maxYesReplyWords && containsWord(words, yesWords) {
+		return replyYes
+	}
+	if len(words) > 0 && onlyWords(words, yesWords, okWords) {
+		return replyYes
+	}
+	return replyUnclear
+}
+
+// onlyWords reports whether every word is in one of the lists
+func onlyWords(words []string, lists ...[]string) bool {
+	for _, word := range words {
+		found := false
+		for _, list := range lists {
+			if containsWord([]string{word}, list) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsWord(words, list []string) bool {
+	for _, word := range words {
+		for _, candidate := range list {
+			if word == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsPhrase(joined string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(joined, " "+phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func startsWithPhrase(joined string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.HasPrefix(joined, " "+phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestChangesWaitForTheCallersConfirmation(t *testing.T) {
+	store := database.NewMemoryStore()
+	var statuses []string
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Now: testClock}, "session", func(p models.ToolCallPayload) {
+		statuses = append(statuses, p.Name+":"+p.Status)
+	}, nil)
+	executor.SetUserIdentity("+15550004444", "Careful Caller")
+
+	// Each step is a second after the last, so proposals are ordered
+	clock := testNow
+	executor.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	slot := testNow.Add(72 * time.Hour)
+
+	tests := []struct {
+		name     string
+		reply    string // what the caller says before the step, if anything
+		propose  int    // hours after slot to propose a booking at, or -1
+		token    int    // which proposal to confirm, when not proposing
+		success  bool
+		declined bool
+		booked   int
+	}{
+		{"a proposal", "", 0, 0, false, false, 0},
+		{"confirming before the caller answered", "", -1, 0, false, false, 0},
+		{"an unclear reply", "Hmm, what was the time again?", -1, 0, false, false, 0},
+		{"a no drops the action", "No, don't book that", -1, 0, false, true, 0},
+		{"a declined token is gone", "", -1, 0, false, false, 0},
+		{"the proposal again", "", 0, 0, false, false, 0},
+		{"a yes books it", "Yes, that's right", -1, 1, true, false, 1},
+		{"a token is used once", "", -1, 1, false, false, 1},
+		{"two more proposals", "", 2, 0, false, false, 1},
+		{"and another", "", 4, 0, false, false, 1},
+		{"a yes does not confirm the earlier one", "Yes", -1, 2, false, false, 1},
+		{"it confirms the latest", "", -1, 3, true, false, 2},
+		{"a used yes confirms nothing else", "", -1, 2, false, false, 2},
+		{"a new yes confirms the rest", "Yes, that one too", -1, 2, true, false, 3},
+	}
+	var tokens []string
+	var want []string
+	for _, tt := range tests {
+		if tt.reply != "" {
+			executor.RecordUserTurn(tt.reply)
+		}
+		if tt.propose >= 0 {
+			at := slot.Add(time.Duration(tt.propose) * time.Hour).Format(time.RFC3339)
+			result, err := executor.ExecuteTool(ToolBookAppointment, []byte(`{"date_time": "`+at+`", "purpose": "checkup"}`))
+			m := result.(map[string]interface{})
+			if err != nil || m["awaiting_confirmation"] != true || !strings.Contains(m["summary"].(string), "checkup") {
+				t.Fatalf("%s: expected a pending booking with a summary, got %v %v", tt.name, result, err)
+			}
+			tokens = append(tokens, `{"confirmation_token": "`+m["confirmation_token"].(string)+`"}`)
+			want = append(want, "book_appointment:pending")
+			continue
+		}
+
+		result, err := executor.ExecuteTool(ToolConfirmAction, []byte(tokens[tt.token]))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		if (m["declined"] == true) != tt.declined || (!tt.declined && m["success"] != tt.success) {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+		appointments, _ := store.GetAppointmentsByPhone("+15550004444")
+		if len(appointments) != tt.booked {
+			t.Errorf("%s: expected %d booked, got %d", tt.name, tt.booked, len(appointments))
+		}
+		want = append(want, "confirm_action:executing")
+		switch {
+		case tt.declined:
+			want = append(want, "book_appointment:declined")
+		case tt.success:
+			want = append(want, "book_appointment:confirmed")
+		}
+	}
+	if strings.Join(statuses, " ") != strings.Join(want, " ") {
+		t.Errorf("got tool_call events %v, want %v", statuses, want)
+	}
+}
+
+func TestClassifyReply(t *testing.T) {
+	tests := []struct {
+		text string
+		want reply
+	}{
+		{"Yes", replyYes},
+		{"Yeah, go ahead.", replyYes},
+		{"Yes, that's right", replyYes},
+		{"Okay", replyYes},
+		{"Sure, fine.", replyYes},
+		{"Perfect, thank you", replyYes},
+		{"Sounds good, book it for me on that day then please", replyYes},
+		{"No", replyNo},
+		{"No, don't book that", replyNo},
+		{"Nope, not that one", replyNo},
+		{"Hold on a second", replyNo},
+		{"Never mind", replyNo},
+		{"Yes, no problem", replyYes},
+		{"Sure, I don't mind", replyYes},
+		{"No problem", replyYes},
+		{"Why not", replyYes},
+		{"Yes but quickly", replyUnclear},
+		{"Yes, but make it four instead", replyUnclear},
+		{"Yeah, not sure about the time though", replyUnclear},
+		{"No problem, but wait a second", replyUnclear},
+		{"Is that with Dr. Smith?", replyUnclear},
+		{"Yes, is that in the morning", replyUnclear},
+		{"Okay, and what time was it?", replyUnclear},
+		{"Right, I was also thinking about moving my other appointment", replyUnclear},
+		{"Please tell me the price first", replyUnclear},
+		{"Great weather today", replyUnclear},
+		{"Yeah I guess I need to check my calendar for that week", replyUnclear},
+		{"", replyUnclear},
+	}
+	for _, tt := range tests {
+		if got := classifyReply(tt.text); got != tt.want {
+			t.Errorf("classifyReply(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestOverlappingTurnsConfirmOnce runs turns the way the agent can, with the
+// next transcript recorded while the last one is still calling tools. Run it
+// with -race.
+func TestOverlappingTurnsConfirmOnce(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550004747", "Quick Caller")
+
+	slot := testNow.Add(72 * time.Hour)
+	result, err := executor.ExecuteTool(ToolBookAppointment, []byte(`{"date_time": "`+slot.Format(time.RFC3339)+`"}`))
+	if err != nil || result.(map[string]interface{})["confirmation_token"] == nil {
+		t.Fatalf("expected a pending booking, got %v %v", result, err)
+	}
+	token := []byte(`{"confirmation_token": "` + result.(map[string]interface{})["confirmation_token"].(string) + `"}`)
+
+	// Every turn says yes to the same proposal at once
+	const turns = 8
+	var wg sync.WaitGroup
+	for i := 0; i < turns; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			executor.RecordUserTurn("Yes")
+			if _, err := executor.ExecuteTool(ToolConfirmAction, token); err != nil {
+				t.Errorf("turn %d: confirm: %v", i, err)
+			}
+			executor.PendingActions()
+		}(i)
+	}
+	wg.Wait()
+
+	appointments, _ := store.GetAppointmentsByPhone("+15550004747")
+	if len(appointments) != 1 || !appointments[0].DateTime.Equal(slot) {
+		t.Fatalf("expected the proposal booked exactly once, got %+v", appointments)
+	}
+
+	// Every turn proposes something new while the caller keeps talking
+	for i := 0; i < turns; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			later := slot.Add(time.Duration(i+1) * time.Hour).Format(time.RFC3339)
+			if _, err := executor.ExecuteTool(ToolBookAppointment, []byte(`{"date_time": "`+later+`"}`)); err != nil {
+				t.Errorf("turn %d: propose: %v", i, err)
+			}
+			executor.RecordUserTurn("Hmm")
+		}(i)
+	}
+	wg.Wait()
+
+	if pending := executor.PendingActions(); len(pending) != turns {
+		t.Fatalf("expected %d pending actions, got %d", turns, len(pending))
+	}
+}
+
+func TestNewPendingActionExpiresStaleOnes(t *testing.T) {
+	store := database.NewMemoryStore()
+	var statuses []string
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Now: testClock}, "session", func(p models.ToolCallPayload) {
+		statuses = append(statuses, p.ID+":"+p.Status)
+	}, nil)
+	executor.SetUserIdentity("+15550004646", "Slow Caller")
+
+	tool, _ := executor.registry.Lookup(ToolBookAppointment)
+	confirmed := tool.(ConfirmedTool)
+	args := map[string]interface{}{"date_time": "2026-03-10T10:00:00Z"}
+	executor.holdForConfirmation(confirmed, args, "stale")
+	executor.pending["stale"].CreatedAt = testNow.Add(-pendingActionTTL - time.Minute)
+	executor.holdForConfirmation(confirmed, args, "fresh")
+
+	if _, ok := executor.pending["stale"]; ok || len(executor.pending) != 1 {
+		t.Fatalf("expected only the fresh action to be kept, got %v", executor.pending)
+	}
+	if want := "stale:pending stale:expired fresh:pending"; strings.Join(statuses, " ") != want {
+		t.Fatalf("got tool_call events %v, want %s", statuses, want)
+	}
+}
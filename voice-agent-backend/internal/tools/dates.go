@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/datetime"
+)
+
+// resolveDateTime turns the caller's words for a date or time into a
+// concrete one in business time, so the LLM never does calendar arithmetic
+func (e *ToolExecutor) resolveDateTime(args map[string]interface{}) (interface{}, error) {
+	expression, _ := args["expression"].(string)
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+
+	resolved, err := e.resolve(expression)
+	if err != nil {
+		log.Printf("[resolveDateTime] Cannot resolve '%s': %v", expression, err)
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Could not work out %q: %s. Ask the caller to say the date another way.", expression, err),
+		}, nil
+	}
+
+	loc := e.slots.Schedule().Location
+	start, end := resolved.Start.In(loc), resolved.End.In(loc)
+	result := map[string]interface{}{
+		"success":    true,
+		"expression": expression,
+		"exact":      resolved.Exact,
+		"date":       start.Format("2006-01-02"),
+		"weekday":    start.Weekday().String(),
+	}
+
+	switch {
+	case resolved.Exact:
+		result["date_time"] = start.Format(localLayout)
+		result["message"] = fmt.Sprintf("%s is %s", expression, e.formatDateTime(start))
+	case end.Sub(start) <= 25*time.Hour:
+		result["from"] = start.Format(localLayout)
+		result["to"] = end.Format(localLayout)
+		result["message"] = fmt.Sprintf("%s is %s", expression, start.Format("Monday, January 2, 2006"))
+		if resolved.Part != "" {
+			result["part_of_day"] = resolved.Part
+			result["message"] = fmt.Sprintf("%s is %s between %s and %s", expression, start.Format("Monday, January 2, 2006"), e.slots.Schedule().FormatTime(start), e.slots.Schedule().FormatTime(end))
+		}
+	default:
+		last := end.AddDate(0, 0, -1)
+		result["from"] = start.Format(localLayout)
+		result["to"] = end.Format(localLayout)
+		result["message"] = fmt.Sprintf("%s runs from %s to %s", expression, start.Format("Monday, January 2"), last.Format("Monday, January 2, 2006"))
+	}
+	return result, nil
+}
+
+// localLayout is how resolved times are handed back to the LLM: business
+// local time without an offset, ready to pass to the other tools
+const localLayout = "2006-01-02T15:04:05"
+
+// resolve reads an ISO 8601 or spoken date and time relative to now in the
+// business timezone
+func (e *ToolExecutor) resolve(value string) (*datetime.Resolution, error) {
+	return datetime.Resolve(value, e.now(), e.slots.Schedule().Location)
+}
+
+// parseDate reads a YYYY-MM-DD or spoken date, returning midnight of the day
+// it starts on along with what the words resolved to
+func (e *ToolExecutor) parseDate(value string) (time.Time, *datetime.Resolution, error) {
+	resolved, err := e.resolve(value)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	y, m, d := resolved.Start.In(e.slots.Schedule().Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, e.slots.Schedule().Location), resolved, nil
+}
+
+// parseDateTime reads an ISO 8601 or spoken time, which has to name a time of
+// day rather than just a day
+func (e *ToolExecutor) parseDateTime(value string) (time.Time, error) {
+	resolved, err := e.resolve(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !resolved.Exact {
+		return time.Time{}, fmt.Errorf("%q does not say what time of day", value)
+	}
+	return resolved.Start, nil
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestSpokenDatesResolveAndBook(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550006666", "Caller")
+
+	tomorrow := testNow.AddDate(0, 0, 1)
+	nextWeek := testNow.AddDate(0, 0, 7)
+	wrongDay := (nextWeek.Weekday() + 1) % 7
+
+	tests := []struct {
+		name    string
+		tool    func(map[string]interface{}) (interface{}, error)
+		args    map[string]interface{}
+		wantErr bool
+		want    map[string]interface{}
+		from    string    // suffix of the resolved start
+		booked  time.Time // when the appointment was booked for
+	}{
+		{"a weekday and part of the day", executor.resolveDateTime,
+			map[string]interface{}{"expression": "next Tuesday afternoon"}, false,
+			map[string]interface{}{"success": true, "weekday": "Tuesday", "exact": false, "part_of_day": "afternoon"}, "T12:00:00", time.Time{}},
+		// A date and weekday that disagree are reported rather than guessed
+		{"a date on the wrong weekday", executor.resolveDateTime,
+			map[string]interface{}{"expression": wrongDay.String() + " " + nextWeek.Format("January 2")}, false,
+			map[string]interface{}{"success": false}, "", time.Time{}},
+		{"slots for a spoken day", executor.fetchSlots,
+			map[string]interface{}{"date": "tomorrow afternoon"}, false,
+			map[string]interface{}{"date": tomorrow.Format("2006-01-02"), "total_slots": 10}, "", time.Time{}},
+		{"booking a spoken time", executor.bookAppointment,
+			map[string]interface{}{"date_time": "tomorrow at half past two"}, false,
+			map[string]interface{}{"success": true}, "", tomorrow.Add(14*time.Hour + 30*time.Minute)},
+		{"booking without a time of day", executor.bookAppointment,
+			map[string]interface{}{"date_time": "next week"}, true, nil, "", time.Time{}},
+	}
+	for _, tt := range tests {
+		result, err := tt.tool(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", tt.name, tt.wantErr, err)
+		}
+		if tt.wantErr {
+			continue
+		}
+		m := result.(map[string]interface{})
+		for key, want := range tt.want {
+			if m[key] != want {
+				t.Errorf("%s: %s is %v, want %v in %v", tt.name, key, m[key], want, m)
+			}
+		}
+		if from, _ := m["from"].(string); !strings.HasSuffix(from, tt.from) {
+			t.Errorf("%s: expected the range to start at %s, got %q", tt.name, tt.from, from)
+		}
+		if !tt.booked.IsZero() {
+			apt, _ := store.GetAppointmentByID(m["appointment_id"].(string))
+			if apt == nil || !apt.DateTime.Equal(tt.booked) {
+				t.Errorf("%s: expected the appointment at %s, got %+v", tt.name, tt.booked, apt)
+			}
+		}
+	}
+}
@@ -1,212 +1,400 @@
 package tools
 
-import (
-	"github.com/sashabaranov/go-openai"
-)
-
-// GetToolDefinitions returns all available tool definitions for the LLM
-func GetToolDefinitions() []openai.Tool {
-	return []openai.Tool{
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "identify_user",
-				Description: "Identify the user by their phone number, name, and email. Use this when you need to know who you're speaking with or before booking/retrieving appointments. All three fields are required.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"phone_number": map[string]interface{}{
-							"type":        "string",
-							"description": "The user's phone number in format like +1234567890 or 1234567890",
-						},
-						"name": map[string]interface{}{
-							"type":        "string",
-							"description": "The user's full name (cannot be empty or 'null')",
-						},
-						"email": map[string]interface{}{
-							"type":        "string",
-							"description": "The user's email address in format user@domain.com (cannot be empty or 'null')",
-						},
-					},
-					"required": []string{"phone_number", "name", "email"},
+// builtinTools are the tools every session has, in the order the LLM sees them
+func builtinTools() []Tool {
+	return []Tool{
+		NewTool(
+			ToolIdentifyUser,
+			"Identify the user by their phone number, name, and email. Use this when you need to know who you're speaking with or before booking/retrieving appointments. All three fields are required.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"phone_number": map[string]interface{}{
+						"type":        "string",
+						"description": "The user's phone number in format like +1234567890 or 1234567890",
+					},
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "The user's full name (cannot be empty or 'null')",
+					},
+					"email": map[string]interface{}{
+						"type":        "string",
+						"description": "The user's email address in format user@domain.com (cannot be empty or 'null')",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional IANA timezone of the user if they mention where they are calling from (e.g., America/New_York)",
+					},
+				},
+				"required": []string{"phone_number", "name", "email"},
+			},
+			(*ToolExecutor).identifyUser,
+		),
+		NewTool(
+			ToolResolveDateTime,
+			"Turn what the caller said about a date or time (e.g. \"next Tuesday afternoon\", \"the 3rd at half past two\", \"in two weeks\") into the exact date, weekday and time in business time. Use it instead of working out dates yourself.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"expression": map[string]interface{}{
+						"type":        "string",
+						"description": "The date and/or time in the caller's words",
+					},
+				},
+				"required": []string{"expression"},
+			},
+			(*ToolExecutor).resolveDateTime,
+		),
+		NewTool(
+			ToolFetchSlots,
+			"Fetch available appointment time slots for a given date within business hours. Returns list of available times and, if the business has several providers, who is free at each time.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "The date to check availability for, in YYYY-MM-DD format or the caller's words (e.g. \"tomorrow\", \"next Tuesday afternoon\"). A part of the day only returns slots in it",
+					},
+					"duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "Desired appointment length in minutes (optional, defaults to the standard appointment length). Leave it out with service_id, which sets the length",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "Name or ID of the staff member or room to check (optional). Omit or use \"any\" to see times when anyone is free",
+					},
+					"service_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of a service from list_services (optional). Uses the service's duration and only its providers",
+					},
+				},
+				"required": []string{"date"},
+			},
+			(*ToolExecutor).fetchSlots,
+		),
+		NewTool(
+			ToolFindNextAvailable,
+			"Find the soonest available appointment times across several days. Use this when the caller wants the earliest slot or is flexible about the date, instead of calling fetch_slots day by day.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"earliest_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Earliest date to consider, in YYYY-MM-DD format or words like \"next week\" (optional, defaults to today)",
+					},
+					"weekdays": map[string]interface{}{
+						"type":        "string",
+						"description": "Acceptable days, e.g. \"mon-fri\" or \"tue,thu\" (optional, any open day if omitted)",
+					},
+					"time_window": map[string]interface{}{
+						"type":        "string",
+						"description": "Acceptable times of day in business local time, e.g. \"09:00-12:00\" or \"09:00-12:00,14:00-17:00\" (optional)",
+					},
+					"duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "Appointment duration in minutes (optional). Leave it out with service_id, which sets the length",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "Name or ID of the staff member or room (optional). Omit or use \"any\" for whoever is free",
+					},
+					"service_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the service from list_services (optional). Sets the duration",
+					},
+					"count": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many options to return (optional, default 3)",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many days ahead to search (optional, default 14, max 90)",
+					},
+				},
+				"required": []string{},
+			},
+			(*ToolExecutor).findNextAvailable,
+		),
+		NewTool(
+			ToolListServices,
+			"List the services the business offers, with their duration, price and who provides them. Use this when the user asks what they can book or before booking a specific kind of appointment.",
+			map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+				"required":   []string{},
+			},
+			(*ToolExecutor).listServices,
+		),
+		NewTool(
+			ToolHoldSlot,
+			"Hold a time slot for a few minutes while the user confirms the details, so no other caller can take it. book_appointment for the same time uses the hold. Holding another time replaces the previous hold, and holds are released when the call ends.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date_time": map[string]interface{}{
+						"type":        "string",
+						"description": "The slot's date and time in ISO 8601 format, in business local time without an offset (e.g., 2024-01-15T10:00:00), or the caller's words naming a time (e.g. \"tomorrow at 3pm\")",
+					},
+					"duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "Duration of the appointment in minutes (optional, defaults to the standard appointment length). Leave it out with service_id, which sets the length",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "Name or ID of the staff member or room (optional). Omit or use \"any\" to hold whoever is free",
+					},
+					"service_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the service from list_services (optional)",
+					},
+				},
+				"required": []string{"date_time"},
+			},
+			(*ToolExecutor).holdSlot,
+		),
+		NewConfirmedTool(
+			ToolBookAppointment,
+			"Book an appointment for the user. Requires user to be identified first. Pass recurrence to book a repeating series; occurrences that conflict are reported and skipped. Bookings the caller's limits refuse come back with a guard explaining why. Nothing changes until the caller agrees to the returned summary and you call confirm_action.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date_time": map[string]interface{}{
+						"type":        "string",
+						"description": "The appointment date and time in ISO 8601 format, in business local time without an offset (e.g., 2024-01-15T10:00:00), or the caller's words naming a time (e.g. \"next Tuesday at 2:30pm\")",
+					},
+					"duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "Duration of the appointment in minutes (optional, defaults to the standard appointment length). Leave it out with service_id, which sets the length",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "Name or ID of the staff member or room to book with (optional). Omit or use \"any\" to book whoever is free",
+					},
+					"service_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the service being booked, from list_services (optional). Sets the duration and price automatically",
+					},
+					"purpose": map[string]interface{}{
+						"type":        "string",
+						"description": "The purpose or reason for the appointment",
+					},
+					"notes": map[string]interface{}{
+						"type":        "string",
+						"description": "Any additional notes for the appointment",
+					},
+					"recurrence": map[string]interface{}{
+						"type":        "string",
+						"description": "iCalendar RRULE for a recurring series starting at date_time (optional), e.g. FREQ=WEEKLY;BYDAY=TU;COUNT=6 or FREQ=WEEKLY;INTERVAL=2;UNTIL=20250630. FREQ may be DAILY, WEEKLY or MONTHLY and COUNT or UNTIL is required",
+					},
+					"confirm_duplicate": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Set to true only after the caller has confirmed they want another appointment despite the similar one reported by a previous attempt",
+					},
 				},
+				"required": []string{"date_time"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "fetch_slots",
-				Description: "Fetch available appointment time slots for a given date. Returns list of available times.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"date": map[string]interface{}{
-							"type":        "string",
-							"description": "The date to check availability for in YYYY-MM-DD format",
-						},
-					},
-					"required": []string{"date"},
+			(*ToolExecutor).bookAppointment,
+			(*ToolExecutor).summarizeBooking,
+		),
+		NewTool(
+			ToolJoinWaitlist,
+			"Add the identified user to the waitlist when the time they want is booked. If a matching appointment is cancelled, the slot is held for them and they are notified.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from_date": map[string]interface{}{
+						"type":        "string",
+						"description": "First acceptable date in YYYY-MM-DD format",
+					},
+					"to_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Last acceptable date in YYYY-MM-DD format (optional, defaults to from_date)",
+					},
+					"preferred_times": map[string]interface{}{
+						"type":        "string",
+						"description": "Acceptable times of day in business local time, e.g. \"09:00-12:00\" or \"09:00-12:00,14:00-17:00\" (optional, any time if omitted)",
+					},
+					"duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "Appointment duration in minutes (optional). Leave it out with service_id, which sets the length",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "Name or ID of the staff member or room they want (optional)",
+					},
+					"service_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the service from list_services (optional)",
+					},
+				},
+				"required": []string{"from_date"},
+			},
+			(*ToolExecutor).joinWaitlist,
+		),
+		NewTool(
+			ToolRetrieveAppointments,
+			"Retrieve the user's appointments. Can fetch upcoming appointments or all past appointments.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"upcoming", "all"},
+						"description": "Type of appointments to retrieve: 'upcoming' for future appointments, 'all' for all appointments",
+					},
 				},
+				"required": []string{"type"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "book_appointment",
-				Description: "Book an appointment for the user. Requires user to be identified first.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"date_time": map[string]interface{}{
-							"type":        "string",
-							"description": "The appointment date and time in ISO 8601 format (e.g., 2024-01-15T10:00:00Z)",
-						},
-						"duration": map[string]interface{}{
-							"type":        "integer",
-							"description": "Duration of the appointment in minutes (default 30)",
-						},
-						"purpose": map[string]interface{}{
-							"type":        "string",
-							"description": "The purpose or reason for the appointment",
-						},
-						"notes": map[string]interface{}{
-							"type":        "string",
-							"description": "Any additional notes for the appointment",
-						},
-					},
-					"required": []string{"date_time"},
+			(*ToolExecutor).retrieveAppointments,
+		),
+		NewConfirmedTool(
+			ToolCancelAppointment,
+			"Cancel an existing appointment by its ID. For a recurring appointment, scope selects this occurrence, this and following, or the whole series. Nothing changes until the caller agrees to the returned summary and you call confirm_action. If the cancellation policy charges a fee and accept_fee is not set, confirming returns fee_required with the fee instead of cancelling; once the caller accepts the fee, call again with accept_fee.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"appointment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID of the appointment to cancel",
+					},
+					"reason": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional reason for cancellation",
+					},
+					"accept_fee": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Set to true only after the caller has agreed to the late cancellation fee reported by a previous attempt",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"this", "following", "series"},
+						"description": "For recurring appointments: 'this' occurrence only (default), 'following' for this and all later occurrences, or the whole 'series'",
+					},
 				},
+				"required": []string{"appointment_id"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "retrieve_appointments",
-				Description: "Retrieve the user's appointments. Can fetch upcoming appointments or all past appointments.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"type": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"upcoming", "all"},
-							"description": "Type of appointments to retrieve: 'upcoming' for future appointments, 'all' for all appointments",
-						},
-					},
-					"required": []string{"type"},
+			(*ToolExecutor).cancelAppointment,
+			(*ToolExecutor).summarizeCancellation,
+		),
+		NewConfirmedTool(
+			ToolModifyAppointment,
+			"Modify an existing appointment's date, time, or details. For a recurring appointment, scope selects this occurrence, this and following, or the whole series; a new time moves each selected occurrence by the same number of days to the new time of day. Nothing changes until the caller agrees to the returned summary and you call confirm_action.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"appointment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID of the appointment to modify",
+					},
+					"new_date_time": map[string]interface{}{
+						"type":        "string",
+						"description": "New date and time in ISO 8601 format, in business local time without an offset, or the caller's words naming a time (optional)",
+					},
+					"new_duration": map[string]interface{}{
+						"type":        "integer",
+						"description": "New duration in minutes (optional)",
+					},
+					"new_purpose": map[string]interface{}{
+						"type":        "string",
+						"description": "New purpose/reason (optional)",
+					},
+					"new_notes": map[string]interface{}{
+						"type":        "string",
+						"description": "New notes (optional)",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"this", "following", "series"},
+						"description": "For recurring appointments: 'this' occurrence only (default), 'following' for this and all later occurrences, or the whole 'series'",
+					},
 				},
+				"required": []string{"appointment_id"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "cancel_appointment",
-				Description: "Cancel an existing appointment by its ID.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"appointment_id": map[string]interface{}{
-							"type":        "string",
-							"description": "The ID of the appointment to cancel",
-						},
-						"reason": map[string]interface{}{
-							"type":        "string",
-							"description": "Optional reason for cancellation",
-						},
-					},
-					"required": []string{"appointment_id"},
+			(*ToolExecutor).modifyAppointment,
+			(*ToolExecutor).summarizeModification,
+		),
+		NewTool(
+			ToolConfirmAction,
+			"Carry out a booking, cancellation or change that is waiting for the caller's agreement. Only call this after the caller has said yes to the summary you read them.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"confirmation_token": map[string]interface{}{
+						"type":        "string",
+						"description": "The confirmation_token returned when the action was proposed",
+					},
 				},
+				"required": []string{"confirmation_token"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "modify_appointment",
-				Description: "Modify an existing appointment's date, time, or details.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"appointment_id": map[string]interface{}{
-							"type":        "string",
-							"description": "The ID of the appointment to modify",
-						},
-						"new_date_time": map[string]interface{}{
-							"type":        "string",
-							"description": "New date and time in ISO 8601 format (optional)",
-						},
-						"new_duration": map[string]interface{}{
-							"type":        "integer",
-							"description": "New duration in minutes (optional)",
-						},
-						"new_purpose": map[string]interface{}{
-							"type":        "string",
-							"description": "New purpose/reason (optional)",
-						},
-						"new_notes": map[string]interface{}{
-							"type":        "string",
-							"description": "New notes (optional)",
-						},
-					},
-					"required": []string{"appointment_id"},
+			(*ToolExecutor).confirmAction,
+		),
+		NewTool(
+			ToolConfirmAppointment,
+			"Confirm that the user will attend an upcoming appointment, e.g. when they reply to a reminder.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"appointment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID of the appointment to confirm",
+					},
 				},
+				"required": []string{"appointment_id"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "end_conversation",
-				Description: "End the current conversation. Use this when the user says goodbye, wants to end the call, or the conversation has naturally concluded.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"reason": map[string]interface{}{
-							"type":        "string",
-							"description": "Reason for ending the conversation",
-						},
-					},
-					"required": []string{},
+			(*ToolExecutor).confirmAppointment,
+		),
+		NewTool(
+			ToolProcessPayment,
+			"Take payment for one of the user's appointments. The amount is the appointment's price and is worked out for you. Only call this when the user wants to pay now.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"appointment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID of the appointment to pay for",
+					},
+					"payment_token": map[string]interface{}{
+						"type":        "string",
+						"description": "Card token from the client's payment form, if it sent one. Without it the user is shown a payment form to finish the payment.",
+					},
 				},
+				"required": []string{"appointment_id"},
 			},
-		},
-		{
-			Type: openai.ToolTypeFunction,
-			Function: &openai.FunctionDefinition{
-				Name:        "process_payment",
-				Description: "Process payment for an appointment booking. Returns payment details and confirmation.",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"appointment_id": map[string]interface{}{
-							"type":        "string",
-							"description": "The appointment ID to pay for",
-						},
-						"amount_cents": map[string]interface{}{
-							"type":        "integer",
-							"description": "The amount in cents (e.g., 1500 for $15.00)",
-						},
-						"payment_method": map[string]interface{}{
-							"type":        "string",
-							"description": "Payment method (card, stripe_token, etc.)",
-						},
-						"description": map[string]interface{}{
-							"type":        "string",
-							"description": "Payment description for the transaction",
-						},
-					},
-					"required": []string{"appointment_id", "amount_cents", "payment_method"},
+			(*ToolExecutor).processPayment,
+		),
+		NewTool(
+			ToolEndConversation,
+			"End the current conversation. Use this when the user says goodbye, wants to end the call, or the conversation has naturally concluded.",
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"reason": map[string]interface{}{
+						"type":        "string",
+						"description": "Reason for ending the conversation",
+					},
 				},
+				"required": []string{},
 			},
-		},
+			(*ToolExecutor).endConversation,
+		),
 	}
 }
 
 // ToolNames for easy reference
 const (
 	ToolIdentifyUser         = "identify_user"
+	ToolResolveDateTime      = "resolve_datetime"
 	ToolFetchSlots           = "fetch_slots"
+	ToolFindNextAvailable    = "find_next_available"
+	ToolHoldSlot             = "hold_slot"
 	ToolBookAppointment      = "book_appointment"
 	ToolRetrieveAppointments = "retrieve_appointments"
 	ToolCancelAppointment    = "cancel_appointment"
 	ToolModifyAppointment    = "modify_appointment"
-	ToolEndConversation      = "end_conversation"
+	ToolConfirmAction        = "confirm_action"
+	ToolConfirmAppointment   = "confirm_appointment"
 	ToolProcessPayment       = "process_payment"
+	ToolListServices         = "list_services"
+	ToolJoinWaitlist         = "join_waitlist"
+	ToolEndConversation      = "end_conversation"
 )
@@ -1,30 +1,88 @@
 package tools
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/calendar"
 	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/lifecycle"
 	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/policy"
+	"github.com/voice-agent/backend/internal/services/payment"
+	"github.com/voice-agent/backend/internal/waitlist"
 	"github.com/voice-agent/backend/pkg/utils"
 )
 
 // ToolExecutor handles the execution of tool calls
 type ToolExecutor struct {
+	store        database.Store
+	slots        *availability.Engine
+	waitlist     *waitlist.Service       // nil disables the waitlist
+	calendar     *calendar.Feeds         // nil leaves calendar links out of results
+	policy       *policy.Engine          // nil allows any change without fees
+	payments     *payment.PaymentService // nil when Stripe is not configured
+	now          func() time.Time
+	registry     *Registry
 	sessionID    string
 	userPhone    string
 	userName     string
+	userLocation *time.Location // caller's timezone, nil when unknown
+	// mu guards the confirmation state below, since a new user turn can be
+	// recorded while the previous one is still running tools
+	mu           sync.Mutex
+	pending      map[string]*PendingAction
+	userTurns    int
+	lastReply    string
+	answeredTurn int // user turn whose reply last confirmed or declined an action
 	onToolCall   func(payload models.ToolCallPayload)
 	onToolResult func(payload models.ToolResultPayload)
 }
 
+// ClientResult lets a tool hand the client data the LLM must not see, such
+// as a payment client secret. Result goes to the LLM, Client only to the
+// tool_result event.
+type ClientResult struct {
+	Result interface{}
+	Client map[string]interface{}
+}
+
+// Services are what a ToolExecutor works with, shared by every session.
+// Store and Slots are required; leaving any other one nil turns its feature
+// off.
+type Services struct {
+	Store    database.Store
+	Slots    *availability.Engine
+	Waitlist *waitlist.Service
+	Calendar *calendar.Feeds
+	Policy   *policy.Engine
+	Payments *payment.PaymentService
+	Now      func() time.Time // nil uses time.Now; tests fix the clock
+}
+
 // NewToolExecutor creates a new tool executor for a session
-func NewToolExecutor(sessionID string, onToolCall func(models.ToolCallPayload), onToolResult func(models.ToolResultPayload)) *ToolExecutor {
+func NewToolExecutor(services Services, sessionID string, onToolCall func(models.ToolCallPayload), onToolResult func(models.ToolResultPayload)) *ToolExecutor {
+	now := services.Now
+	if now == nil {
+		now = time.Now
+	}
 	return &ToolExecutor{
+		store:        services.Store,
+		slots:        services.Slots,
+		waitlist:     services.Waitlist,
+		calendar:     services.Calendar,
+		policy:       services.Policy,
+		payments:     services.Payments,
+		now:          now,
+		registry:     defaultRegistry,
 		sessionID:    sessionID,
 		onToolCall:   onToolCall,
 		onToolResult: onToolResult,
@@ -37,6 +95,18 @@ func (e *ToolExecutor) SetUserIdentity(phone, name string) {
 	e.userName = name
 }
 
+// SetUserTimezone records the caller's IANA timezone so confirmations can
+// include their local time. An empty or unknown name clears it.
+func (e *ToolExecutor) SetUserTimezone(name string) {
+	e.userLocation = nil
+	if name == "" {
+		return
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		e.userLocation = loc
+	}
+}
+
 // GetUserPhone returns the current user's phone
 func (e *ToolExecutor) GetUserPhone() string {
 	return e.userPhone
@@ -49,15 +119,28 @@ func (e *ToolExecutor) GetUserName() string {
 
 // ExecuteTool executes a tool call and returns the result
 func (e *ToolExecutor) ExecuteTool(toolName string, arguments json.RawMessage) (interface{}, error) {
-	var args map[string]interface{}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	// Malformed arguments are reported like any other invalid argument
+	var problems []FieldError
+	args := map[string]interface{}{}
+	if len(bytes.TrimSpace(arguments)) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil || args == nil {
+			args = map[string]interface{}{}
+			problems = []FieldError{{Field: "arguments", Error: "must be a JSON object"}}
+		}
 	}
 
 	toolCallID := uuid.New().String()
 
-	// Notify tool call started
-	if e.onToolCall != nil {
+	tool, ok := e.registry.Lookup(toolName)
+	if ok && problems == nil {
+		problems = validateArgs(tool.Parameters(), args)
+	}
+	// Valid calls that change bookings wait for the caller's agreement
+	confirmed, gated := tool.(ConfirmedTool)
+	gated = gated && len(problems) == 0
+
+	// Notify tool call started; pending actions are announced with their summary
+	if e.onToolCall != nil && !gated {
 		e.onToolCall(models.ToolCallPayload{
 			ID:        toolCallID,
 			Name:      toolName,
@@ -69,23 +152,21 @@ func (e *ToolExecutor) ExecuteTool(toolName string, arguments json.RawMessage) (
 	var result interface{}
 	var err error
 
-	switch toolName {
-	case ToolIdentifyUser:
-		result, err = e.identifyUser(args)
-	case ToolFetchSlots:
-		result, err = e.fetchSlots(args)
-	case ToolBookAppointment:
-		result, err = e.bookAppointment(args)
-	case ToolRetrieveAppointments:
-		result, err = e.retrieveAppointments(args)
-	case ToolCancelAppointment:
-		result, err = e.cancelAppointment(args)
-	case ToolModifyAppointment:
-		result, err = e.modifyAppointment(args)
-	case ToolEndConversation:
-		result, err = e.endConversation(args)
-	default:
+	switch {
+	case !ok:
 		err = fmt.Errorf("unknown tool: %s", toolName)
+	case len(problems) > 0:
+		log.Printf("[ExecuteTool] Invalid arguments for %s: %v", toolName, problems)
+		result = invalidArguments(toolName, problems)
+	case gated:
+		result = e.holdForConfirmation(confirmed, args, toolCallID)
+	default:
+		result, err = tool.Execute(e, args)
+	}
+
+	var client map[string]interface{}
+	if cr, ok := result.(ClientResult); ok {
+		result, client = cr.Result, cr.Client
 	}
 
 	// Notify tool result
@@ -94,6 +175,7 @@ func (e *ToolExecutor) ExecuteTool(toolName string, arguments json.RawMessage) (
 			ID:     toolCallID,
 			Name:   toolName,
 			Result: result,
+			Client: client,
 		}
 		if err != nil {
 			payload.Error = err.Error()
@@ -112,11 +194,24 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 
 	name, _ := args["name"].(string)
 	email, _ := args["email"].(string)
+	timezone, _ := args["timezone"].(string)
 
 	// Clean and normalize inputs
 	phone = strings.TrimSpace(phone)
 	name = strings.TrimSpace(name)
 	email = strings.TrimSpace(email)
+	timezone = strings.TrimSpace(timezone)
+
+	// An unknown timezone must not block identification, so drop it and
+	// keep showing times in business time
+	warning := ""
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			log.Printf("[identifyUser] WARNING: Ignoring invalid timezone '%s': %v", timezone, err)
+			warning = fmt.Sprintf("Timezone %q was not recognised, so times are in the business timezone (%s). Ask for an IANA name such as America/New_York if the caller wants their own.", timezone, e.slots.Schedule().Location)
+			timezone = ""
+		}
+	}
 
 	// Log raw inputs for debugging
 	log.Printf("[identifyUser] Raw inputs - Phone: '%s', Name: '%s', Email: '%s'", phone, name, email)
@@ -132,7 +227,7 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 	log.Printf("[identifyUser] Phone validated and normalized to: %s", phone)
 
 	// Check if user already exists
-	existingUser, err := database.DB.GetUserByPhone(phone)
+	existingUser, err := e.store.GetUserByPhone(phone)
 	if err != nil {
 		log.Printf("[identifyUser] ERROR: Failed to check if user exists: %v", err)
 		return nil, fmt.Errorf("failed to check user: %w", err)
@@ -142,18 +237,28 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 	if existingUser != nil {
 		log.Printf("[identifyUser] User already exists - Phone: %s, Name: %s, Email: %s", existingUser.PhoneNumber, existingUser.Name, existingUser.Email)
 
+		// Remember a newly provided timezone
+		if timezone != "" && timezone != existingUser.Timezone {
+			existingUser.Timezone = timezone
+			if err := e.store.UpdateUser(existingUser); err != nil {
+				log.Printf("[identifyUser] WARNING: Failed to save timezone: %v", err)
+			}
+		}
+
 		// Set identity and return existing user
 		e.SetUserIdentity(phone, existingUser.Name)
+		e.SetUserTimezone(existingUser.Timezone)
 
-		return map[string]interface{}{
+		return withWarning(map[string]interface{}{
 			"success":      true,
 			"user_id":      existingUser.ID,
 			"phone_number": existingUser.PhoneNumber,
 			"name":         existingUser.Name,
 			"email":        existingUser.Email,
+			"timezone":     existingUser.Timezone,
 			"is_new_user":  false,
 			"message":      fmt.Sprintf("Welcome back, %s! Successfully identified using your phone number.", existingUser.Name),
-		}, nil
+		}, warning), nil
 	}
 
 	// User does NOT exist - require name and email for new registration
@@ -188,7 +293,7 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 	log.Printf("[identifyUser] Email validated and normalized to: %s", email)
 
 	// Check if user exists
-	user, err := database.DB.GetUserByPhone(phone)
+	user, err := e.store.GetUserByPhone(phone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check user: %w", err)
 	}
@@ -200,10 +305,11 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 			PhoneNumber: phone,
 			Name:        nameClean,
 			Email:       email,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			Timezone:    timezone,
+			CreatedAt:   e.now(),
+			UpdatedAt:   e.now(),
 		}
-		if err := database.DB.CreateUser(user); err != nil {
+		if err := e.store.CreateUser(user); err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
 		}
 	} else {
@@ -233,22 +339,32 @@ func (e *ToolExecutor) identifyUser(args map[string]interface{}) (interface{}, e
 		}
 
 		if updateNeeded {
-			user.UpdatedAt = time.Now()
-			_ = database.DB.UpdateUser(user)
+			user.UpdatedAt = e.now()
+			_ = e.store.UpdateUser(user)
 		}
 	}
 
 	e.SetUserIdentity(phone, user.Name)
+	e.SetUserTimezone(user.Timezone)
 
-	return map[string]interface{}{
+	return withWarning(map[string]interface{}{
 		"success":      true,
 		"user_id":      user.ID,
 		"phone_number": user.PhoneNumber,
 		"name":         user.Name,
 		"email":        user.Email,
+		"timezone":     user.Timezone,
 		"is_new_user":  user.Name == nameClean,
 		"message":      fmt.Sprintf("User identified: %s (%s)", user.Name, user.PhoneNumber),
-	}, nil
+	}, warning), nil
+}
+
+// withWarning adds warning to result when there is one
+func withWarning(result map[string]interface{}, warning string) map[string]interface{} {
+	if warning != "" {
+		result["warning"] = warning
+	}
+	return result
 }
 
 func (e *ToolExecutor) fetchSlots(args map[string]interface{}) (interface{}, error) {
@@ -257,53 +373,118 @@ func (e *ToolExecutor) fetchSlots(args map[string]interface{}) (interface{}, err
 		return nil, fmt.Errorf("date is required")
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	date, resolved, err := e.parseDate(dateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format, use YYYY-MM-DD")
+		return nil, fmt.Errorf("invalid date: %v. Use YYYY-MM-DD or words like \"next Tuesday\"", err)
 	}
+	dateStr = date.Format("2006-01-02")
 
-	// Generate hardcoded available slots (9 AM to 5 PM, every 30 minutes)
-	slots := []map[string]interface{}{}
-	loc := time.Local
+	duration := e.slots.DefaultDuration()
+	if d, ok := args["duration"].(float64); ok && d > 0 {
+		duration = int(d)
+	}
 
-	for hour := 9; hour < 17; hour++ {
-		for _, minute := range []int{0, 30} {
-			slotTime := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
 
-			// Skip past slots
-			if slotTime.Before(time.Now()) {
-				continue
-			}
+	buffer := 0
+	serviceID, _ := args["service_id"].(string)
+	service, err := e.findService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceID != "" && service == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Unknown service. Use list_services to see what we offer.",
+		}, nil
+	}
+	if service != nil {
+		if mismatch := durationMismatch(args, service); mismatch != nil {
+			return mismatch, nil
+		}
+		duration = service.Duration
+		buffer = service.BufferMinutes
+		providers = eligibleProviders(providers, service)
+	}
 
-			// Check availability in database
-			available, err := database.DB.CheckSlotAvailability(slotTime, 30)
-			if err != nil {
-				available = true // Default to available on error
-			}
+	providerQuery, _ := args["provider"].(string)
+	provider, found := findProvider(providers, providerQuery)
+	if !found {
+		return map[string]interface{}{
+			"success": false,
+			"error":   unknownProviderMessage(providerQuery, providers),
+		}, nil
+	}
 
-			slots = append(slots, map[string]interface{}{
-				"date_time": slotTime.Format(time.RFC3339),
-				"time":      slotTime.Format("3:04 PM"),
-				"available": available,
-				"duration":  30,
-			})
-		}
+	var timeSlots []models.TimeSlot
+	if provider != nil {
+		timeSlots, err = e.slots.Slots(date, duration, buffer, provider, e.holder())
+	} else {
+		timeSlots, err = e.slots.AnySlots(date, duration, buffer, providers, e.holder())
+	}
+	if err != nil {
+		return nil, err
 	}
 
+	slots := []map[string]interface{}{}
 	availableCount := 0
-	for _, slot := range slots {
-		if slot["available"].(bool) {
+	for _, slot := range timeSlots {
+		// "Tuesday afternoon" only lists the afternoon
+		if resolved.Part != "" && (slot.DateTime.Before(resolved.Start) || !slot.DateTime.Before(resolved.End)) {
+			continue
+		}
+		if slot.Available {
 			availableCount++
 		}
+		entry := map[string]interface{}{
+			"date_time": slot.DateTime.Format(time.RFC3339),
+			"time":      e.slots.Schedule().FormatTime(slot.DateTime),
+			"available": slot.Available,
+			"duration":  slot.Duration,
+		}
+		if slot.Held {
+			entry["held"] = true
+		}
+		if slot.ProviderID != "" {
+			entry["provider_id"] = slot.ProviderID
+			entry["provider"] = providerName(providers, slot.ProviderID)
+		}
+		slots = append(slots, entry)
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"date":            dateStr,
 		"slots":           slots,
 		"total_slots":     len(slots),
 		"available_slots": availableCount,
 		"message":         fmt.Sprintf("Found %d available slots out of %d total for %s", availableCount, len(slots), dateStr),
-	}, nil
+	}
+	if resolved.Part != "" {
+		result["part_of_day"] = resolved.Part
+	}
+	if len(slots) == 0 {
+		result["message"] = fmt.Sprintf("No appointments can be booked on %s (closed or fully past)", dateStr)
+	}
+	if service != nil {
+		result["service"] = service.Name
+	}
+	if len(providers) > 0 {
+		names := make([]string, len(providers))
+		for i, p := range providers {
+			names[i] = p.Name
+		}
+		result["providers"] = names
+		if provider != nil {
+			result["provider"] = provider.Name
+		} else {
+			result["provider"] = "any"
+		}
+	}
+
+	return result, nil
 }
 
 func (e *ToolExecutor) bookAppointment(args map[string]interface{}) (interface{}, error) {
@@ -325,62 +506,85 @@ func (e *ToolExecutor) bookAppointment(args map[string]interface{}) (interface{}
 
 	log.Printf("[bookAppointment] Attempting to parse date_time: '%s'", dateTimeStr)
 
-	// Try multiple date formats
-	var dateTime time.Time
-	var err error
-
-	// Try RFC3339 first
-	dateTime, err = time.Parse(time.RFC3339, dateTimeStr)
+	// Times without an offset, and spoken ones, are in the business timezone
+	dateTime, err := e.parseDateTime(dateTimeStr)
 	if err != nil {
-		// Try ISO date format
-		dateTime, err = time.Parse("2006-01-02T15:04:05", dateTimeStr)
-		if err != nil {
-			// Try another format
-			dateTime, err = time.Parse("2006-01-02 15:04:05", dateTimeStr)
-			if err != nil {
-				log.Printf("[bookAppointment] ERROR: Cannot parse date_time '%s': %v", dateTimeStr, err)
-				return nil, fmt.Errorf("invalid date_time format, use ISO 8601 (e.g., 2024-01-15T10:00:00Z or 2024-01-15T10:00:00)")
-			}
-		}
+		log.Printf("[bookAppointment] ERROR: Cannot parse date_time '%s': %v", dateTimeStr, err)
+		return nil, fmt.Errorf("invalid date_time: %v. Use ISO 8601 (e.g., 2024-01-15T10:00:00) or words naming a time like \"tomorrow at 3pm\"", err)
 	}
 
 	log.Printf("[bookAppointment] Parsed date_time to: %s", dateTime)
 
-	// Check if slot is in the past
-	if dateTime.Before(time.Now()) {
-		log.Printf("[bookAppointment] ERROR: Slot is in the past")
-		return map[string]interface{}{
-			"success": false,
-			"error":   "Cannot book appointments in the past",
-		}, nil
+	duration := e.slots.DefaultDuration()
+	if d, ok := args["duration"].(float64); ok && d > 0 {
+		duration = int(d)
 	}
 
-	duration := 30
-	if d, ok := args["duration"].(float64); ok {
-		duration = int(d)
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		log.Printf("[bookAppointment] ERROR: Failed to get providers: %v", err)
+		return nil, fmt.Errorf("failed to get providers: %w", err)
 	}
-	log.Printf("[bookAppointment] Duration: %d minutes", duration)
 
-	// Check slot availability
-	available, err := database.DB.CheckSlotAvailability(dateTime, duration)
+	// A catalog service fixes the duration, buffer and price
+	serviceID, _ := args["service_id"].(string)
+	service, err := e.findService(serviceID)
 	if err != nil {
-		log.Printf("[bookAppointment] ERROR: Failed to check availability: %v", err)
-		return nil, fmt.Errorf("failed to check availability: %w", err)
+		log.Printf("[bookAppointment] ERROR: Failed to get service: %v", err)
+		return nil, err
+	}
+	if serviceID != "" && service == nil {
+		log.Printf("[bookAppointment] ERROR: Unknown service '%s'", serviceID)
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Unknown service. Use list_services to see what we offer.",
+		}, nil
+	}
+	if service != nil {
+		if mismatch := durationMismatch(args, service); mismatch != nil {
+			log.Printf("[bookAppointment] ERROR: Duration does not match service %s", service.Name)
+			return mismatch, nil
+		}
+		duration = service.Duration
 	}
+	if service != nil && len(providers) > 0 {
+		providers = eligibleProviders(providers, service)
+		if len(providers) == 0 {
+			log.Printf("[bookAppointment] ERROR: No provider offers service %s", service.Name)
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("No one is currently available to provide %s", service.Name),
+			}, nil
+		}
+	}
+	log.Printf("[bookAppointment] Duration: %d minutes", duration)
 
-	if !available {
-		log.Printf("[bookAppointment] ERROR: Slot not available")
+	providerQuery, _ := args["provider"].(string)
+	provider, found := findProvider(providers, providerQuery)
+	if !found {
+		log.Printf("[bookAppointment] ERROR: Unknown provider '%s'", providerQuery)
 		return map[string]interface{}{
 			"success": false,
-			"error":   "This time slot is already booked. Please choose another time.",
+			"error":   unknownProviderMessage(providerQuery, providers),
 		}, nil
 	}
 
+	// Try the requested provider, or each provider in turn for "whoever is
+	// free", starting with any provider this session is holding the slot with
+	candidates := []*models.Provider{provider}
+	if provider == nil && len(providers) > 0 {
+		candidates = candidates[:0]
+		for i := range providers {
+			candidates = append(candidates, &providers[i])
+		}
+		if err := e.preferHeld(candidates, dateTime); err != nil {
+			return nil, err
+		}
+	}
+
 	purpose, _ := args["purpose"].(string)
 	notes, _ := args["notes"].(string)
 
-	log.Printf("[bookAppointment] Creating appointment - User: %s, Phone: %s, Purpose: %s", e.userName, e.userPhone, purpose)
-
 	appointment := &models.Appointment{
 		ID:        uuid.New().String(),
 		UserPhone: e.userPhone,
@@ -390,25 +594,155 @@ func (e *ToolExecutor) bookAppointment(args map[string]interface{}) (interface{}
 		Purpose:   purpose,
 		Notes:     notes,
 		Status:    models.StatusBooked,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: e.now(),
+		UpdatedAt: e.now(),
+	}
+	if service != nil {
+		appointment.ServiceID = service.ID
+		appointment.BufferMinutes = service.BufferMinutes
+		appointment.PriceCents = payment.CalculateAppointmentCost(service, duration)
+		if appointment.Purpose == "" {
+			appointment.Purpose = service.Name
+		}
+	}
+
+	upcoming, err := e.upcomingAppointments()
+	if err != nil {
+		log.Printf("[bookAppointment] ERROR: Failed to get upcoming appointments: %v", err)
+		return nil, fmt.Errorf("failed to get upcoming appointments: %w", err)
+	}
+
+	if recurrenceRule, _ := args["recurrence"].(string); strings.TrimSpace(recurrenceRule) != "" {
+		return e.bookSeries(appointment, candidates, recurrenceRule, service, upcoming)
 	}
 
-	if err := database.DB.CreateAppointment(appointment); err != nil {
-		log.Printf("[bookAppointment] ERROR: Failed to create appointment: %v", err)
-		return nil, fmt.Errorf("failed to book appointment: %w", err)
+	confirmDuplicate, _ := args["confirm_duplicate"].(bool)
+	guard := e.limitGuard(upcoming, 1)
+	if guard == nil {
+		guard = e.conflictGuard(appointment, upcoming, confirmDuplicate)
+	}
+	if guard != nil {
+		log.Printf("[bookAppointment] ERROR: Booking limit %s: %s", guard.Code, guard.Message)
+		return e.guardResult(guard), nil
+	}
+
+	placed, reason, err := e.placeAppointment(appointment, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		log.Printf("[bookAppointment] ERROR: %s", reason)
+		result := map[string]interface{}{
+			"success": false,
+			"error":   reason,
+		}
+		if e.waitlist != nil && (reason == msgSlotBooked || reason == msgSlotHeld) {
+			result["suggestion"] = "Offer to add the caller to the waitlist with join_waitlist"
+		}
+		return result, nil
 	}
 
 	log.Printf("[bookAppointment] SUCCESS: Appointment created with ID: %s", appointment.ID)
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"success":        true,
 		"appointment_id": appointment.ID,
-		"date_time":      appointment.DateTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+		"date_time":      e.formatDateTime(appointment.DateTime),
 		"duration":       appointment.Duration,
 		"purpose":        appointment.Purpose,
-		"message":        fmt.Sprintf("Appointment successfully booked for %s", appointment.DateTime.Format("Monday, January 2, 2006 at 3:04 PM")),
-	}, nil
+		"message":        fmt.Sprintf("Appointment successfully booked for %s", e.formatDateTime(appointment.DateTime)),
+	}
+	if service != nil {
+		result["service"] = service.Name
+		result["price"] = payment.FormatAmount(appointment.PriceCents)
+	}
+	if placed != nil {
+		result["provider_id"] = placed.ID
+		result["provider"] = placed.Name
+		result["message"] = fmt.Sprintf("Appointment successfully booked with %s for %s", placed.Name, e.formatDateTime(appointment.DateTime))
+	}
+	e.addCalendarLinks(result, appointment)
+	return result, nil
+}
+
+// Reasons placeAppointment gives when the time is within hours but taken
+const (
+	msgSlotBooked = "This time slot is already booked. Please choose another time."
+	msgSlotHeld   = "This time is being held for another caller. Please choose another time."
+)
+
+// placeAppointment creates appt with the first candidate provider that works
+// at that time and is free, setting its ProviderID. A nil candidate is the
+// unassigned calendar. When nothing fits it returns a reason for the caller
+// instead of an error.
+func (e *ToolExecutor) placeAppointment(appointment *models.Appointment, candidates []*models.Provider) (*models.Provider, string, error) {
+	var scheduleErr error
+	withinHours := false
+	held := false
+	for _, candidate := range candidates {
+		// Check the slot is in the future and within the provider's hours
+		if err := e.slots.Validate(appointment.DateTime, appointment.Duration, e.now(), candidate); err != nil {
+			if scheduleErr == nil {
+				scheduleErr = err
+			}
+			continue
+		}
+		withinHours = true
+
+		// Respect slots held for someone else, e.g. a waitlist offer
+		ownHolds, heldForOthers, err := e.slotHolds(providerID(candidate), appointment.DateTime, appointment.BlockEnd())
+		if err != nil {
+			log.Printf("[bookAppointment] ERROR: Failed to check holds: %v", err)
+			return nil, "", err
+		}
+		if heldForOthers {
+			held = true
+			continue
+		}
+
+		// Check slot availability
+		available, err := e.store.CheckSlotAvailability(providerID(candidate), appointment.DateTime, appointment.Duration+appointment.BufferMinutes)
+		if err != nil {
+			log.Printf("[bookAppointment] ERROR: Failed to check availability: %v", err)
+			return nil, "", fmt.Errorf("failed to check availability: %w", err)
+		}
+		if !available {
+			continue
+		}
+
+		appointment.ProviderID = providerID(candidate)
+		log.Printf("[bookAppointment] Creating appointment - User: %s, Phone: %s, Purpose: %s, Provider: %s", appointment.UserName, appointment.UserPhone, appointment.Purpose, appointment.ProviderID)
+
+		// The availability check above is advisory; the store rejects the insert
+		// if another caller booked an overlapping slot in the meantime.
+		if err := e.store.CreateAppointment(appointment); err != nil {
+			if errors.Is(err, database.ErrSlotUnavailable) {
+				log.Printf("[bookAppointment] Slot was taken concurrently")
+				continue
+			}
+			log.Printf("[bookAppointment] ERROR: Failed to create appointment: %v", err)
+			return nil, "", fmt.Errorf("failed to book appointment: %w", err)
+		}
+		e.releaseHolds(ownHolds, appointment.ID)
+		return candidate, "", nil
+	}
+
+	if !withinHours {
+		if len(candidates) > 1 {
+			// Prefer the business-wide reason (past, lead time, closed) when there is one
+			if err := e.slots.Validate(appointment.DateTime, appointment.Duration, e.now(), nil); err != nil {
+				scheduleErr = err
+			} else {
+				scheduleErr = fmt.Errorf("none of our providers are working at that time")
+			}
+		}
+		return nil, capitalize(scheduleErr.Error()), nil
+	}
+	if held {
+		return nil, msgSlotHeld, nil
+	}
+
+	return nil, msgSlotBooked, nil
 }
 
 func (e *ToolExecutor) retrieveAppointments(args map[string]interface{}) (interface{}, error) {
@@ -433,9 +767,9 @@ func (e *ToolExecutor) retrieveAppointments(args map[string]interface{}) (interf
 	var err error
 
 	if retrieveType == "upcoming" {
-		appointments, err = database.DB.GetUpcomingAppointments(e.userPhone)
+		appointments, err = e.store.GetUpcomingAppointments(e.userPhone)
 	} else {
-		appointments, err = database.DB.GetAppointmentsByPhone(e.userPhone)
+		appointments, err = e.store.GetAppointmentsByPhone(e.userPhone)
 	}
 
 	if err != nil {
@@ -445,16 +779,26 @@ func (e *ToolExecutor) retrieveAppointments(args map[string]interface{}) (interf
 
 	log.Printf("[retrieveAppointments] Retrieved %d appointments", len(appointments))
 
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		log.Printf("[retrieveAppointments] ERROR: Failed to get providers: %v", err)
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+
 	formattedAppointments := make([]map[string]interface{}, len(appointments))
 	for i, apt := range appointments {
 		formattedAppointments[i] = map[string]interface{}{
 			"id":        apt.ID,
-			"date_time": apt.DateTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+			"date_time": e.formatDateTime(apt.DateTime),
 			"duration":  apt.Duration,
 			"purpose":   apt.Purpose,
 			"status":    apt.Status,
 			"notes":     apt.Notes,
 		}
+		if apt.ProviderID != "" {
+			formattedAppointments[i]["provider"] = providerName(providers, apt.ProviderID)
+		}
+		e.addStatusTimes(formattedAppointments[i], &apt)
 	}
 
 	message := fmt.Sprintf("Found %d %s appointment(s)", len(appointments), retrieveType)
@@ -492,7 +836,7 @@ func (e *ToolExecutor) cancelAppointment(args map[string]interface{}) (interface
 
 	log.Printf("[cancelAppointment] Fetching appointment ID: %s", appointmentID)
 
-	appointment, err := database.DB.GetAppointmentByID(appointmentID)
+	appointment, err := e.store.GetAppointmentByID(appointmentID)
 	if err != nil {
 		log.Printf("[cancelAppointment] ERROR: Failed to get appointment: %v", err)
 		return nil, fmt.Errorf("failed to get appointment: %w", err)
@@ -522,29 +866,70 @@ func (e *ToolExecutor) cancelAppointment(args map[string]interface{}) (interface
 			"error":   "Appointment is already cancelled",
 		}, nil
 	}
+	if !lifecycle.CanTransition(appointment.Status, models.StatusCancelled) {
+		log.Printf("[cancelAppointment] ERROR: Appointment is %s", appointment.Status)
+		return map[string]interface{}{
+			"success": false,
+			"status":  appointment.Status,
+			"error":   fmt.Sprintf("This appointment is %s and can no longer be cancelled", lifecycle.Describe(appointment.Status)),
+		}, nil
+	}
 
 	reason, _ := args["reason"].(string)
+	acceptFee, _ := args["accept_fee"].(bool)
 
-	appointment.Status = models.StatusCancelled
+	if scope := seriesScope(args, appointment); scope != scopeThis {
+		return e.cancelSeries(appointment, scope, reason, acceptFee)
+	}
+
+	now := e.now()
+	decision := e.cancelDecision(appointment, now)
+	if !decision.Allowed {
+		log.Printf("[cancelAppointment] ERROR: Refused by policy: %s", decision.Reason)
+		return map[string]interface{}{
+			"success": false,
+			"error":   decision.Reason,
+			"policy":  decision,
+		}, nil
+	}
+	if decision.FeeCents > 0 && !acceptFee {
+		return feeConfirmation(decision, decision.FeeCents), nil
+	}
+
+	if err := lifecycle.Transition(appointment, models.StatusCancelled, now); err != nil {
+		return nil, err
+	}
 	if reason != "" {
 		appointment.Notes = fmt.Sprintf("%s\nCancellation reason: %s", appointment.Notes, reason)
 	}
+	var intents []*payment.PaymentIntent
+	if intent := e.chargeFee(appointment, decision); intent != nil {
+		intents = append(intents, intent)
+	}
 
 	log.Printf("[cancelAppointment] Updating appointment status to cancelled")
 
-	if err := database.DB.UpdateAppointment(appointment); err != nil {
+	if err := e.store.UpdateAppointment(appointment); err != nil {
 		log.Printf("[cancelAppointment] ERROR: Failed to cancel appointment: %v", err)
 		return nil, fmt.Errorf("failed to cancel appointment: %w", err)
 	}
 
 	log.Printf("[cancelAppointment] SUCCESS: Appointment cancelled - ID: %s", appointmentID)
+	e.offerToWaitlist(appointment)
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"success":        true,
 		"appointment_id": appointmentID,
-		"date_time":      appointment.DateTime.Format("Monday, January 2, 2006 at 3:04 PM"),
-		"message":        fmt.Sprintf("Appointment on %s has been cancelled", appointment.DateTime.Format("Monday, January 2, 2006 at 3:04 PM")),
-	}, nil
+		"date_time":      e.formatDateTime(appointment.DateTime),
+		"status":         appointment.Status,
+		"message":        fmt.Sprintf("Appointment on %s has been cancelled", e.formatDateTime(appointment.DateTime)),
+	}
+	addFee(result, appointment.FeeCents, intents)
+	e.addCalendarLinks(result, appointment)
+	if len(intents) > 0 {
+		return ClientResult{Result: result, Client: feeClient(intents)}, nil
+	}
+	return result, nil
 }
 
 func (e *ToolExecutor) modifyAppointment(args map[string]interface{}) (interface{}, error) {
@@ -560,7 +945,7 @@ func (e *ToolExecutor) modifyAppointment(args map[string]interface{}) (interface
 		return nil, fmt.Errorf("appointment_id is required")
 	}
 
-	appointment, err := database.DB.GetAppointmentByID(appointmentID)
+	appointment, err := e.store.GetAppointmentByID(appointmentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointment: %w", err)
 	}
@@ -580,37 +965,79 @@ func (e *ToolExecutor) modifyAppointment(args map[string]interface{}) (interface
 		}, nil
 	}
 
-	if appointment.Status == models.StatusCancelled {
+	if !lifecycle.CanTransition(appointment.Status, models.StatusRescheduled) {
 		return map[string]interface{}{
 			"success": false,
-			"error":   "Cannot modify a cancelled appointment",
+			"status":  appointment.Status,
+			"error":   fmt.Sprintf("Cannot modify a %s appointment", lifecycle.Describe(appointment.Status)),
 		}, nil
 	}
 
+	if scope := seriesScope(args, appointment); scope != scopeThis {
+		return e.modifySeries(appointment, scope, args)
+	}
+
 	modified := false
 	changes := []string{}
+	var ownHolds []models.SlotHold
 
 	// Handle new date_time
 	if newDateTimeStr, ok := args["new_date_time"].(string); ok && newDateTimeStr != "" {
-		newDateTime, err := time.Parse(time.RFC3339, newDateTimeStr)
+		newDateTime, err := e.parseDateTime(newDateTimeStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid new_date_time format")
+			return nil, fmt.Errorf("invalid new_date_time: %v", err)
 		}
 
-		if newDateTime.Before(time.Now()) {
+		if newDateTime.Before(e.now()) {
 			return map[string]interface{}{
 				"success": false,
 				"error":   "Cannot reschedule to a past time",
 			}, nil
 		}
 
+		if !newDateTime.Equal(appointment.DateTime) {
+			if decision := e.rescheduleDecision(appointment, e.now()); !decision.Allowed {
+				return map[string]interface{}{
+					"success": false,
+					"error":   decision.Reason,
+					"policy":  decision,
+				}, nil
+			}
+		}
+
 		// Check availability for new time
 		duration := appointment.Duration
 		if newDur, ok := args["new_duration"].(float64); ok {
 			duration = int(newDur)
 		}
 
-		available, err := database.DB.CheckSlotAvailability(newDateTime, duration)
+		provider, err := e.appointmentProvider(appointment)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := e.slots.Validate(newDateTime, duration, e.now(), provider); err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   capitalize(err.Error()),
+			}, nil
+		}
+
+		// Respect slots held for someone else, as booking does
+		end := newDateTime.Add(time.Duration(duration+appointment.BufferMinutes) * time.Minute)
+		var heldForOthers bool
+		ownHolds, heldForOthers, err = e.slotHolds(appointment.ProviderID, newDateTime, end)
+		if err != nil {
+			return nil, err
+		}
+		if heldForOthers {
+			return map[string]interface{}{
+				"success": false,
+				"error":   msgSlotHeld,
+			}, nil
+		}
+
+		available, err := e.store.CheckSlotAvailability(appointment.ProviderID, newDateTime, duration+appointment.BufferMinutes)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check availability: %w", err)
 		}
@@ -622,9 +1049,27 @@ func (e *ToolExecutor) modifyAppointment(args map[string]interface{}) (interface
 			}, nil
 		}
 
+		// The caller's other appointments may be with another provider
+		upcoming, err := e.upcomingAppointments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get upcoming appointments: %w", err)
+		}
+		moved := *appointment
+		moved.DateTime = newDateTime
+		moved.Duration = duration
+		if guard := e.conflictGuard(&moved, upcoming, true); guard != nil {
+			return e.guardResult(guard), nil
+		}
+
+		if !newDateTime.Equal(appointment.DateTime) {
+			if err := lifecycle.Transition(appointment, models.StatusRescheduled, e.now()); err != nil {
+				return nil, err
+			}
+			appointment.RescheduleCount++
+		}
 		appointment.DateTime = newDateTime
 		modified = true
-		changes = append(changes, fmt.Sprintf("rescheduled to %s", newDateTime.Format("Monday, January 2, 2006 at 3:04 PM")))
+		changes = append(changes, fmt.Sprintf("rescheduled to %s", e.formatDateTime(newDateTime)))
 	}
 
 	// Handle new duration
@@ -655,16 +1100,24 @@ func (e *ToolExecutor) modifyAppointment(args map[string]interface{}) (interface
 		}, nil
 	}
 
-	if err := database.DB.UpdateAppointment(appointment); err != nil {
+	if err := e.store.UpdateAppointment(appointment); err != nil {
+		if errors.Is(err, database.ErrSlotUnavailable) {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "The new time slot is not available",
+			}, nil
+		}
 		return nil, fmt.Errorf("failed to modify appointment: %w", err)
 	}
+	e.releaseHolds(ownHolds, appointment.ID)
 
 	return map[string]interface{}{
 		"success":        true,
 		"appointment_id": appointmentID,
 		"changes":        changes,
-		"new_date_time":  appointment.DateTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+		"new_date_time":  e.formatDateTime(appointment.DateTime),
 		"new_duration":   appointment.Duration,
+		"status":         appointment.Status,
 		"message":        fmt.Sprintf("Appointment modified: %v", changes),
 	}, nil
 }
@@ -681,6 +1134,30 @@ func (e *ToolExecutor) endConversation(args map[string]interface{}) (interface{}
 	}, nil
 }
 
+// formatDateTime formats t in the business timezone and, when the caller is
+// in a different zone, appends their local time as well.
+func (e *ToolExecutor) formatDateTime(t time.Time) string {
+	formatted := e.slots.Schedule().FormatDateTime(t)
+	if e.userLocation == nil {
+		return formatted
+	}
+
+	_, businessOffset := t.In(e.slots.Schedule().Location).Zone()
+	local := t.In(e.userLocation)
+	if _, localOffset := local.Zone(); localOffset == businessOffset {
+		return formatted
+	}
+	return fmt.Sprintf("%s (%s your time)", formatted, local.Format(availability.DateTimeLayout))
+}
+
+// capitalize upper-cases the first letter of an error message for the LLM
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 // Helper function to normalize phone numbers
 func normalizePhoneNumber(phone string) string {
 	// Remove all non-digit characters except leading +
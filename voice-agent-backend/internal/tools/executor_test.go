@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/database"
+)
+
+// testNow is the time the executor sees in tests: the coming midnight UTC,
+// so every time a test books is still ahead of the real clock, which the
+// store and slot engine check against
+var testNow = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+
+// testClock is the executor clock used in tests
+func testClock() time.Time {
+	return testNow
+}
+
+// newTestEngine returns a slot engine that is open around the clock
+func newTestEngine(store database.Store) *availability.Engine {
+	allDay := []availability.TimeRange{{Start: 0, End: 24 * 60}}
+	hours := make(map[time.Weekday][]availability.TimeRange)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		hours[day] = allDay
+	}
+	return availability.NewEngine(&availability.Schedule{
+		WeeklyHours:     hours,
+		SlotInterval:    30,
+		DefaultDuration: 30,
+		HoldTTL:         5 * time.Minute,
+		Location:        time.UTC,
+	}, store)
+}
+
+// newTestExecutor returns an executor for a session with the around-the-clock
+// engine, the test clock and none of the optional services
+func newTestExecutor(store database.Store, sessionID string) *ToolExecutor {
+	return NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Now: testClock}, sessionID, nil, nil)
+}
+
+func TestBookAppointmentConcurrentCallersCannotDoubleBook(t *testing.T) {
+	store := database.NewMemoryStore()
+	slot := testNow.Add(48 * time.Hour).Format(time.RFC3339)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]map[string]interface{}, callers)
+	errs := make([]error, callers)
+
+	start := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			executor := newTestExecutor(store, fmt.Sprintf("session-%d", i))
+			executor.SetUserIdentity(fmt.Sprintf("+1555000%04d", i), fmt.Sprintf("Caller %d", i))
+
+			<-start
+			result, err := executor.bookAppointment(map[string]interface{}{
+				"date_time": slot,
+				"duration":  float64(30),
+			})
+			errs[i] = err
+			if m, ok := result.(map[string]interface{}); ok {
+				results[i] = m
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	booked := 0
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: unexpected error: %v", i, errs[i])
+		}
+		if results[i]["success"] == true {
+			booked++
+			continue
+		}
+		if results[i]["error"] != "This time slot is already booked. Please choose another time." {
+			t.Errorf("caller %d: unexpected failure response: %v", i, results[i])
+		}
+	}
+
+	if booked != 1 {
+		t.Fatalf("expected exactly 1 successful booking, got %d", booked)
+	}
+}
+
+func TestBookingsAndMovesIntoATakenSlotAreRejected(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550001111", "Caller")
+
+	first := testNow.Add(48 * time.Hour)
+	second := first.Add(2 * time.Hour)
+	var ids []string
+	for _, slot := range []time.Time{first, second} {
+		result, err := executor.bookAppointment(map[string]interface{}{"date_time": slot.Format(time.RFC3339)})
+		if err != nil || result.(map[string]interface{})["success"] != true {
+			t.Fatalf("booking %s: %v %v", slot, result, err)
+		}
+		ids = append(ids, result.(map[string]interface{})["appointment_id"].(string))
+	}
+
+	tests := []struct {
+		name    string
+		move    bool
+		at      time.Time
+		success bool
+	}{
+		{"booking the same time", false, first, false},
+		{"booking an overlapping time", false, first.Add(15 * time.Minute), false},
+		{"moving into an overlapping time", true, first.Add(15 * time.Minute), false},
+		{"moving next to it", true, first.Add(30 * time.Minute), true},
+	}
+	for _, tt := range tests {
+		var result interface{}
+		var err error
+		if tt.move {
+			result, err = executor.modifyAppointment(map[string]interface{}{"appointment_id": ids[1], "new_date_time": tt.at.Format(time.RFC3339)})
+		} else {
+			result, err = executor.bookAppointment(map[string]interface{}{"date_time": tt.at.Format(time.RFC3339)})
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m := result.(map[string]interface{}); m["success"] != tt.success {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+	}
+}
+
+func TestIdentifyUserIgnoresAnInvalidTimezone(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+
+	tests := []struct {
+		name     string
+		phone    string
+		stored   string // the caller's timezone from an earlier call
+		timezone string
+		want     string // timezone used, "" for business time
+		warning  bool
+	}{
+		{"an unknown zone for a new caller", "+15550007777", "", "Mars/Olympus_Mons", "", true},
+		{"a known zone", "+15550008888", "", "America/New_York", "America/New_York", false},
+		// A returning caller keeps the timezone they gave before
+		{"an unknown zone for a returning caller", "+15550007777", "America/Chicago", "Not/A_Zone", "America/Chicago", true},
+	}
+	for _, tt := range tests {
+		if tt.stored != "" {
+			user, _ := store.GetUserByPhone(tt.phone)
+			user.Timezone = tt.stored
+			if err := store.UpdateUser(user); err != nil {
+				t.Fatalf("%s: update: %v", tt.name, err)
+			}
+		}
+		result, err := executor.identifyUser(map[string]interface{}{
+			"phone_number": tt.phone,
+			"name":         "Caller",
+			"email":        "caller@example.com",
+			"timezone":     tt.timezone,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		if m["success"] != true || m["timezone"] != tt.want {
+			t.Errorf("%s: expected timezone %q, got %v", tt.name, tt.want, m)
+		}
+		if warned := strings.Contains(fmt.Sprint(m["warning"]), tt.timezone); warned != tt.warning {
+			t.Errorf("%s: expected warning=%v, got %v", tt.name, tt.warning, m["warning"])
+		}
+		if location := executor.userLocation; (location == nil && tt.want != "") || (location != nil && location.String() != tt.want) {
+			t.Errorf("%s: expected the caller's time in %q, got %v", tt.name, tt.want, location)
+		}
+		if user, _ := store.GetUserByPhone(tt.phone); user.Timezone != tt.want {
+			t.Errorf("%s: expected %q stored, got %q", tt.name, tt.want, user.Timezone)
+		}
+	}
+}
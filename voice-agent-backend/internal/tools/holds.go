@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// ReleaseHolds releases the slots held by this session
+func (e *ToolExecutor) ReleaseHolds() {
+	if err := e.store.DeleteSlotHoldsBySession(e.sessionID); err != nil {
+		log.Printf("[ReleaseHolds] WARNING: Failed to release holds for session %s: %v", e.sessionID, err)
+	}
+}
+
+// holder identifies the session and caller whose holds don't block them
+func (e *ToolExecutor) holder() availability.Holder {
+	return availability.Holder{SessionID: e.sessionID, Phone: e.userPhone}
+}
+
+// slotHolds returns the caller's own unexpired holds overlapping [start, end)
+// on a provider's calendar, and whether anyone else holds part of it
+func (e *ToolExecutor) slotHolds(providerID string, start, end time.Time) ([]models.SlotHold, bool, error) {
+	holds, err := e.store.GetSlotHolds(start, end)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get slot holds: %w", err)
+	}
+
+	var own []models.SlotHold
+	for _, hold := range holds {
+		if hold.ProviderID != providerID {
+			continue
+		}
+		if !hold.OwnedBy(e.sessionID, e.userPhone) {
+			return nil, true, nil
+		}
+		own = append(own, hold)
+	}
+	return own, false, nil
+}
+
+// releaseHolds drops holds that a booking has used up
+func (e *ToolExecutor) releaseHolds(holds []models.SlotHold, appointmentID string) {
+	for i := range holds {
+		if err := e.store.DeleteSlotHold(holds[i].ID); err != nil {
+			log.Printf("[releaseHolds] WARNING: Failed to release hold %s: %v", holds[i].ID, err)
+		}
+		if e.waitlist != nil {
+			if err := e.waitlist.Accept(&holds[i], appointmentID); err != nil {
+				log.Printf("[releaseHolds] WARNING: Failed to update waitlist entry: %v", err)
+			}
+		}
+	}
+}
+
+// preferHeld moves the candidate this session holds start with to the front
+func (e *ToolExecutor) preferHeld(candidates []*models.Provider, start time.Time) error {
+	holds, err := e.store.GetSlotHolds(start, start.Add(time.Minute))
+	if err != nil {
+		return fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	for _, hold := range holds {
+		if hold.SessionID != e.sessionID || !hold.DateTime.Equal(start) {
+			continue
+		}
+		for i, candidate := range candidates {
+			if providerID(candidate) == hold.ProviderID {
+				copy(candidates[1:i+1], candidates[:i])
+				candidates[0] = candidate
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// holdSlot reserves a slot for this session for the schedule's HoldTTL so the
+// caller can confirm details without another caller taking it. A session
+// holds one slot at a time.
+func (e *ToolExecutor) holdSlot(args map[string]interface{}) (interface{}, error) {
+	dateTimeStr, _ := args["date_time"].(string)
+	if dateTimeStr == "" {
+		return nil, fmt.Errorf("date_time is required")
+	}
+	dateTime, err := e.parseDateTime(dateTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date_time: %v. Use ISO 8601 (e.g., 2024-01-15T10:00:00) or words naming a time", err)
+	}
+
+	duration := e.slots.DefaultDuration()
+	if d, ok := args["duration"].(float64); ok && d > 0 {
+		duration = int(d)
+	}
+	buffer := 0
+
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+
+	serviceID, _ := args["service_id"].(string)
+	service, err := e.findService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceID != "" && service == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Unknown service. Use list_services to see what we offer.",
+		}, nil
+	}
+	if service != nil {
+		if mismatch := durationMismatch(args, service); mismatch != nil {
+			return mismatch, nil
+		}
+		duration = service.Duration
+		buffer = service.BufferMinutes
+		providers = eligibleProviders(providers, service)
+	}
+
+	providerQuery, _ := args["provider"].(string)
+	provider, found := findProvider(providers, providerQuery)
+	if !found {
+		return map[string]interface{}{
+			"success": false,
+			"error":   unknownProviderMessage(providerQuery, providers),
+		}, nil
+	}
+
+	candidates := []*models.Provider{provider}
+	if provider == nil && len(providers) > 0 {
+		candidates = candidates[:0]
+		for i := range providers {
+			candidates = append(candidates, &providers[i])
+		}
+	}
+
+	// Find the first candidate the slot is open and free with, as booking would
+	block := time.Duration(duration+buffer) * time.Minute
+	var chosen *models.Provider
+	var ownHolds []models.SlotHold
+	var scheduleErr error
+	free := false
+	reason := msgSlotBooked
+	for _, candidate := range candidates {
+		if err := e.slots.Validate(dateTime, duration, e.now(), candidate); err != nil {
+			if scheduleErr == nil {
+				scheduleErr = err
+			}
+			continue
+		}
+		own, heldForOthers, err := e.slotHolds(providerID(candidate), dateTime, dateTime.Add(block))
+		if err != nil {
+			return nil, err
+		}
+		if heldForOthers {
+			reason = msgSlotHeld
+			continue
+		}
+		available, err := e.store.CheckSlotAvailability(providerID(candidate), dateTime, duration+buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check availability: %w", err)
+		}
+		if available {
+			chosen, ownHolds, free = candidate, own, true
+			break
+		}
+	}
+	if !free {
+		if scheduleErr != nil && len(candidates) == 1 {
+			reason = capitalize(scheduleErr.Error())
+		}
+		return map[string]interface{}{
+			"success": false,
+			"error":   reason,
+		}, nil
+	}
+
+	e.ReleaseHolds()
+	expiresAt := e.now().Add(e.slots.Schedule().HoldTTL)
+	if offer := coveringHold(ownHolds, e.sessionID, dateTime, dateTime.Add(block)); offer != nil {
+		// The caller already holds it from the waitlist; keep that hold
+		expiresAt = offer.ExpiresAt
+	} else {
+		hold := &models.SlotHold{
+			ProviderID:  providerID(chosen),
+			DateTime:    dateTime,
+			EndTime:     dateTime.Add(block),
+			SessionID:   e.sessionID,
+			HolderPhone: e.userPhone,
+			ExpiresAt:   expiresAt,
+		}
+		err := e.store.CreateSlotHold(hold)
+		if errors.Is(err, database.ErrSlotUnavailable) {
+			// Another caller held it since the check above
+			return map[string]interface{}{
+				"success": false,
+				"error":   msgSlotHeld,
+			}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to hold slot: %w", err)
+		}
+	}
+	log.Printf("[holdSlot] Session %s holding %s until %s", e.sessionID, dateTime.Format(time.RFC3339), expiresAt.Format(time.RFC3339))
+
+	result := map[string]interface{}{
+		"success":    true,
+		"date_time":  e.formatDateTime(dateTime),
+		"duration":   duration,
+		"expires_at": expiresAt.Format(time.RFC3339),
+		"message":    fmt.Sprintf("Holding %s for %d minutes. Confirm the details and book it before then.", e.formatDateTime(dateTime), int(e.slots.Schedule().HoldTTL.Minutes())),
+	}
+	if chosen != nil {
+		result["provider_id"] = chosen.ID
+		result["provider"] = chosen.Name
+	}
+	return result, nil
+}
+
+// coveringHold returns the hold among the caller's own that spans [start, end)
+// and outlives the session, such as a waitlist offer
+func coveringHold(holds []models.SlotHold, sessionID string, start, end time.Time) *models.SlotHold {
+	for i := range holds {
+		if holds[i].SessionID != sessionID && !holds[i].DateTime.After(start) && !holds[i].EndTime.Before(end) {
+			return &holds[i]
+		}
+	}
+	return nil
+}
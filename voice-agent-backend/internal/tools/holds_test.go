@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestSessionHoldsBlockOtherSessions(t *testing.T) {
+	store := database.NewMemoryStore()
+	engine := newTestEngine(store)
+	holder := NewToolExecutor(Services{Store: store, Slots: engine, Now: testClock}, "session-a", nil, nil)
+	holder.SetUserIdentity("+15550000001", "Holder")
+	other := NewToolExecutor(Services{Store: store, Slots: engine, Now: testClock}, "session-b", nil, nil)
+	other.SetUserIdentity("+15550000002", "Other")
+
+	base := testNow.Add(48 * time.Hour)
+	booked, err := other.bookAppointment(map[string]interface{}{"date_time": base.Format(time.RFC3339)})
+	if err != nil || booked.(map[string]interface{})["success"] != true {
+		t.Fatalf("book: %v %v", booked, err)
+	}
+	id := booked.(map[string]interface{})["appointment_id"]
+
+	held, moveTo, released := base.Add(2*time.Hour), base.Add(4*time.Hour), base.Add(6*time.Hour)
+	run := func(executor *ToolExecutor, action string, at time.Time) (interface{}, error) {
+		args := map[string]interface{}{"date_time": at.Format(time.RFC3339)}
+		switch action {
+		case "hold":
+			return executor.holdSlot(args)
+		case "book":
+			return executor.bookAppointment(args)
+		case "move":
+			return executor.modifyAppointment(map[string]interface{}{"appointment_id": id, "new_date_time": at.Format(time.RFC3339)})
+		case "release":
+			executor.ReleaseHolds()
+			return map[string]interface{}{"success": true}, nil
+		}
+		// list: the slot shows as held to everyone
+		slots, err := engine.Slots(at, 30, 0, nil, availability.Holder{})
+		for _, s := range slots {
+			if s.DateTime.Equal(at) {
+				return map[string]interface{}{"success": s.Held && !s.Available}, err
+			}
+		}
+		return map[string]interface{}{"success": false}, err
+	}
+
+	tests := []struct {
+		name     string
+		executor *ToolExecutor
+		action   string
+		at       time.Time
+		success  bool
+		err      string
+	}{
+		{"a caller holds a slot", holder, "hold", held, true, ""},
+		{"it is listed as held", nil, "list", held, true, ""},
+		{"another caller can't book it", other, "book", held, false, msgSlotHeld},
+		{"or move into it", other, "move", held, false, msgSlotHeld},
+		{"or hold it", other, "hold", held, false, msgSlotHeld},
+		{"the holder books it", holder, "book", held, true, ""},
+		{"a caller holds a slot to move into", other, "hold", moveTo, true, ""},
+		{"and moves into it", other, "move", moveTo, true, ""},
+		{"a caller holds another slot", holder, "hold", released, true, ""},
+		{"ending the call releases it", holder, "release", released, true, ""},
+		{"so another caller can hold it", other, "hold", released, true, ""},
+	}
+	for _, tt := range tests {
+		result, err := run(tt.executor, tt.action, tt.at)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m := result.(map[string]interface{}); m["success"] != tt.success || (tt.err != "" && m["error"] != tt.err) {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+	}
+
+	// Booking or moving into a hold uses it up
+	if holds, _ := store.GetSlotHolds(held, moveTo.Add(time.Hour)); len(holds) != 0 {
+		t.Errorf("expected the used holds to be released, got %v", holds)
+	}
+	if holds, _ := store.GetSlotHolds(released, released.Add(time.Hour)); len(holds) != 1 || holds[0].SessionID != "session-b" {
+		t.Errorf("expected only the last hold left, got %v", holds)
+	}
+}
+
+func TestConcurrentHoldsOnOneSlotLetOneSessionWin(t *testing.T) {
+	store := database.NewMemoryStore()
+	engine := newTestEngine(store)
+	slot := testNow.Add(48 * time.Hour)
+
+	const sessions = 10
+	var wg sync.WaitGroup
+	results := make([]map[string]interface{}, sessions)
+	for i := 0; i < sessions; i++ {
+		executor := NewToolExecutor(Services{Store: store, Slots: engine, Now: testClock}, fmt.Sprintf("session-%d", i), nil, nil)
+		executor.SetUserIdentity(fmt.Sprintf("+1555000%04d", i), "Caller")
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := executor.holdSlot(map[string]interface{}{"date_time": slot.Format(time.RFC3339)})
+			if err != nil {
+				t.Errorf("session %d: %v", i, err)
+				return
+			}
+			results[i] = result.(map[string]interface{})
+		}(i)
+	}
+	wg.Wait()
+
+	held := 0
+	for i, result := range results {
+		switch {
+		case result == nil:
+		case result["success"] == true:
+			held++
+		case result["error"] != msgSlotHeld:
+			t.Errorf("session %d: unexpected result %v", i, result)
+		}
+	}
+	if held != 1 {
+		t.Fatalf("expected exactly 1 session to hold the slot, got %d", held)
+	}
+}
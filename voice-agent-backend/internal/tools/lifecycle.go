@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/lifecycle"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func (e *ToolExecutor) confirmAppointment(args map[string]interface{}) (interface{}, error) {
+	if e.userPhone == "" {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "User not identified. Please identify the user first.",
+		}, nil
+	}
+
+	appointmentID, ok := args["appointment_id"].(string)
+	if !ok || appointmentID == "" {
+		return nil, fmt.Errorf("appointment_id is required")
+	}
+
+	appointment, err := e.store.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	if appointment == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Appointment not found",
+		}, nil
+	}
+	if appointment.UserPhone != e.userPhone {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "You can only confirm your own appointments",
+		}, nil
+	}
+
+	if appointment.Status == models.StatusConfirmed {
+		return map[string]interface{}{
+			"success": true,
+			"status":  appointment.Status,
+			"message": fmt.Sprintf("Appointment on %s is already confirmed", e.formatDateTime(appointment.DateTime)),
+		}, nil
+	}
+	if err := lifecycle.Transition(appointment, models.StatusConfirmed, e.now()); err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"status":  appointment.Status,
+			"error":   fmt.Sprintf("This appointment is %s and cannot be confirmed", lifecycle.Describe(appointment.Status)),
+		}, nil
+	}
+	if err := e.store.UpdateAppointment(appointment); err != nil {
+		log.Printf("[confirmAppointment] ERROR: Failed to confirm appointment: %v", err)
+		return nil, fmt.Errorf("failed to confirm appointment: %w", err)
+	}
+
+	log.Printf("[confirmAppointment] SUCCESS: Appointment confirmed - ID: %s", appointmentID)
+
+	return map[string]interface{}{
+		"success":        true,
+		"appointment_id": appointmentID,
+		"date_time":      e.formatDateTime(appointment.DateTime),
+		"status":         appointment.Status,
+		"message":        fmt.Sprintf("Appointment on %s is confirmed", e.formatDateTime(appointment.DateTime)),
+	}, nil
+}
+
+// addStatusTimes adds when the appointment went through each lifecycle step
+func (e *ToolExecutor) addStatusTimes(entry map[string]interface{}, appointment *models.Appointment) {
+	times := []struct {
+		key string
+		at  *time.Time
+	}{
+		{"confirmed_at", appointment.ConfirmedAt},
+		{"rescheduled_at", appointment.RescheduledAt},
+		{"checked_in_at", appointment.CheckedInAt},
+		{"completed_at", appointment.CompletedAt},
+		{"no_show_at", appointment.NoShowAt},
+		{"cancelled_at", appointment.CancelledAt},
+	}
+	for _, t := range times {
+		if t.at != nil {
+			entry[t.key] = e.formatDateTime(*t.at)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/lifecycle"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestAppointmentLifecycleTransitionsAndNoShowSweep(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550002222", "Caller")
+
+	slot := testNow.Add(48 * time.Hour)
+	result, err := executor.bookAppointment(map[string]interface{}{"date_time": slot.Format(time.RFC3339)})
+	if err != nil || result.(map[string]interface{})["success"] != true {
+		t.Fatalf("booking: %v %v", result, err)
+	}
+	id := result.(map[string]interface{})["appointment_id"].(string)
+
+	// One caller never turned up, another was checked in and has finished
+	missed := &models.Appointment{UserPhone: "+15550002222", DateTime: testNow.Add(-time.Hour), Duration: 30, Status: models.StatusBooked}
+	attended := &models.Appointment{UserPhone: "+15550003333", DateTime: testNow.Add(-2 * time.Hour), Duration: 30, Status: models.StatusBooked}
+	for _, apt := range []*models.Appointment{missed, attended} {
+		if err := store.CreateAppointment(apt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lifecycle.Transition(attended, models.StatusCheckedIn, testNow.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateAppointment(attended); err != nil {
+		t.Fatal(err)
+	}
+	if err := lifecycle.Transition(attended, models.StatusNoShow, testNow); !errors.Is(err, lifecycle.ErrInvalidTransition) {
+		t.Fatalf("expected checked-in appointments to reject no_show, got %v", err)
+	}
+
+	sweeper := lifecycle.NewService(&config.Config{NoShowGraceMinutes: 15}, store)
+	defer sweeper.Stop()
+	if err := sweeper.Sweep(testNow); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() (interface{}, error)
+		success bool
+		id      string
+		status  string
+	}{
+		{"confirming", func() (interface{}, error) {
+			return executor.confirmAppointment(map[string]interface{}{"appointment_id": id})
+		}, true, id, models.StatusConfirmed},
+		{"rescheduling", func() (interface{}, error) {
+			return executor.modifyAppointment(map[string]interface{}{"appointment_id": id, "new_date_time": slot.Add(time.Hour).Format(time.RFC3339)})
+		}, true, id, models.StatusRescheduled},
+		{"a missed appointment is a no-show", nil, true, missed.ID, models.StatusNoShow},
+		{"a checked-in one has completed", nil, true, attended.ID, models.StatusCompleted},
+		{"a no-show can't be cancelled", func() (interface{}, error) {
+			return executor.cancelAppointment(map[string]interface{}{"appointment_id": missed.ID})
+		}, false, missed.ID, models.StatusNoShow},
+	}
+	for _, tt := range tests {
+		if tt.call != nil {
+			result, err := tt.call()
+			if err != nil || result.(map[string]interface{})["success"] != tt.success {
+				t.Errorf("%s: got %v %v", tt.name, result, err)
+			}
+		}
+		if got, _ := store.GetAppointmentByID(tt.id); got.Status != tt.status {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.status, got.Status)
+		}
+	}
+
+	// A rescheduled appointment still occupies its slot
+	available, err := store.CheckSlotAvailability("", slot.Add(time.Hour), 30)
+	if err != nil || available {
+		t.Fatalf("expected the rescheduled slot to be taken: %v %v", available, err)
+	}
+
+	result, err = executor.retrieveAppointments(map[string]interface{}{"type": "all"})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	for _, apt := range result.(map[string]interface{})["appointments"].([]map[string]interface{}) {
+		if apt["id"] == id && (apt["confirmed_at"] == nil || apt["rescheduled_at"] == nil) {
+			t.Errorf("expected lifecycle timestamps in %v", apt)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// paymentSucceeded is the Stripe status of a completed charge or intent
+const paymentSucceeded = "succeeded"
+
+// processPayment takes payment for an appointment: a charge when the client
+// supplied a card token, otherwise a PaymentIntent the client completes
+func (e *ToolExecutor) processPayment(args map[string]interface{}) (interface{}, error) {
+	if e.userPhone == "" {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "User not identified. Please identify the user first.",
+		}, nil
+	}
+
+	appointmentID, ok := args["appointment_id"].(string)
+	if !ok || appointmentID == "" {
+		return nil, fmt.Errorf("appointment_id is required")
+	}
+
+	appointment, err := e.store.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	if appointment == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Appointment not found",
+		}, nil
+	}
+	if appointment.UserPhone != e.userPhone {
+		log.Printf("[processPayment] ERROR: Phone mismatch - appointment phone: %s, user phone: %s", appointment.UserPhone, e.userPhone)
+		return map[string]interface{}{
+			"success": false,
+			"error":   "You can only pay for your own appointments",
+		}, nil
+	}
+	if appointment.Status == models.StatusCancelled {
+		return map[string]interface{}{
+			"success": false,
+			"status":  appointment.Status,
+			"error":   "This appointment is cancelled and cannot be paid for",
+		}, nil
+	}
+	open, err := e.refreshPayment(appointment)
+	if err != nil {
+		log.Printf("[processPayment] ERROR: Failed to check payment %s: %v", appointment.PaymentID, err)
+		return map[string]interface{}{
+			"success": false,
+			"error":   "The earlier payment for this appointment could not be checked. Please try again later.",
+		}, nil
+	}
+	if appointment.PaymentStatus == paymentSucceeded {
+		return map[string]interface{}{
+			"success":        true,
+			"appointment_id": appointmentID,
+			"payment_id":     appointment.PaymentID,
+			"status":         appointment.PaymentStatus,
+			"message":        fmt.Sprintf("Appointment on %s is already paid", e.formatDateTime(appointment.DateTime)),
+		}, nil
+	}
+
+	amount := appointment.PriceCents
+	if amount <= 0 {
+		amount = payment.CalculateAppointmentCost(nil, appointment.Duration)
+	}
+
+	if e.payments == nil {
+		log.Printf("[processPayment] Payments are not configured")
+		return map[string]interface{}{
+			"success": false,
+			"amount":  payment.FormatAmount(amount),
+			"error":   fmt.Sprintf("Payments can't be taken over the phone right now. The %s can be paid at the appointment.", payment.FormatAmount(amount)),
+		}, nil
+	}
+
+	description := fmt.Sprintf("Appointment on %s", e.formatDateTime(appointment.DateTime))
+	var client map[string]interface{}
+	token, _ := args["payment_token"].(string)
+	if open != nil && token != "" {
+		// Close the open intent before charging the card, so the caller
+		// cannot pay twice
+		if open.Status == string(stripe.PaymentIntentStatusProcessing) {
+			return map[string]interface{}{
+				"success": false,
+				"amount":  payment.FormatAmount(open.Amount),
+				"status":  open.Status,
+				"error":   "A payment for this appointment is already being processed. Check again in a few minutes.",
+			}, nil
+		}
+		if err := e.payments.CancelPaymentIntent(open.ID); err != nil {
+			log.Printf("[processPayment] ERROR: Failed to cancel payment %s: %v", open.ID, err)
+			return map[string]interface{}{
+				"success": false,
+				"amount":  payment.FormatAmount(amount),
+				"error":   "The payment that is already open for this appointment could not be closed. Please try again later.",
+			}, nil
+		}
+		open = nil
+	}
+	if open != nil {
+		// Pay the intent already opened rather than charging again
+		amount = open.Amount
+		client = intentClient(open)
+	} else if token != "" {
+		record, err := e.payments.ProcessPayment(e.userPhone, e.userName, amount, token, description)
+		if err != nil {
+			log.Printf("[processPayment] ERROR: Charge failed: %v", err)
+			return map[string]interface{}{
+				"success": false,
+				"amount":  payment.FormatAmount(amount),
+				"error":   "The payment was declined. Please try another card.",
+			}, nil
+		}
+		appointment.PaymentID = record.StripeChargeID
+		appointment.PaymentStatus = record.Status
+	} else if !e.payments.CollectsOnScreen() {
+		log.Printf("[processPayment] No publishable key, so no payment form can be shown")
+		return map[string]interface{}{
+			"success": false,
+			"amount":  payment.FormatAmount(amount),
+			"error":   fmt.Sprintf("Card payments can't be taken on screen right now. The %s can be paid at the appointment.", payment.FormatAmount(amount)),
+		}, nil
+	} else {
+		intent, err := e.payments.CreatePaymentIntent(e.userPhone, e.userName, appointmentID, amount, description)
+		if err != nil {
+			log.Printf("[processPayment] ERROR: Failed to create payment intent: %v", err)
+			return map[string]interface{}{
+				"success": false,
+				"amount":  payment.FormatAmount(amount),
+				"error":   "The payment could not be started. Please try again later.",
+			}, nil
+		}
+		appointment.PaymentID = intent.ID
+		appointment.PaymentStatus = intent.Status
+		client = intentClient(intent)
+	}
+	if appointment.PaymentStatus == paymentSucceeded {
+		now := e.now()
+		appointment.PaidAt = &now
+	}
+
+	if err := e.store.UpdateAppointment(appointment); err != nil {
+		log.Printf("[processPayment] ERROR: Failed to record payment %s: %v", appointment.PaymentID, err)
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	log.Printf("[processPayment] SUCCESS: Payment %s for appointment %s is %s", appointment.PaymentID, appointmentID, appointment.PaymentStatus)
+
+	message := fmt.Sprintf("Payment of %s for the appointment on %s went through", payment.FormatAmount(amount), e.formatDateTime(appointment.DateTime))
+	if appointment.PaymentStatus != paymentSucceeded {
+		message = fmt.Sprintf("A payment of %s has been started. A payment form has been sent to the caller's screen; ask them to finish it there", payment.FormatAmount(amount))
+	}
+	result := map[string]interface{}{
+		"success":        true,
+		"appointment_id": appointmentID,
+		"amount":         payment.FormatAmount(amount),
+		"amount_cents":   amount,
+		"payment_id":     appointment.PaymentID,
+		"status":         appointment.PaymentStatus,
+		"message":        message,
+	}
+	if client == nil {
+		return result, nil
+	}
+	return ClientResult{Result: result, Client: client}, nil
+}
+
+// refreshPayment brings the appointment's payment status up to date with
+// the payment intent opened for it, saving any change, and returns that
+// intent while it can still be paid
+func (e *ToolExecutor) refreshPayment(appointment *models.Appointment) (*payment.PaymentIntent, error) {
+	if e.payments == nil || !strings.HasPrefix(appointment.PaymentID, "pi_") || appointment.PaymentStatus == paymentSucceeded {
+		return nil, nil
+	}
+	intent, err := e.payments.GetPaymentIntent(appointment.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != appointment.PaymentStatus {
+		appointment.PaymentStatus = intent.Status
+		if intent.Status == paymentSucceeded {
+			now := e.now()
+			appointment.PaidAt = &now
+		}
+		if err := e.store.UpdateAppointment(appointment); err != nil {
+			return nil, fmt.Errorf("failed to record payment status: %w", err)
+		}
+	}
+	if !payment.IsOpenIntentStatus(intent.Status) {
+		return nil, nil
+	}
+	return intent, nil
+}
+
+// intentClient is the client-only data for paying one payment intent
+func intentClient(intent *payment.PaymentIntent) map[string]interface{} {
+	return map[string]interface{}{
+		"payment_intent_id": intent.ID,
+		"client_secret":     intent.ClientSecret,
+		"amount_cents":      intent.Amount,
+		"currency":          intent.Currency,
+		"publishable_key":   intent.PublishableKey,
+	}
+}
@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// fakeStripe serves the payment intent and charge endpoints the payment
+// service uses, keeping intents in memory
+type fakeStripe struct {
+	mu      sync.Mutex
+	intents map[string]map[string]interface{}
+	created int
+	charged int
+}
+
+// newFakeStripe points the Stripe client at a fake API for the test
+func newFakeStripe(t *testing.T) *fakeStripe {
+	f := &fakeStripe{intents: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(server.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	t.Cleanup(func() {
+		stripe.SetBackend(stripe.APIBackend, nil)
+		server.Close()
+	})
+	return f
+}
+
+func (f *fakeStripe) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_ = r.ParseForm()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	var body interface{}
+	switch {
+	case r.Method == http.MethodPost && path == "payment_intents":
+		f.created++
+		id := fmt.Sprintf("pi_%d", f.created)
+		var amount int64
+		fmt.Sscan(r.PostForm.Get("amount"), &amount)
+		f.intents[id] = map[string]interface{}{
+			"id":            id,
+			"object":        "payment_intent",
+			"amount":        amount,
+			"currency":      "usd",
+			"client_secret": id + "_secret",
+			"status":        "requires_payment_method",
+		}
+		body = f.intents[id]
+	case r.Method == http.MethodPost && path == "charges":
+		f.charged++
+		body = map[string]interface{}{"id": fmt.Sprintf("ch_%d", f.charged), "object": "charge", "status": "succeeded"}
+	case strings.HasSuffix(path, "/cancel"):
+		intent := f.intents[strings.TrimSuffix(strings.TrimPrefix(path, "payment_intents/"), "/cancel")]
+		if intent == nil {
+			http.NotFound(w, r)
+			return
+		}
+		intent["status"] = "canceled"
+		body = intent
+	case strings.HasPrefix(path, "payment_intents/"):
+		intent := f.intents[strings.TrimPrefix(path, "payment_intents/")]
+		if intent == nil {
+			http.NotFound(w, r)
+			return
+		}
+		body = intent
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// setStatus changes an intent's status, as paying or abandoning it would
+func (f *fakeStripe) setStatus(id, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intents[id]["status"] = status
+}
+
+func (f *fakeStripe) counts() (created, charged int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.created, f.charged
+}
+
+func TestProcessPaymentReusesTheOpenIntent(t *testing.T) {
+	fake := newFakeStripe(t)
+	store := database.NewMemoryStore()
+	var secrets []interface{}
+	executor := NewToolExecutor(Services{
+		Store:    store,
+		Slots:    newTestEngine(store),
+		Payments: payment.NewPaymentService(&config.Config{StripeSecretKey: "sk_test_fake", StripePublishableKey: "pk_test_fake"}),
+		Now:      testClock,
+	}, "session", nil, func(p models.ToolResultPayload) {
+		if p.Client != nil {
+			secrets = append(secrets, p.Client["client_secret"])
+			if p.Client["publishable_key"] != "pk_test_fake" {
+				t.Errorf("expected the publishable key for the payment form, got %v", p.Client)
+			}
+		}
+	})
+	executor.SetUserIdentity("+15550008888", "Payer")
+
+	book := func(offset time.Duration) string {
+		slot := testNow.Add(48*time.Hour + offset).Format(time.RFC3339)
+		booked, err := executor.bookAppointment(map[string]interface{}{"date_time": slot})
+		if err != nil || booked.(map[string]interface{})["success"] != true {
+			t.Fatalf("expected the booking to succeed, got %v %v", booked, err)
+		}
+		return booked.(map[string]interface{})["appointment_id"].(string)
+	}
+	pay := func(id, token string) map[string]interface{} {
+		args := `{"appointment_id": "` + id + `"}`
+		if token != "" {
+			args = `{"appointment_id": "` + id + `", "payment_token": "` + token + `"}`
+		}
+		result, err := executor.ExecuteTool(ToolProcessPayment, []byte(args))
+		if err != nil {
+			t.Fatalf("process payment: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	first, second, third := book(0), book(2*time.Hour), book(4*time.Hour)
+	tests := []struct {
+		name     string
+		id       string
+		token    string
+		before   func()
+		status   string
+		created  int
+		charged  int
+		secret   interface{}
+		paidAt   bool
+		resultID string
+	}{
+		{"opens an intent", first, "", nil, "requires_payment_method", 1, 0, "pi_1_secret", false, "pi_1"},
+		{"asking again reuses it", first, "", nil, "requires_payment_method", 1, 0, "pi_1_secret", false, "pi_1"},
+		{"paid on the form since", first, "", func() { fake.setStatus("pi_1", "succeeded") }, "succeeded", 1, 0, nil, true, "pi_1"},
+		{"paying again does not charge", first, "tok_visa", nil, "succeeded", 1, 0, nil, true, "pi_1"},
+		{"another appointment opens its own", second, "", nil, "requires_payment_method", 2, 0, "pi_2_secret", false, "pi_2"},
+		{"a card closes the open intent", second, "tok_visa", nil, "succeeded", 2, 1, nil, true, "ch_1"},
+		{"a third appointment opens its own", third, "", nil, "requires_payment_method", 3, 1, "pi_3_secret", false, "pi_3"},
+		{"a cancelled intent is replaced", third, "", func() { fake.setStatus("pi_3", "canceled") }, "requires_payment_method", 4, 1, "pi_4_secret", false, "pi_4"},
+	}
+	for _, tt := range tests {
+		if tt.before != nil {
+			tt.before()
+		}
+		secrets = nil
+		result := pay(tt.id, tt.token)
+		created, charged := fake.counts()
+		if result["success"] != true || result["status"] != tt.status || result["payment_id"] != tt.resultID {
+			t.Errorf("%s: got %v", tt.name, result)
+		}
+		if created != tt.created || charged != tt.charged {
+			t.Errorf("%s: %d intents and %d charges, want %d and %d", tt.name, created, charged, tt.created, tt.charged)
+		}
+		if (tt.secret == nil) != (len(secrets) == 0) || (tt.secret != nil && secrets[0] != tt.secret) {
+			t.Errorf("%s: sent client secrets %v, want %v", tt.name, secrets, tt.secret)
+		}
+		if apt, _ := store.GetAppointmentByID(tt.id); apt.PaymentStatus != tt.status || (apt.PaidAt != nil) != tt.paidAt {
+			t.Errorf("%s: stored %s paid at %v", tt.name, apt.PaymentStatus, apt.PaidAt)
+		}
+	}
+	if fake.intents["pi_2"]["status"] != "canceled" {
+		t.Errorf("expected the open intent to be cancelled before the card was charged, got %v", fake.intents["pi_2"]["status"])
+	}
+
+	// Without a publishable key the client cannot show a form, so only a
+	// card token is charged
+	executor.payments = payment.NewPaymentService(&config.Config{StripeSecretKey: "sk_test_fake"})
+	fourth := book(6 * time.Hour)
+	if result := pay(fourth, ""); result["success"] != false || !strings.Contains(result["error"].(string), "paid at the appointment") {
+		t.Errorf("expected no payment form without a publishable key, got %v", result)
+	}
+	if result := pay(fourth, "tok_visa"); result["success"] != true || result["status"] != "succeeded" {
+		t.Errorf("expected a card token to be charged without a publishable key, got %v", result)
+	}
+	if created, _ := fake.counts(); created != 4 {
+		t.Errorf("expected no intent without a publishable key, got %d", created)
+	}
+}
+
+func TestProcessPaymentChecksOwnershipAndConfiguration(t *testing.T) {
+	store := database.NewMemoryStore()
+	var payloads []models.ToolResultPayload
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Now: testClock}, "session", nil, func(p models.ToolResultPayload) {
+		payloads = append(payloads, p)
+	})
+	executor.SetUserIdentity("+15550009999", "Payer")
+	other := newTestExecutor(store, "other")
+	other.SetUserIdentity("+15550001111", "Someone Else")
+
+	slot := testNow.Add(48 * time.Hour).Format(time.RFC3339)
+	booked, err := executor.bookAppointment(map[string]interface{}{"date_time": slot})
+	if err != nil || booked.(map[string]interface{})["success"] != true {
+		t.Fatalf("expected the booking to succeed, got %v %v", booked, err)
+	}
+	id := booked.(map[string]interface{})["appointment_id"].(string)
+
+	tests := []struct {
+		name       string
+		caller     *ToolExecutor
+		withAmount bool
+	}{
+		{"someone else's appointment", other, false},
+		{"without Stripe the amount is still given", executor, true},
+	}
+	for _, tt := range tests {
+		result, err := tt.caller.ExecuteTool(ToolProcessPayment, []byte(`{"appointment_id": "`+id+`"}`))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m := result.(map[string]interface{}); m["success"] != false || (m["amount"] != nil) != tt.withAmount {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+		if apt, _ := store.GetAppointmentByID(id); apt.PaymentID != "" || apt.PaidAt != nil {
+			t.Errorf("%s: expected no payment to be recorded, got %+v", tt.name, apt)
+		}
+	}
+
+	// Client-only data reaches the tool_result event but not the LLM
+	registry := newDefaultRegistry()
+	registry.Register(NewTool("test_secret", "", map[string]interface{}{"type": "object"},
+		func(e *ToolExecutor, args map[string]interface{}) (interface{}, error) {
+			return ClientResult{Result: map[string]interface{}{"success": true}, Client: map[string]interface{}{"client_secret": "pi_secret"}}, nil
+		}))
+	executor.registry = registry
+	result, _ := executor.ExecuteTool("test_secret", nil)
+	if _, ok := result.(map[string]interface{}); !ok {
+		t.Fatalf("expected the LLM result to be unwrapped, got %#v", result)
+	}
+	last := payloads[len(payloads)-1]
+	if last.Client["client_secret"] != "pi_secret" {
+		t.Fatalf("expected the client secret on the tool_result payload, got %+v", last)
+	}
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/policy"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// cancelDecision applies the cancellation policy; without one anything goes
+func (e *ToolExecutor) cancelDecision(appointment *models.Appointment, now time.Time) policy.Decision {
+	if e.policy == nil {
+		return policy.Decision{Allowed: true}
+	}
+	return e.policy.Cancel(appointment, now)
+}
+
+// rescheduleDecision applies the rescheduling policy; without one anything goes
+func (e *ToolExecutor) rescheduleDecision(appointment *models.Appointment, now time.Time) policy.Decision {
+	if e.policy == nil {
+		return policy.Decision{Allowed: true}
+	}
+	return e.policy.Reschedule(appointment, now)
+}
+
+// chargeFee records the decision's fee on the appointment and opens its
+// payment, returning the intent the caller pays it with or nil
+func (e *ToolExecutor) chargeFee(appointment *models.Appointment, decision policy.Decision) *payment.PaymentIntent {
+	if e.policy == nil {
+		return nil
+	}
+	return e.policy.ChargeFee(appointment, e.userName, decision)
+}
+
+// feeConfirmation asks the agent to get the caller's agreement to a fee
+// before cancelling
+func feeConfirmation(decision policy.Decision, feeCents int64) map[string]interface{} {
+	fee := decision.Reason
+	if feeCents != decision.FeeCents {
+		fee = fmt.Sprintf("%s per appointment, %s in total", decision.Reason, payment.FormatAmount(feeCents))
+	}
+	return map[string]interface{}{
+		"success":      false,
+		"fee_required": true,
+		"fee":          payment.FormatAmount(feeCents),
+		"fee_cents":    feeCents,
+		"policy":       decision,
+		"message":      fee + ". Ask the caller whether they want to proceed; if they agree, call cancel_appointment again with accept_fee set to true.",
+	}
+}
+
+// addFee adds the fee owed for a cancellation to a tool result, and what
+// became of its payment: paid, open on the caller's screen, or left for the
+// office to collect when no payment could be opened
+func addFee(result map[string]interface{}, feeCents int64, intents []*payment.PaymentIntent) {
+	if feeCents <= 0 {
+		return
+	}
+	result["fee"] = payment.FormatAmount(feeCents)
+	result["fee_cents"] = feeCents
+
+	message := fmt.Sprintf("%s. A late cancellation fee of %s applies", result["message"], payment.FormatAmount(feeCents))
+	if len(intents) == 0 {
+		result["fee_payment_status"] = "not_started"
+		result["message"] = message + ". No payment could be taken now, so the office will collect it later"
+		return
+	}
+
+	var ids []string
+	var opened int64
+	paid := true
+	for _, intent := range intents {
+		ids = append(ids, intent.ID)
+		opened += intent.Amount
+		paid = paid && intent.Status == paymentSucceeded
+	}
+	result["fee_payment_id"] = strings.Join(ids, ",")
+	result["fee_payment_status"] = intents[0].Status
+	if paid {
+		message += " and has been paid"
+	} else {
+		message += ". It has not been paid yet; a payment form for it has been sent to the caller's screen"
+	}
+	if opened < feeCents {
+		message += fmt.Sprintf(". The office will collect the remaining %s later", payment.FormatAmount(feeCents-opened))
+	}
+	result["message"] = message
+}
+
+// feeClient is the client-only data for paying fees: each payment intent
+// with its client secret
+func feeClient(intents []*payment.PaymentIntent) map[string]interface{} {
+	payments := make([]map[string]interface{}, 0, len(intents))
+	for _, intent := range intents {
+		payments = append(payments, intentClient(intent))
+	}
+	return map[string]interface{}{"fee_payments": payments}
+}
+
+// upcomingAppointments returns the caller's upcoming appointments for the
+// booking limits; without a policy there are no limits to check
+func (e *ToolExecutor) upcomingAppointments() ([]models.Appointment, error) {
+	if e.policy == nil {
+		return nil, nil
+	}
+	return e.store.GetUpcomingAppointments(e.userPhone)
+}
+
+// limitGuard checks the maximum number of upcoming appointments before adding more
+func (e *ToolExecutor) limitGuard(upcoming []models.Appointment, adding int) *policy.Guard {
+	if e.policy == nil {
+		return nil
+	}
+	return e.policy.CheckLimit(upcoming, adding)
+}
+
+// conflictGuard checks an appointment against the caller's others; a
+// near-duplicate passes once the caller has confirmed it
+func (e *ToolExecutor) conflictGuard(appointment *models.Appointment, upcoming []models.Appointment, confirmDuplicate bool) *policy.Guard {
+	if e.policy == nil {
+		return nil
+	}
+	guard := e.policy.CheckConflicts(appointment, upcoming)
+	if guard != nil && guard.Warning && confirmDuplicate {
+		return nil
+	}
+	return guard
+}
+
+// guardResult turns a booking guard into a tool result the agent can relay,
+// asking it to confirm a near-duplicate with the caller
+func (e *ToolExecutor) guardResult(guard *policy.Guard) map[string]interface{} {
+	result := map[string]interface{}{
+		"success": false,
+		"error":   guard.Message,
+		"guard":   guard,
+	}
+	if guard.Existing != nil {
+		result["existing_appointment"] = map[string]interface{}{
+			"appointment_id": guard.Existing.ID,
+			"date_time":      e.formatDateTime(guard.Existing.DateTime),
+			"purpose":        guard.Existing.Purpose,
+		}
+	}
+	if guard.Warning {
+		result["duplicate_warning"] = true
+		result["message"] = guard.Message + ". Ask the caller whether they really want another one; if so, call book_appointment again with confirm_duplicate set to true."
+	}
+	return result
+}
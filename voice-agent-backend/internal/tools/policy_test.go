@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/policy"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+func TestCancellationPolicyChargesLateFeeAndLimitsReschedules(t *testing.T) {
+	store := database.NewMemoryStore()
+	engine := policy.NewEngine(&policy.Policy{
+		LateCancelWindow:   24 * time.Hour,
+		LateCancelFeeCents: 2000,
+		RescheduleNotice:   2 * time.Hour,
+		MaxReschedules:     1,
+	}, nil)
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Policy: engine, Now: testClock}, "session", nil, nil)
+	executor.SetUserIdentity("+15550004444", "Caller")
+
+	book := func(at time.Time) string {
+		t.Helper()
+		result, err := executor.bookAppointment(map[string]interface{}{"date_time": at.Format(time.RFC3339)})
+		if err != nil || result.(map[string]interface{})["success"] != true {
+			t.Fatalf("booking %s: %v %v", at, result, err)
+		}
+		return result.(map[string]interface{})["appointment_id"].(string)
+	}
+	// Tomorrow morning's appointment is inside the late window; the other
+	// is far enough out to cancel for free
+	later := testNow.Add(72 * time.Hour)
+	soon, free := book(testNow.Add(12*time.Hour)), book(later)
+
+	cancel := func(id string, acceptFee bool) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			return executor.cancelAppointment(map[string]interface{}{"appointment_id": id, "accept_fee": acceptFee})
+		}
+	}
+	move := func(id string, to time.Time) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			return executor.modifyAppointment(map[string]interface{}{"appointment_id": id, "new_date_time": to.Format(time.RFC3339)})
+		}
+	}
+
+	tests := []struct {
+		name    string
+		call    func() (interface{}, error)
+		want    map[string]interface{}
+		message string
+		id      string
+		status  string
+	}{
+		{"a late cancellation needs the fee accepted", cancel(soon, false),
+			map[string]interface{}{"fee_required": true, "fee": "$20.00"}, "24 hours", soon, models.StatusBooked},
+		{"accepting the fee cancels", cancel(soon, true),
+			map[string]interface{}{"success": true, "fee_cents": int64(2000), "fee_payment_status": "not_started"}, "collect it later", soon, models.StatusCancelled},
+		{"one reschedule is allowed", move(free, later.Add(time.Hour)),
+			map[string]interface{}{"success": true}, "", free, models.StatusRescheduled},
+		{"a second is not", move(free, later.Add(2*time.Hour)),
+			map[string]interface{}{"success": false}, "", free, models.StatusRescheduled},
+		{"an early cancellation is free", cancel(free, false),
+			map[string]interface{}{"success": true, "fee": nil}, "", free, models.StatusCancelled},
+	}
+	for _, tt := range tests {
+		result, err := tt.call()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		for key, want := range tt.want {
+			if m[key] != want {
+				t.Errorf("%s: %s is %v, want %v in %v", tt.name, key, m[key], want, m)
+			}
+		}
+		if !strings.Contains(fmt.Sprint(m["message"]), tt.message) {
+			t.Errorf("%s: expected the message to mention %q, got %v", tt.name, tt.message, m["message"])
+		}
+		if apt, _ := store.GetAppointmentByID(tt.id); apt.Status != tt.status {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.status, apt.Status)
+		}
+	}
+	if apt, _ := store.GetAppointmentByID(soon); apt.FeeCents != 2000 {
+		t.Errorf("expected the late cancellation to carry the fee, got %+v", apt)
+	}
+}
+
+func TestFeeResultReportsThePaymentStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		intents []*payment.PaymentIntent
+		status  string
+		message string
+	}{
+		{"no payment", nil, "not_started", "office will collect it later"},
+		{"open", []*payment.PaymentIntent{{ID: "pi_1", Amount: 2000, Status: "requires_payment_method"}}, "requires_payment_method", "has not been paid yet"},
+		{"paid", []*payment.PaymentIntent{{ID: "pi_1", Amount: 2000, Status: paymentSucceeded}}, paymentSucceeded, "has been paid"},
+		{"partly opened", []*payment.PaymentIntent{{ID: "pi_1", Amount: 1000, Status: "requires_payment_method"}}, "requires_payment_method", "remaining $10.00"},
+	}
+	for _, tt := range tests {
+		result := map[string]interface{}{"message": "Cancelled"}
+		addFee(result, 2000, tt.intents)
+		if result["fee_payment_status"] != tt.status || !strings.Contains(result["message"].(string), tt.message) {
+			t.Errorf("%s: unexpected result %v", tt.name, result)
+		}
+	}
+}
+
+func TestBookingLimitsRefuseOverlapsAndExcessAndWarnOnDuplicates(t *testing.T) {
+	store := database.NewMemoryStore()
+	engine := policy.NewEngine(&policy.Policy{
+		MaxUpcoming:     3,
+		PreventOverlap:  true,
+		DuplicateWindow: 7 * 24 * time.Hour,
+	}, nil)
+	executor := NewToolExecutor(Services{Store: store, Slots: newTestEngine(store), Policy: engine, Now: testClock}, "session", nil, nil)
+	executor.SetUserIdentity("+15550005555", "Caller")
+
+	day := testNow.Add(48 * time.Hour)
+	tests := []struct {
+		name     string
+		at       time.Time
+		purpose  string
+		confirm  bool
+		success  bool
+		guard    string
+		existing bool // the result names the appointment in the way
+	}{
+		{"the first booking", day, "Checkup", false, true, "", false},
+		// Nobody else holds the slot, but the caller does
+		{"the same caller at the same time", day.Add(15 * time.Minute), "Filling", false, false, policy.GuardOverlap, true},
+		{"another checkup the same week", day.Add(2 * time.Hour), "checkup", false, false, policy.GuardDuplicate, true},
+		{"the checkup once the caller confirms", day.Add(2 * time.Hour), "checkup", true, true, "", false},
+		{"the third booking", day.Add(4 * time.Hour), "Filling", false, true, "", false},
+		{"one more than the limit", day.Add(6 * time.Hour), "Cleaning", false, false, policy.GuardMaxUpcoming, false},
+	}
+	for _, tt := range tests {
+		result, err := executor.bookAppointment(map[string]interface{}{
+			"date_time":         tt.at.Format(time.RFC3339),
+			"purpose":           tt.purpose,
+			"confirm_duplicate": tt.confirm,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		code := ""
+		if guard, ok := m["guard"].(*policy.Guard); ok {
+			code = guard.Code
+		}
+		if m["success"] != tt.success || code != tt.guard || (m["existing_appointment"] != nil) != tt.existing {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+		// A near-duplicate is a warning for the caller to confirm
+		if duplicate := tt.guard == policy.GuardDuplicate; (m["duplicate_warning"] == true) != duplicate {
+			t.Errorf("%s: expected duplicate_warning=%v, got %v", tt.name, duplicate, m)
+		}
+	}
+}
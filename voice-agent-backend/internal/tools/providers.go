@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// findProvider matches query against provider IDs and names. An empty query,
+// "any" or "anyone" selects whoever is free and returns nil. It reports false
+// when the query names no known provider.
+func findProvider(providers []models.Provider, query string) (*models.Provider, bool) {
+	query = strings.TrimSpace(query)
+	switch strings.ToLower(query) {
+	case "", "any", "anyone", "whoever is free":
+		return nil, true
+	}
+	if len(providers) == 0 {
+		// Single-calendar businesses ignore the provider argument
+		return nil, true
+	}
+
+	for i := range providers {
+		if providers[i].ID == query || strings.EqualFold(providers[i].Name, query) {
+			return &providers[i], true
+		}
+	}
+
+	// Fall back to a unique partial name match, e.g. "Patel" for "Dr. Patel"
+	var match *models.Provider
+	for i := range providers {
+		if strings.Contains(strings.ToLower(providers[i].Name), strings.ToLower(query)) {
+			if match != nil {
+				return nil, false
+			}
+			match = &providers[i]
+		}
+	}
+	return match, match != nil
+}
+
+func unknownProviderMessage(query string, providers []models.Provider) string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("No unique provider matches %q. Available providers: %s", query, strings.Join(names, ", "))
+}
+
+func providerName(providers []models.Provider, id string) string {
+	for _, p := range providers {
+		if p.ID == id {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// providerID returns the calendar ID for a provider; nil is the unassigned calendar
+func providerID(provider *models.Provider) string {
+	if provider == nil {
+		return ""
+	}
+	return provider.ID
+}
+
+// appointmentProvider loads the provider an appointment is assigned to, if any
+func (e *ToolExecutor) appointmentProvider(appointment *models.Appointment) (*models.Provider, error) {
+	if appointment.ProviderID == "" {
+		return nil, nil
+	}
+	provider, err := e.store.GetProviderByID(appointment.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	return provider, nil
+}
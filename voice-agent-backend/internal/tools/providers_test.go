@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestBookAppointmentWhoeverIsFreeUsesEachProvider(t *testing.T) {
+	store := database.NewMemoryStore()
+	for _, name := range []string{"Dr. Adams", "Dr. Baker", "Dr. Chen"} {
+		if err := store.CreateProvider(&models.Provider{Name: name, Kind: models.ProviderKindStaff, Active: true}); err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+	}
+
+	// Every caller asks for the same time, so each booking takes a provider
+	slot := testNow.Add(48 * time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name     string
+		provider string
+		success  bool
+		want     string // provider booked, or text in the error
+	}{
+		{"by partial name", "Baker", true, "Dr. Baker"},
+		{"the named one is taken", "Dr. Baker", false, "already booked"},
+		{"ambiguous name", "Dr.", false, "No unique provider"},
+		{"whoever is free", "", true, ""},
+		{"anyone", "anyone", true, ""},
+		{"everyone is busy", "", false, "already booked"},
+	}
+	seen := make(map[string]bool)
+	for i, tt := range tests {
+		executor := newTestExecutor(store, fmt.Sprintf("session-%d", i))
+		executor.SetUserIdentity(fmt.Sprintf("+1555000%04d", i), fmt.Sprintf("Caller %d", i))
+
+		result, err := executor.bookAppointment(map[string]interface{}{"date_time": slot, "provider": tt.provider})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		if m["success"] != tt.success {
+			t.Fatalf("%s: expected success=%v, got %v", tt.name, tt.success, m)
+		}
+		if !tt.success {
+			if !strings.Contains(fmt.Sprint(m["error"]), tt.want) {
+				t.Errorf("%s: expected an error mentioning %q, got %v", tt.name, tt.want, m)
+			}
+			continue
+		}
+		provider := m["provider"].(string)
+		if seen[provider] || (tt.want != "" && provider != tt.want) {
+			t.Errorf("%s: booked %s, already booked %v", tt.name, provider, seen)
+		}
+		seen[provider] = true
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Tool is a function the LLM can call: its name, a description telling the
+// model when to use it, a JSON schema of its arguments and the code that runs
+// it for a session.
+type Tool interface {
+	Name() string
+	Description() string
+	Parameters() map[string]interface{}
+	// Execute runs the tool for the session e. Results the LLM should act on
+	// (including refusals) are returned as a value, not an error.
+	Execute(e *ToolExecutor, args map[string]interface{}) (interface{}, error)
+}
+
+// NewTool builds a Tool from its parts
+func NewTool(name, description string, parameters map[string]interface{}, execute func(e *ToolExecutor, args map[string]interface{}) (interface{}, error)) Tool {
+	return &funcTool{name: name, description: description, parameters: parameters, execute: execute}
+}
+
+type funcTool struct {
+	name        string
+	description string
+	parameters  map[string]interface{}
+	execute     func(e *ToolExecutor, args map[string]interface{}) (interface{}, error)
+}
+
+func (t *funcTool) Name() string                       { return t.name }
+func (t *funcTool) Description() string                { return t.description }
+func (t *funcTool) Parameters() map[string]interface{} { return t.parameters }
+func (t *funcTool) Execute(e *ToolExecutor, args map[string]interface{}) (interface{}, error) {
+	return t.execute(e, args)
+}
+
+// ConfirmedTool is a Tool that changes the caller's bookings. The executor
+// does not run it when the LLM calls it: it holds the call as a pending
+// action, described by Summarize, until confirm_action follows a yes from
+// the caller.
+type ConfirmedTool interface {
+	Tool
+	Summarize(e *ToolExecutor, args map[string]interface{}) string
+}
+
+// NewConfirmedTool builds a ConfirmedTool from its parts
+func NewConfirmedTool(name, description string, parameters map[string]interface{}, execute func(e *ToolExecutor, args map[string]interface{}) (interface{}, error), summarize func(e *ToolExecutor, args map[string]interface{}) string) ConfirmedTool {
+	return &confirmedTool{
+		funcTool:  funcTool{name: name, description: description, parameters: parameters, execute: execute},
+		summarize: summarize,
+	}
+}
+
+type confirmedTool struct {
+	funcTool
+	summarize func(e *ToolExecutor, args map[string]interface{}) string
+}
+
+func (t *confirmedTool) Summarize(e *ToolExecutor, args map[string]interface{}) string {
+	return t.summarize(e, args)
+}
+
+// Registry holds the tools offered to the LLM. It both generates the tool
+// list sent with each request and dispatches the calls that come back, so
+// the two cannot drift apart.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool; names must be unique
+func (r *Registry) Register(tool Tool) error {
+	if tool.Name() == "" {
+		return fmt.Errorf("tool name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name()]; exists {
+		return fmt.Errorf("tool %s is already registered", tool.Name())
+	}
+	r.tools[tool.Name()] = tool
+	r.order = append(r.order, tool.Name())
+	return nil
+}
+
+// Lookup returns the tool registered under name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Definitions returns the OpenAI tool list, in registration order
+func (r *Registry) Definitions() []openai.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]openai.Tool, 0, len(r.order))
+	for _, name := range r.order {
+		tool := r.tools[name]
+		defs = append(defs, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  tool.Parameters(),
+			},
+		})
+	}
+	return defs
+}
+
+// defaultRegistry holds the built-in tools plus any added with Register
+var defaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, tool := range builtinTools() {
+		if err := r.Register(tool); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds a custom tool to every session. Call it at startup, before
+// the first session is created, so the tool is in the list the LLM is given.
+func Register(tool Tool) error {
+	return defaultRegistry.Register(tool)
+}
+
+// GetToolDefinitions returns all available tool definitions for the LLM
+func GetToolDefinitions() []openai.Tool {
+	return defaultRegistry.Definitions()
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestRegistryAdvertisesAndDispatchesEveryTool(t *testing.T) {
+	echo := NewTool("test_echo", "Echo the caller's phone number", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}, func(e *ToolExecutor, args map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"success": true, "phone": e.GetUserPhone()}, nil
+	})
+	registry := newDefaultRegistry()
+	if err := registry.Register(echo); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := registry.Register(echo); err == nil {
+		t.Fatal("expected a duplicate registration to fail")
+	}
+
+	// Every advertised tool has something behind it
+	for _, def := range registry.Definitions() {
+		if _, ok := registry.Lookup(def.Function.Name); !ok {
+			t.Fatalf("%s is advertised but not dispatched", def.Function.Name)
+		}
+	}
+
+	executor := newTestExecutor(database.NewMemoryStore(), "session")
+	executor.registry = registry
+	executor.SetUserIdentity("+15550007777", "Caller")
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    string
+		wantErr bool
+		phone   interface{}
+	}{
+		{"a registered tool runs", "test_echo", `{}`, false, "+15550007777"},
+		{"a built-in tool runs", ToolRetrieveAppointments, `{"type": "upcoming"}`, false, nil},
+		{"an unknown tool fails", "process_payment_typo", `{}`, true, nil},
+	}
+	for _, tt := range tests {
+		result, err := executor.ExecuteTool(tt.tool, []byte(tt.args))
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", tt.name, tt.wantErr, err)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if m := result.(map[string]interface{}); m["success"] != true || m["phone"] != tt.phone {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError is one tool argument that does not match the tool's schema
+type FieldError struct {
+	Field string      `json:"field"`
+	Error string      `json:"error"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// validateArgs checks args against a tool's JSON schema before it runs,
+// coercing what is safe to coerce in place: strings are trimmed, numeric
+// strings become numbers, "true"/"yes" become booleans, numbers become
+// strings and enum values are matched case-insensitively. Integers stay
+// float64, as encoding/json decodes them. Arguments the schema does not
+// declare are left alone. A null is an empty string, or not given for other
+// types; an empty enum value is not given either. A required string that is
+// blank counts as missing.
+func validateArgs(schema map[string]interface{}, args map[string]interface{}) []FieldError {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []FieldError
+	for _, name := range names {
+		value, given := args[name]
+		if !given {
+			continue
+		}
+		prop, _ := properties[name].(map[string]interface{})
+		coerced, problem := coerce(value, prop)
+		if problem != "" {
+			problems = append(problems, FieldError{Field: name, Error: problem, Value: value})
+			continue
+		}
+		if coerced == nil {
+			delete(args, name)
+		} else {
+			args[name] = coerced
+		}
+	}
+
+	for _, name := range stringList(schema["required"]) {
+		if value, given := args[name]; !given || value == "" {
+			problems = append(problems, FieldError{Field: name, Error: "is required"})
+		}
+	}
+	return problems
+}
+
+// coerce converts value to the property's type, or explains why it cannot
+func coerce(value interface{}, prop map[string]interface{}) (interface{}, string) {
+	kind, _ := prop["type"].(string)
+	switch kind {
+	case "string":
+		var s string
+		switch v := value.(type) {
+		case nil:
+		case string:
+			s = strings.TrimSpace(v)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			s = strconv.FormatBool(v)
+		default:
+			return nil, "must be a string"
+		}
+		if allowed := stringList(prop["enum"]); len(allowed) > 0 {
+			for _, option := range allowed {
+				if strings.EqualFold(s, option) {
+					return option, ""
+				}
+			}
+			if s == "" {
+				return nil, ""
+			}
+			return nil, "must be one of " + strings.Join(allowed, ", ")
+		}
+		return s, ""
+
+	case "integer", "number":
+		var n float64
+		switch v := value.(type) {
+		case nil:
+			return nil, ""
+		case float64:
+			n = v
+		case string:
+			trimmed := strings.TrimSpace(v)
+			if trimmed == "" {
+				return nil, ""
+			}
+			parsed, err := strconv.ParseFloat(trimmed, 64)
+			if err != nil {
+				return nil, "must be a " + numberKind(kind)
+			}
+			n = parsed
+		default:
+			return nil, "must be a " + numberKind(kind)
+		}
+		if kind == "integer" && n != math.Trunc(n) {
+			return nil, "must be a whole number"
+		}
+		return n, ""
+
+	case "boolean":
+		switch v := value.(type) {
+		case nil:
+			return nil, ""
+		case bool:
+			return v, ""
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "1":
+				return true, ""
+			case "false", "no", "0", "":
+				return false, ""
+			}
+		case float64:
+			if v == 0 || v == 1 {
+				return v == 1, ""
+			}
+		}
+		return nil, "must be true or false"
+	}
+	return value, ""
+}
+
+func numberKind(kind string) string {
+	if kind == "integer" {
+		return "whole number"
+	}
+	return "number"
+}
+
+// stringList reads a schema list written in Go ([]string) or decoded from JSON
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// invalidArguments is the result for a call whose arguments do not match the
+// tool's schema, listing every bad field so the LLM can fix them in one go
+func invalidArguments(toolName string, problems []FieldError) map[string]interface{} {
+	reasons := make([]string, len(problems))
+	for i, problem := range problems {
+		reasons[i] = problem.Field + " " + problem.Error
+	}
+	return map[string]interface{}{
+		"success":           false,
+		"error":             fmt.Sprintf("Invalid arguments for %s: %s. Correct them and call %s again.", toolName, strings.Join(reasons, "; "), toolName),
+		"invalid_arguments": problems,
+	}
+}
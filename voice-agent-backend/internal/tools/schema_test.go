@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestToolArgumentsAreCoercedOrReportedPerField(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550008888", "Caller")
+
+	slot := testNow.Add(48 * time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name   string
+		tool   string
+		args   string
+		fields []string // fields reported invalid, in order
+		reason string   // why the first one is, when it matters
+		want   map[string]interface{}
+	}{
+		{"strings are trimmed and coerced", ToolBookAppointment,
+			`{"date_time": "  ` + slot + ` ", "duration": "45", "confirm_duplicate": "no"}`, nil, "",
+			map[string]interface{}{"awaiting_confirmation": true}},
+		{"every bad field is reported", ToolBookAppointment,
+			`{"duration": 45.5, "recurrence": ["weekly"]}`, []string{"duration", "recurrence", "date_time"}, "",
+			map[string]interface{}{"success": false}},
+		{"a blank required string is missing", ToolBookAppointment,
+			`{"date_time": "   "}`, []string{"date_time"}, "is required",
+			map[string]interface{}{"success": false}},
+		{"an enum value in another case", ToolRetrieveAppointments,
+			`{"type": "UPCOMING"}`, nil, "",
+			map[string]interface{}{"success": true}},
+		{"malformed arguments", ToolRetrieveAppointments,
+			`not json`, []string{"arguments"}, "",
+			map[string]interface{}{"success": false}},
+	}
+	var pending map[string]interface{}
+	for _, tt := range tests {
+		result, err := executor.ExecuteTool(tt.tool, []byte(tt.args))
+		if err != nil {
+			t.Fatalf("%s: expected invalid arguments as a result, got %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		for key, want := range tt.want {
+			if m[key] != want {
+				t.Errorf("%s: %s is %v, want %v in %v", tt.name, key, m[key], want, m)
+			}
+		}
+		problems, _ := m["invalid_arguments"].([]FieldError)
+		if len(problems) != len(tt.fields) {
+			t.Fatalf("%s: expected %v reported, got %+v", tt.name, tt.fields, problems)
+		}
+		for i, field := range tt.fields {
+			if problems[i].Field != field {
+				t.Errorf("%s: expected %s to be reported, got %+v", tt.name, field, problems)
+			}
+		}
+		if tt.reason != "" && problems[0].Error != tt.reason {
+			t.Errorf("%s: expected %s %s, got %+v", tt.name, tt.fields[0], tt.reason, problems[0])
+		}
+		if m["awaiting_confirmation"] == true {
+			pending = m
+		}
+	}
+
+	// The coerced arguments are what gets booked
+	executor.RecordUserTurn("Yes please")
+	result, err := executor.ExecuteTool(ToolConfirmAction, []byte(`{"confirmation_token": "`+pending["confirmation_token"].(string)+`"}`))
+	if err != nil || result.(map[string]interface{})["success"] != true || result.(map[string]interface{})["duration"] != 45 {
+		t.Fatalf("expected coerced arguments to book, got %v %v", result, err)
+	}
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func (e *ToolExecutor) findNextAvailable(args map[string]interface{}) (interface{}, error) {
+	opts := availability.SearchOptions{
+		Duration: e.slots.DefaultDuration(),
+		Holder:   e.holder(),
+	}
+
+	if dateStr, _ := args["earliest_date"].(string); dateStr != "" {
+		from, _, err := e.parseDate(dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid earliest_date: %v. Use YYYY-MM-DD or words like \"next week\"", err)
+		}
+		opts.From = from
+	}
+	if weekdays, _ := args["weekdays"].(string); weekdays != "" {
+		days, err := availability.ParseWeekdays(weekdays)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weekdays: %w", err)
+		}
+		opts.Weekdays = days
+	}
+	if window, _ := args["time_window"].(string); window != "" {
+		windows, err := availability.ParseTimeRanges(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time_window: %w", err)
+		}
+		opts.Windows = windows
+	}
+	if d, ok := args["duration"].(float64); ok && d > 0 {
+		opts.Duration = int(d)
+	}
+	if n, ok := args["count"].(float64); ok && n > 0 {
+		opts.Limit = int(n)
+	}
+	if n, ok := args["days"].(float64); ok && n > 0 {
+		opts.Days = int(n)
+	}
+
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+
+	serviceID, _ := args["service_id"].(string)
+	service, err := e.findService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceID != "" && service == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Unknown service. Use list_services to see what we offer.",
+		}, nil
+	}
+	if service != nil {
+		if mismatch := durationMismatch(args, service); mismatch != nil {
+			return mismatch, nil
+		}
+		opts.Duration = service.Duration
+		opts.Buffer = service.BufferMinutes
+		providers = eligibleProviders(providers, service)
+	}
+
+	providerQuery, _ := args["provider"].(string)
+	provider, found := findProvider(providers, providerQuery)
+	if !found {
+		return map[string]interface{}{
+			"success": false,
+			"error":   unknownProviderMessage(providerQuery, providers),
+		}, nil
+	}
+	opts.Providers = providers
+	if provider != nil {
+		opts.Providers = []models.Provider{*provider}
+	}
+
+	matches, err := e.slots.FindNext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]map[string]interface{}, len(matches))
+	for i, slot := range matches {
+		slots[i] = map[string]interface{}{
+			"date_time": slot.DateTime.Format(time.RFC3339),
+			"when":      e.formatDateTime(slot.DateTime),
+			"duration":  slot.Duration,
+		}
+		if slot.ProviderID != "" {
+			slots[i]["provider_id"] = slot.ProviderID
+			slots[i]["provider"] = providerName(providers, slot.ProviderID)
+		}
+	}
+
+	message := "No available slots match those constraints. Try a later date, more days or a wider time window."
+	if len(matches) > 0 {
+		message = fmt.Sprintf("Found %d available slot(s); the earliest is %s", len(matches), e.formatDateTime(matches[0].DateTime))
+	}
+
+	result := map[string]interface{}{
+		"success": true,
+		"slots":   slots,
+		"count":   len(slots),
+		"message": message,
+	}
+	if service != nil {
+		result["service"] = service.Name
+	}
+	return result, nil
+}
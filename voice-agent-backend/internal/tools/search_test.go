@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+)
+
+func TestFindNextAvailableSkipsBookedSlots(t *testing.T) {
+	store := database.NewMemoryStore()
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550001111", "Caller")
+
+	tenAM := testNow.AddDate(0, 0, 2).Add(10 * time.Hour)
+	if _, err := executor.bookAppointment(map[string]interface{}{"date_time": tenAM.Format(time.RFC3339)}); err != nil {
+		t.Fatalf("booking: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want []time.Time
+	}{
+		{
+			"the next morning follows the booked slot",
+			map[string]interface{}{"time_window": "10:00-11:00", "count": float64(2)},
+			[]time.Time{tenAM.Add(30 * time.Minute), tenAM.AddDate(0, 0, 1)},
+		},
+		{
+			"only the given weekday",
+			map[string]interface{}{"time_window": "10:00-11:00", "count": float64(2), "weekdays": strings.ToLower(tenAM.Weekday().String())},
+			[]time.Time{tenAM.Add(30 * time.Minute), tenAM.AddDate(0, 0, 7)},
+		},
+		{
+			"only the given days",
+			map[string]interface{}{"time_window": "10:00-11:00", "count": float64(3), "days": float64(1)},
+			[]time.Time{tenAM.Add(30 * time.Minute)},
+		},
+	}
+	for _, tt := range tests {
+		tt.args["earliest_date"] = tenAM.Format("2006-01-02")
+		result, err := executor.findNextAvailable(tt.args)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		slots := result.(map[string]interface{})["slots"].([]map[string]interface{})
+		if len(slots) != len(tt.want) {
+			t.Fatalf("%s: expected %d slots, got %v", tt.name, len(tt.want), slots)
+		}
+		for i, w := range tt.want {
+			if slots[i]["date_time"] != w.Format(time.RFC3339) {
+				t.Errorf("%s: slot %d is %v, want %s", tt.name, i, slots[i]["date_time"], w.Format(time.RFC3339))
+			}
+		}
+	}
+}
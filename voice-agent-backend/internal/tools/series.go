@@ -0,0 +1,538 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/lifecycle"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/policy"
+	"github.com/voice-agent/backend/internal/recurrence"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+// bookSeries books every occurrence of a recurring series. Occurrences that
+// are outside hours, already taken or overlapping the caller's other
+// appointments are skipped and reported as conflicts. A series repeats by
+// design, so near-duplicates are not flagged. Occurrences point at the
+// series, so it is saved first and deleted again if none is booked.
+func (e *ToolExecutor) bookSeries(template *models.Appointment, candidates []*models.Provider, rrule string, service *models.Service, upcoming []models.Appointment) (interface{}, error) {
+	loc := e.slots.Schedule().Location
+	rule, err := recurrence.Parse(rrule, loc)
+	if err != nil {
+		log.Printf("[bookAppointment] ERROR: Invalid recurrence '%s': %v", rrule, err)
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Invalid recurrence rule: %v", err),
+		}, nil
+	}
+
+	occurrences := rule.Occurrences(template.DateTime, loc)
+	if guard := e.limitGuard(upcoming, len(occurrences)); guard != nil {
+		log.Printf("[bookAppointment] ERROR: Booking limit %s: %s", guard.Code, guard.Message)
+		result := e.guardResult(guard)
+		result["error"] = fmt.Sprintf("%s, so a series of %d cannot be booked", guard.Message, len(occurrences))
+		return result, nil
+	}
+
+	series := &models.AppointmentSeries{
+		ID:        uuid.New().String(),
+		UserPhone: template.UserPhone,
+		RRule:     rule.String(),
+		DTStart:   template.DateTime,
+		Timezone:  loc.String(),
+	}
+	if err := e.store.CreateAppointmentSeries(series); err != nil {
+		log.Printf("[bookAppointment] ERROR: Failed to create series: %v", err)
+		return nil, fmt.Errorf("failed to create appointment series: %w", err)
+	}
+
+	booked := []map[string]interface{}{}
+	conflicts := []map[string]interface{}{}
+	for _, occurrence := range occurrences {
+		appointment := *template
+		appointment.ID = uuid.New().String()
+		appointment.DateTime = occurrence
+		appointment.SeriesID = series.ID
+
+		if guard := e.conflictGuard(&appointment, upcoming, true); guard != nil {
+			conflicts = append(conflicts, map[string]interface{}{
+				"date_time": e.formatDateTime(occurrence),
+				"reason":    guard.Message,
+				"guard":     guard,
+			})
+			continue
+		}
+
+		placed, reason, err := e.placeAppointment(&appointment, candidates)
+		if err != nil {
+			if len(booked) == 0 {
+				e.deleteSeries(series)
+			}
+			return nil, err
+		}
+		if reason != "" {
+			conflicts = append(conflicts, map[string]interface{}{
+				"date_time": e.formatDateTime(occurrence),
+				"reason":    reason,
+			})
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"appointment_id": appointment.ID,
+			"date_time":      e.formatDateTime(occurrence),
+		}
+		if placed != nil {
+			entry["provider"] = placed.Name
+		}
+		booked = append(booked, entry)
+	}
+
+	log.Printf("[bookAppointment] Series %s: booked %d of %d occurrences", series.ID, len(booked), len(occurrences))
+
+	result := map[string]interface{}{
+		"success":     len(booked) > 0,
+		"recurrence":  series.RRule,
+		"duration":    template.Duration,
+		"purpose":     template.Purpose,
+		"booked":      booked,
+		"conflicts":   conflicts,
+		"occurrences": len(occurrences),
+		"message":     fmt.Sprintf("Booked %d of %d occurrences", len(booked), len(occurrences)),
+	}
+	if len(conflicts) > 0 {
+		result["message"] = fmt.Sprintf("Booked %d of %d occurrences; %d could not be booked (see conflicts)", len(booked), len(occurrences), len(conflicts))
+	}
+	if len(booked) == 0 {
+		e.deleteSeries(series)
+		result["error"] = "None of the occurrences could be booked. Please choose another time."
+	} else {
+		result["series_id"] = series.ID
+		e.addFeedLink(result, e.userPhone)
+	}
+	if service != nil {
+		result["service"] = service.Name
+		result["price"] = payment.FormatAmount(template.PriceCents)
+	}
+	return result, nil
+}
+
+// deleteSeries removes a series none of whose occurrences were booked
+func (e *ToolExecutor) deleteSeries(series *models.AppointmentSeries) {
+	if err := e.store.DeleteAppointmentSeries(series.ID); err != nil {
+		log.Printf("[bookAppointment] WARNING: Failed to delete empty series %s: %v", series.ID, err)
+	}
+}
+
+// cancelSeries cancels the booked occurrences selected by scope. Cancelling
+// this and following ends the series rule before this occurrence.
+func (e *ToolExecutor) cancelSeries(appointment *models.Appointment, scope, reason string, acceptFee bool) (interface{}, error) {
+	series, occurrences, err := e.seriesOccurrences(appointment, scope)
+	if err != nil {
+		log.Printf("[cancelAppointment] ERROR: Failed to load series: %v", err)
+		return nil, err
+	}
+
+	// Occurrences the policy refuses stay booked; fees on the rest are
+	// agreed to once for the whole series
+	now := e.now()
+	decisions := make([]policy.Decision, len(occurrences))
+	refused := []map[string]interface{}{}
+	var feeCents int64
+	var feeDecision policy.Decision
+	for i := range occurrences {
+		decisions[i] = e.cancelDecision(&occurrences[i], now)
+		if !decisions[i].Allowed {
+			refused = append(refused, map[string]interface{}{
+				"appointment_id": occurrences[i].ID,
+				"date_time":      e.formatDateTime(occurrences[i].DateTime),
+				"reason":         decisions[i].Reason,
+			})
+			continue
+		}
+		if decisions[i].FeeCents > 0 {
+			feeCents += decisions[i].FeeCents
+			feeDecision = decisions[i]
+		}
+	}
+	if len(occurrences) > 0 && len(refused) == len(occurrences) {
+		return map[string]interface{}{
+			"success": false,
+			"error":   decisions[0].Reason,
+			"refused": refused,
+		}, nil
+	}
+	if feeCents > 0 && !acceptFee {
+		return feeConfirmation(feeDecision, feeCents), nil
+	}
+
+	cancelled := []string{}
+	var charged int64
+	var intents []*payment.PaymentIntent
+	for i := range occurrences {
+		occurrence := &occurrences[i]
+		if !decisions[i].Allowed {
+			continue
+		}
+		if err := lifecycle.Transition(occurrence, models.StatusCancelled, now); err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			occurrence.Notes = fmt.Sprintf("%s\nCancellation reason: %s", occurrence.Notes, reason)
+		}
+		intent := e.chargeFee(occurrence, decisions[i])
+		if err := e.store.UpdateAppointment(occurrence); err != nil {
+			log.Printf("[cancelAppointment] ERROR: Failed to cancel occurrence %s: %v", occurrence.ID, err)
+			return nil, fmt.Errorf("failed to cancel appointment: %w", err)
+		}
+		charged += occurrence.FeeCents
+		if intent != nil {
+			intents = append(intents, intent)
+		}
+		cancelled = append(cancelled, e.formatDateTime(occurrence.DateTime))
+		e.offerToWaitlist(occurrence)
+	}
+
+	if series != nil && scope == scopeFollowing {
+		if rule, err := recurrence.Parse(series.RRule, e.seriesLocation(series)); err == nil {
+			rule.EndBefore(appointment.DateTime)
+			series.RRule = rule.String()
+			if err := e.store.UpdateAppointmentSeries(series); err != nil {
+				log.Printf("[cancelAppointment] WARNING: Failed to end series rule: %v", err)
+			}
+		}
+	}
+
+	log.Printf("[cancelAppointment] SUCCESS: Cancelled %d occurrence(s) of series %s", len(cancelled), appointment.SeriesID)
+
+	result := map[string]interface{}{
+		"success":        true,
+		"appointment_id": appointment.ID,
+		"series_id":      appointment.SeriesID,
+		"scope":          scope,
+		"cancelled":      cancelled,
+		"count":          len(cancelled),
+		"message":        fmt.Sprintf("Cancelled %d appointment(s) in the series", len(cancelled)),
+	}
+	if len(refused) > 0 {
+		result["refused"] = refused
+		result["message"] = fmt.Sprintf("Cancelled %d appointment(s) in the series; %d could not be cancelled (see refused)", len(cancelled), len(refused))
+	}
+	addFee(result, charged, intents)
+	if len(intents) > 0 {
+		return ClientResult{Result: result, Client: feeClient(intents)}, nil
+	}
+	return result, nil
+}
+
+// modifySeries applies a modification to the booked occurrences selected by
+// scope. A new time moves each occurrence by the same number of days to the
+// new time of day; occurrences that cannot move are left as they were and
+// reported as conflicts. Changing the time of this and following occurrences
+// splits them into a new series. If no occurrence can change, the series are
+// left as they were.
+func (e *ToolExecutor) modifySeries(appointment *models.Appointment, scope string, args map[string]interface{}) (interface{}, error) {
+	series, occurrences, err := e.seriesOccurrences(appointment, scope)
+	if err != nil {
+		return nil, err
+	}
+	loc := e.seriesLocation(series)
+
+	changes := []string{}
+	var newDateTime time.Time
+	if newDateTimeStr, ok := args["new_date_time"].(string); ok && newDateTimeStr != "" {
+		newDateTime, err = e.parseDateTime(newDateTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid new_date_time: %v", err)
+		}
+		if newDateTime.Before(e.now()) {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "Cannot reschedule to a past time",
+			}, nil
+		}
+		changes = append(changes, fmt.Sprintf("moved to %s", e.formatDateTime(newDateTime)))
+	}
+	newDuration := 0
+	if d, ok := args["new_duration"].(float64); ok && d > 0 {
+		newDuration = int(d)
+		changes = append(changes, fmt.Sprintf("duration changed to %d minutes", newDuration))
+	}
+	newPurpose, _ := args["new_purpose"].(string)
+	if newPurpose != "" {
+		changes = append(changes, "purpose updated")
+	}
+	newNotes, _ := args["new_notes"].(string)
+	if newNotes != "" {
+		changes = append(changes, "notes updated")
+	}
+	if len(changes) == 0 {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "No changes specified",
+		}, nil
+	}
+
+	// Move by whole days in the series timezone so the time of day survives DST
+	moving := !newDateTime.IsZero()
+	var dayDelta int
+	var shift func(t time.Time) time.Time
+	if moving {
+		from := appointment.DateTime.In(loc)
+		to := newDateTime.In(loc)
+		dayDelta = int(time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).
+			Sub(time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24)
+		shift = func(t time.Time) time.Time {
+			local := t.In(loc)
+			return time.Date(local.Year(), local.Month(), local.Day()+dayDelta, to.Hour(), to.Minute(), 0, 0, loc)
+		}
+	}
+
+	// Changing the time of this and following occurrences starts a new series,
+	// unless this is the first occurrence anyway. A new series is saved now,
+	// since the moved occurrences point at it; the original is only changed
+	// once an occurrence has moved.
+	targetSeriesID := appointment.SeriesID
+	var seriesUpdate, newSeries *models.AppointmentSeries
+	if moving && series != nil {
+		if rule, err := recurrence.Parse(series.RRule, loc); err == nil {
+			shiftRule := func(r *recurrence.Rule) string {
+				r.ShiftDays(dayDelta)
+				if !r.Until.IsZero() {
+					r.Until = r.Until.AddDate(0, 0, dayDelta)
+				}
+				return r.String()
+			}
+			update := *series
+			if scope == scopeFollowing && appointment.DateTime.After(series.DTStart) {
+				following := *rule
+				following.ByDay = append([]time.Weekday(nil), rule.ByDay...)
+				if following.Count > 0 {
+					following.Count = 0
+					for _, t := range rule.Occurrences(series.DTStart, loc) {
+						if !t.Before(appointment.DateTime) {
+							following.Count++
+						}
+					}
+				}
+				rule.EndBefore(appointment.DateTime)
+				update.RRule = rule.String()
+
+				newSeries = &models.AppointmentSeries{
+					ID:        uuid.New().String(),
+					UserPhone: series.UserPhone,
+					RRule:     shiftRule(&following),
+					DTStart:   shift(appointment.DateTime),
+					Timezone:  loc.String(),
+				}
+				if err := e.store.CreateAppointmentSeries(newSeries); err != nil {
+					return nil, fmt.Errorf("failed to create appointment series: %w", err)
+				}
+				targetSeriesID = newSeries.ID
+			} else {
+				update.DTStart = shift(series.DTStart)
+				update.RRule = shiftRule(rule)
+			}
+			seriesUpdate = &update
+		}
+	}
+
+	// Moving later, update the last occurrence first so occurrences do not
+	// collide with their not-yet-moved neighbours
+	if moving && newDateTime.After(appointment.DateTime) {
+		for i, j := 0, len(occurrences)-1; i < j; i, j = i+1, j-1 {
+			occurrences[i], occurrences[j] = occurrences[j], occurrences[i]
+		}
+	}
+
+	providers := map[string]*models.Provider{}
+	modified := []map[string]interface{}{}
+	conflicts := []map[string]interface{}{}
+	var unmoved []models.Appointment
+	for _, occurrence := range occurrences {
+		updated := occurrence
+		updated.SeriesID = targetSeriesID
+		reason := ""
+		if moving {
+			updated.DateTime = shift(occurrence.DateTime)
+			if !updated.DateTime.Equal(occurrence.DateTime) {
+				if decision := e.rescheduleDecision(&occurrence, e.now()); !decision.Allowed {
+					reason = decision.Reason
+				}
+				if err := lifecycle.Transition(&updated, models.StatusRescheduled, e.now()); err != nil {
+					return nil, err
+				}
+				updated.RescheduleCount++
+			}
+		}
+		if newDuration > 0 {
+			updated.Duration = newDuration
+		}
+		if newPurpose != "" {
+			updated.Purpose = newPurpose
+		}
+		if newNotes != "" {
+			updated.Notes = newNotes
+		}
+
+		provider, ok := providers[updated.ProviderID]
+		if !ok {
+			provider, err = e.appointmentProvider(&updated)
+			if err != nil {
+				return nil, err
+			}
+			providers[updated.ProviderID] = provider
+		}
+
+		// A reason set by now is the rescheduling policy refusing the move
+		var ownHolds []models.SlotHold
+		if reason == "" {
+			if err := e.slots.Validate(updated.DateTime, updated.Duration, e.now(), provider); err != nil {
+				reason = capitalize(err.Error())
+			}
+		}
+		if reason == "" && moving {
+			var heldForOthers bool
+			ownHolds, heldForOthers, err = e.slotHolds(updated.ProviderID, updated.DateTime, updated.BlockEnd())
+			if err != nil {
+				return nil, err
+			}
+			if heldForOthers {
+				reason = msgSlotHeld
+			}
+		}
+		if reason == "" {
+			if err := e.store.UpdateAppointment(&updated); err != nil {
+				if !errors.Is(err, database.ErrSlotUnavailable) {
+					return nil, fmt.Errorf("failed to modify appointment: %w", err)
+				}
+				reason = "The new time slot is not available"
+			}
+		}
+
+		if reason != "" {
+			conflicts = append(conflicts, map[string]interface{}{
+				"appointment_id": occurrence.ID,
+				"date_time":      e.formatDateTime(updated.DateTime),
+				"reason":         reason,
+			})
+			unmoved = append(unmoved, occurrence)
+			continue
+		}
+
+		e.releaseHolds(ownHolds, updated.ID)
+		modified = append(modified, map[string]interface{}{
+			"appointment_id": updated.ID,
+			"date_time":      e.formatDateTime(updated.DateTime),
+			"duration":       updated.Duration,
+		})
+	}
+
+	if len(modified) == 0 {
+		// Nothing changed, so neither does the series
+		if newSeries != nil {
+			if err := e.store.DeleteAppointmentSeries(newSeries.ID); err != nil {
+				log.Printf("[modifyAppointment] WARNING: Failed to delete unused series %s: %v", newSeries.ID, err)
+			}
+		}
+		targetSeriesID = appointment.SeriesID
+	} else {
+		if seriesUpdate != nil {
+			if err := e.store.UpdateAppointmentSeries(seriesUpdate); err != nil {
+				log.Printf("[modifyAppointment] WARNING: Failed to update series %s: %v", seriesUpdate.ID, err)
+			}
+		}
+		// Occurrences that could not change stay where they were, but in the
+		// series they now belong to
+		for i := range unmoved {
+			if unmoved[i].SeriesID == targetSeriesID {
+				continue
+			}
+			unmoved[i].SeriesID = targetSeriesID
+			if err := e.store.UpdateAppointment(&unmoved[i]); err != nil {
+				log.Printf("[modifyAppointment] WARNING: Failed to move occurrence %s to series %s: %v", unmoved[i].ID, targetSeriesID, err)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"success":   len(modified) > 0,
+		"series_id": targetSeriesID,
+		"scope":     scope,
+		"changes":   changes,
+		"modified":  modified,
+		"conflicts": conflicts,
+		"message":   fmt.Sprintf("Modified %d appointment(s) in the series: %v", len(modified), changes),
+	}
+	if len(conflicts) > 0 {
+		result["message"] = fmt.Sprintf("Modified %d appointment(s) in the series: %v; %d could not be changed (see conflicts)", len(modified), changes, len(conflicts))
+	}
+	if len(modified) == 0 {
+		result["error"] = "None of the appointments could be changed"
+	}
+	return result, nil
+}
+
+// Scopes for changing an occurrence of a recurring series
+const (
+	scopeThis      = "this"
+	scopeFollowing = "following"
+	scopeSeries    = "series"
+)
+
+// seriesScope reads the scope argument. Appointments outside a series are
+// always changed on their own.
+func seriesScope(args map[string]interface{}, appointment *models.Appointment) string {
+	scope, _ := args["scope"].(string)
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	if appointment.SeriesID == "" || (scope != scopeFollowing && scope != scopeSeries) {
+		return scopeThis
+	}
+	return scope
+}
+
+// seriesOccurrences loads an appointment's series and its occurrences in
+// scope that can still be changed: from this occurrence on for "following", or every upcoming one for
+// "series". The series is nil if its row is missing.
+func (e *ToolExecutor) seriesOccurrences(appointment *models.Appointment, scope string) (*models.AppointmentSeries, []models.Appointment, error) {
+	series, err := e.store.GetAppointmentSeries(appointment.SeriesID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get appointment series: %w", err)
+	}
+
+	all, err := e.store.GetAppointmentsBySeries(appointment.SeriesID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get series appointments: %w", err)
+	}
+
+	from := e.now()
+	if scope == scopeFollowing {
+		from = appointment.DateTime
+	}
+	var occurrences []models.Appointment
+	for _, apt := range all {
+		if !lifecycle.CanTransition(apt.Status, models.StatusRescheduled) || apt.UserPhone != appointment.UserPhone {
+			continue
+		}
+		if apt.ID == appointment.ID || !apt.DateTime.Before(from) {
+			occurrences = append(occurrences, apt)
+		}
+	}
+	return series, occurrences, nil
+}
+
+// seriesLocation returns the timezone a series is expanded in
+func (e *ToolExecutor) seriesLocation(series *models.AppointmentSeries) *time.Location {
+	if series != nil && series.Timezone != "" {
+		if loc, err := time.LoadLocation(series.Timezone); err == nil {
+			return loc
+		}
+	}
+	return e.slots.Schedule().Location
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// seriesRecorder notes the series a test's executor creates
+type seriesRecorder struct {
+	database.Store
+	created []string
+}
+
+func (s *seriesRecorder) CreateAppointmentSeries(series *models.AppointmentSeries) error {
+	if err := s.Store.CreateAppointmentSeries(series); err != nil {
+		return err
+	}
+	s.created = append(s.created, series.ID)
+	return nil
+}
+
+// bookTaken has another caller book each of the given days after first
+func bookTaken(t *testing.T, store database.Store, first time.Time, days []int) {
+	t.Helper()
+	other := newTestExecutor(store, "other")
+	other.SetUserIdentity("+15550002222", "Other")
+	for _, day := range days {
+		at := first.AddDate(0, 0, day).Format(time.RFC3339)
+		if result, err := other.bookAppointment(map[string]interface{}{"date_time": at}); err != nil || result.(map[string]interface{})["success"] != true {
+			t.Fatalf("booking blocker on day %d: %v %v", day, result, err)
+		}
+	}
+}
+
+func TestBookingASeriesReportsConflicts(t *testing.T) {
+	first := testNow.Add(48 * time.Hour)
+	tests := []struct {
+		name      string
+		taken     []int // days after the first occurrence someone else has
+		count     int
+		booked    int
+		conflicts int
+	}{
+		{"a taken week is a conflict", []int{14}, 4, 3, 1},
+		{"every week taken books nothing", []int{0, 7}, 2, 0, 2},
+	}
+	for _, tt := range tests {
+		store := &seriesRecorder{Store: database.NewMemoryStore()}
+		bookTaken(t, store, first, tt.taken)
+
+		executor := newTestExecutor(store, "session")
+		executor.SetUserIdentity("+15550001111", "Caller")
+		result, err := executor.bookAppointment(map[string]interface{}{
+			"date_time":  first.Format(time.RFC3339),
+			"recurrence": fmt.Sprintf("FREQ=WEEKLY;COUNT=%d", tt.count),
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		booked, _ := m["booked"].([]map[string]interface{})
+		conflicts, _ := m["conflicts"].([]map[string]interface{})
+		if len(booked) != tt.booked || len(conflicts) != tt.conflicts {
+			t.Errorf("%s: expected %d booked and %d conflicts, got %v", tt.name, tt.booked, tt.conflicts, m)
+		}
+		if len(store.created) != 1 {
+			t.Fatalf("%s: expected one series to be created, got %v", tt.name, store.created)
+		}
+
+		// A series with nothing booked is not kept
+		series, _ := store.GetAppointmentSeries(store.created[0])
+		if kept := tt.booked > 0; (series != nil) != kept || (m["series_id"] != nil) != kept || m["success"] != kept {
+			t.Errorf("%s: expected the series kept=%v, got %+v and %v", tt.name, kept, series, m)
+		}
+	}
+}
+
+func TestMovingFollowingOccurrencesSplitsTheSeries(t *testing.T) {
+	first := testNow.Add(48 * time.Hour)
+	tests := []struct {
+		name  string
+		taken []int // days after the first occurrence someone else books before the move
+		moved int
+	}{
+		{"the rest of the series moves", nil, 2},
+		{"nothing can move", []int{8, 15}, 0},
+	}
+	for _, tt := range tests {
+		store := &seriesRecorder{Store: database.NewMemoryStore()}
+		executor := newTestExecutor(store, "session")
+		executor.SetUserIdentity("+15550001111", "Caller")
+		result, err := executor.bookAppointment(map[string]interface{}{
+			"date_time":  first.Format(time.RFC3339),
+			"recurrence": "FREQ=WEEKLY;COUNT=3",
+		})
+		if err != nil {
+			t.Fatalf("%s: booking series: %v", tt.name, err)
+		}
+		booked := result.(map[string]interface{})["booked"].([]map[string]interface{})
+		seriesID := result.(map[string]interface{})["series_id"].(string)
+		before, _ := store.GetAppointmentSeries(seriesID)
+		bookTaken(t, store, first, tt.taken)
+
+		// Move the second week onwards a day later
+		result, err = executor.modifyAppointment(map[string]interface{}{
+			"appointment_id": booked[1]["appointment_id"],
+			"new_date_time":  first.AddDate(0, 0, 8).Format(time.RFC3339),
+			"scope":          scopeFollowing,
+		})
+		if err != nil {
+			t.Fatalf("%s: modify following: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		modified, _ := m["modified"].([]map[string]interface{})
+		if len(modified) != tt.moved || m["success"] != (tt.moved > 0) {
+			t.Fatalf("%s: expected %d occurrences moved, got %v", tt.name, tt.moved, m)
+		}
+		if len(store.created) != 2 {
+			t.Fatalf("%s: expected the split series to be created, got %v", tt.name, store.created)
+		}
+		split, _ := store.GetAppointmentSeries(store.created[1])
+		left, _ := store.GetAppointmentsBySeries(seriesID)
+
+		if tt.moved == 0 {
+			after, _ := store.GetAppointmentSeries(seriesID)
+			if split != nil || m["series_id"] != seriesID || len(left) != 3 {
+				t.Errorf("%s: expected the unused split deleted and every occurrence kept, got %+v and %v", tt.name, split, left)
+			}
+			if after.RRule != before.RRule || !after.DTStart.Equal(before.DTStart) {
+				t.Errorf("%s: expected the series rule to be unchanged, got %+v", tt.name, after)
+			}
+			continue
+		}
+		if split == nil || m["series_id"] != split.ID || len(left) != 1 || !left[0].DateTime.Equal(first) {
+			t.Errorf("%s: expected only the first occurrence left in the original series, got %v", tt.name, left)
+		}
+
+		// Cancelling the new series leaves the first occurrence alone
+		result, err = executor.cancelAppointment(map[string]interface{}{
+			"appointment_id": booked[1]["appointment_id"],
+			"scope":          scopeSeries,
+		})
+		if err != nil || result.(map[string]interface{})["count"] != tt.moved {
+			t.Errorf("%s: expected %d cancellations, got %v %v", tt.name, tt.moved, result, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/services/payment"
+)
+
+func (e *ToolExecutor) listServices(args map[string]interface{}) (interface{}, error) {
+	services, err := e.store.GetServices()
+	if err != nil {
+		log.Printf("[listServices] ERROR: Failed to get services: %v", err)
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+
+	formattedServices := make([]map[string]interface{}, len(services))
+	for i, service := range services {
+		formattedServices[i] = map[string]interface{}{
+			"id":          service.ID,
+			"name":        service.Name,
+			"description": service.Description,
+			"duration":    service.Duration,
+			"price":       payment.FormatAmount(service.PriceCents),
+		}
+		if len(service.ProviderIDs) > 0 {
+			eligible := eligibleProviders(providers, &service)
+			names := make([]string, len(eligible))
+			for j, p := range eligible {
+				names[j] = p.Name
+			}
+			formattedServices[i]["providers"] = names
+		}
+	}
+
+	message := fmt.Sprintf("We offer %d service(s)", len(services))
+	if len(services) == 0 {
+		message = "No service catalog is configured. Book with a purpose and duration instead."
+	}
+
+	return map[string]interface{}{
+		"success":  true,
+		"services": formattedServices,
+		"count":    len(services),
+		"message":  message,
+	}, nil
+}
+
+// findService looks up a catalog service by ID, returning nil for an empty,
+// unknown or inactive ID
+func (e *ToolExecutor) findService(id string) (*models.Service, error) {
+	if id == "" {
+		return nil, nil
+	}
+	service, err := e.store.GetServiceByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if service == nil || !service.Active {
+		return nil, nil
+	}
+	return service, nil
+}
+
+// durationMismatch returns the result refusing a call whose duration
+// differs from the length of its catalog service, or nil when they agree
+func durationMismatch(args map[string]interface{}, service *models.Service) map[string]interface{} {
+	d, ok := args["duration"].(float64)
+	if !ok || d <= 0 || int(d) == service.Duration {
+		return nil
+	}
+	return map[string]interface{}{
+		"success": false,
+		"error":   fmt.Sprintf("%s takes %d minutes, not %d. Leave out duration to use the service's length, or leave out service_id to ask for %d minutes.", service.Name, service.Duration, int(d), int(d)),
+	}
+}
+
+// eligibleProviders filters providers down to those offering the service
+func eligibleProviders(providers []models.Provider, service *models.Service) []models.Provider {
+	var eligible []models.Provider
+	for _, p := range providers {
+		if service.OfferedBy(p.ID) {
+			eligible = append(eligible, p)
+		}
+	}
+	return eligible
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func TestListServicesNamesTheProvidersOfferingEach(t *testing.T) {
+	store := database.NewMemoryStore()
+	lee := &models.Provider{Name: "Dr. Lee", Kind: "staff", Active: true}
+	kim := &models.Provider{Name: "Dr. Kim", Kind: "staff", Active: true}
+	for _, p := range []*models.Provider{lee, kim} {
+		if err := store.CreateProvider(p); err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+	}
+	for _, s := range []*models.Service{
+		{Name: "Cleaning", Duration: 30, PriceCents: 4500, Active: true},
+		{Name: "Whitening", Duration: 60, PriceCents: 12000, ProviderIDs: []string{kim.ID}, Active: true},
+		{Name: "Retired", Duration: 30, Active: false},
+	} {
+		if err := store.CreateService(s); err != nil {
+			t.Fatalf("create service: %v", err)
+		}
+	}
+
+	result, err := newTestExecutor(store, "session-1").listServices(nil)
+	if err != nil {
+		t.Fatalf("list services: %v", err)
+	}
+	m := result.(map[string]interface{})
+	services := m["services"].([]map[string]interface{})
+	if m["count"] != 2 || len(services) != 2 {
+		t.Fatalf("expected only the two active services, got %+v", m)
+	}
+
+	tests := []struct {
+		name      string
+		price     string
+		providers []string
+	}{
+		{"Cleaning", "$45.00", nil},
+		{"Whitening", "$120.00", []string{"Dr. Kim"}},
+	}
+	for i, tt := range tests {
+		got := services[i]
+		if got["name"] != tt.name || got["price"] != tt.price {
+			t.Errorf("service %d: got %+v, want %s at %s", i, got, tt.name, tt.price)
+		}
+		names, _ := got["providers"].([]string)
+		if len(names) != len(tt.providers) || (len(names) > 0 && names[0] != tt.providers[0]) {
+			t.Errorf("%s: providers %v, want %v", tt.name, names, tt.providers)
+		}
+	}
+}
+
+func TestEmptyCatalogAsksForAPurposeAndDuration(t *testing.T) {
+	result, err := newTestExecutor(database.NewMemoryStore(), "session-1").listServices(nil)
+	if err != nil {
+		t.Fatalf("list services: %v", err)
+	}
+	m := result.(map[string]interface{})
+	if m["count"] != 0 || m["message"] != "No service catalog is configured. Book with a purpose and duration instead." {
+		t.Fatalf("unexpected empty catalog result %+v", m)
+	}
+}
+
+func TestServiceDurationIsNotSilentlyOverridden(t *testing.T) {
+	store := database.NewMemoryStore()
+	service := &models.Service{Name: "Massage", Duration: 60, Active: true}
+	if err := store.CreateService(service); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+	executor := newTestExecutor(store, "session")
+	executor.SetUserIdentity("+15550008888", "Caller")
+	start := testNow.Add(48 * time.Hour).Format(localLayout)
+
+	tests := []struct {
+		name     string
+		tool     func(map[string]interface{}) (interface{}, error)
+		args     map[string]interface{}
+		success  bool
+		contains string
+		booked   int
+	}{
+		{"booking too short", executor.bookAppointment, map[string]interface{}{"date_time": start, "duration": float64(30)}, false, "60 minutes", 0},
+		{"searching too long", executor.fetchSlots, map[string]interface{}{"date": start[:10], "duration": float64(90)}, false, "60 minutes", 0},
+		{"booking the service's length", executor.bookAppointment, map[string]interface{}{"date_time": start, "duration": float64(60)}, true, "", 1},
+	}
+	for _, tt := range tests {
+		tt.args["service_id"] = service.ID
+		result, err := tt.tool(tt.args)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m := result.(map[string]interface{})
+		if m["success"] != tt.success || (tt.contains != "" && !strings.Contains(fmt.Sprint(m["error"]), tt.contains)) {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+		if apts, _ := store.GetAppointmentsByPhone("+15550008888"); len(apts) != tt.booked {
+			t.Errorf("%s: expected %d booked, got %v", tt.name, tt.booked, apts)
+		}
+	}
+}
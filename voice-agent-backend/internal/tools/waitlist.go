@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+func (e *ToolExecutor) joinWaitlist(args map[string]interface{}) (interface{}, error) {
+	if e.userPhone == "" {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "User not identified. Please identify the user first by asking for their phone number.",
+		}, nil
+	}
+	if e.waitlist == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "The waitlist is not available",
+		}, nil
+	}
+
+	fromDate, ok := args["from_date"].(string)
+	if !ok || fromDate == "" {
+		return nil, fmt.Errorf("from_date is required")
+	}
+	toDate, _ := args["to_date"].(string)
+	preferredTimes, _ := args["preferred_times"].(string)
+
+	entry := &models.WaitlistEntry{
+		UserPhone:      e.userPhone,
+		UserName:       e.userName,
+		Duration:       e.slots.DefaultDuration(),
+		FromDate:       fromDate,
+		ToDate:         toDate,
+		PreferredTimes: preferredTimes,
+	}
+	if d, ok := args["duration"].(float64); ok && d > 0 {
+		entry.Duration = int(d)
+	}
+
+	providers, err := e.store.GetProviders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get providers: %w", err)
+	}
+
+	serviceID, _ := args["service_id"].(string)
+	service, err := e.findService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if serviceID != "" && service == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Unknown service. Use list_services to see what we offer.",
+		}, nil
+	}
+	if service != nil {
+		if mismatch := durationMismatch(args, service); mismatch != nil {
+			return mismatch, nil
+		}
+		entry.ServiceID = service.ID
+		entry.Duration = service.Duration
+		entry.BufferMinutes = service.BufferMinutes
+		providers = eligibleProviders(providers, service)
+	}
+
+	providerQuery, _ := args["provider"].(string)
+	provider, found := findProvider(providers, providerQuery)
+	if !found {
+		return map[string]interface{}{
+			"success": false,
+			"error":   unknownProviderMessage(providerQuery, providers),
+		}, nil
+	}
+	entry.ProviderID = providerID(provider)
+
+	if err := e.waitlist.Join(entry); err != nil {
+		log.Printf("[joinWaitlist] ERROR: %v", err)
+		return map[string]interface{}{
+			"success": false,
+			"error":   capitalize(err.Error()),
+		}, nil
+	}
+
+	window := fmt.Sprintf("between %s and %s", entry.FromDate, entry.ToDate)
+	if entry.FromDate == entry.ToDate {
+		window = "on " + entry.FromDate
+	}
+	log.Printf("[joinWaitlist] SUCCESS: %s waiting %s", e.userPhone, window)
+
+	return map[string]interface{}{
+		"success":             true,
+		"waitlist_id":         entry.ID,
+		"from_date":           entry.FromDate,
+		"to_date":             entry.ToDate,
+		"preferred_times":     entry.PreferredTimes,
+		"hold_window_minutes": int(e.waitlist.HoldWindow().Minutes()),
+		"message": fmt.Sprintf("Added to the waitlist for a slot %s. If one opens up we will hold it for %d minutes and let you know.",
+			window, int(e.waitlist.HoldWindow().Minutes())),
+	}, nil
+}
+
+// offerToWaitlist offers a cancelled appointment's time to waitlisted callers
+func (e *ToolExecutor) offerToWaitlist(appointment *models.Appointment) {
+	if e.waitlist != nil {
+		e.waitlist.AppointmentFreed(appointment)
+	}
+}
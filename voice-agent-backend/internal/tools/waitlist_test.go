@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/waitlist"
+)
+
+func TestCancellationOffersSlotToWaitlist(t *testing.T) {
+	store := database.NewMemoryStore()
+	engine := newTestEngine(store)
+	wl := waitlist.NewService(&config.Config{WaitlistHoldMinutes: 15}, store, engine.Schedule(), nil)
+	defer wl.Stop()
+
+	newCaller := func(phone string) *ToolExecutor {
+		executor := NewToolExecutor(Services{Store: store, Slots: engine, Waitlist: wl, Now: testClock}, phone, nil, nil)
+		executor.SetUserIdentity(phone, "Caller "+phone)
+		return executor
+	}
+	owner, waiting, other := newCaller("+15550000001"), newCaller("+15550000002"), newCaller("+15550000003")
+
+	slot := testNow.Add(48 * time.Hour)
+	result, err := owner.bookAppointment(map[string]interface{}{"date_time": slot.Format(time.RFC3339)})
+	if err != nil {
+		t.Fatalf("booking: %v", err)
+	}
+	appointmentID := result.(map[string]interface{})["appointment_id"]
+
+	result, err = waiting.joinWaitlist(map[string]interface{}{"from_date": slot.Format("2006-01-02")})
+	if err != nil || result.(map[string]interface{})["success"] != true {
+		t.Fatalf("join waitlist: %v %v", result, err)
+	}
+	if _, err := owner.cancelAppointment(map[string]interface{}{"appointment_id": appointmentID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	// The freed slot is held for the waitlisted caller only
+	tests := []struct {
+		name    string
+		caller  *ToolExecutor
+		success bool
+		err     string
+	}{
+		{"another caller is refused the held slot", other, false, msgSlotHeld},
+		{"the waitlisted caller books it", waiting, true, ""},
+		{"then it is taken", owner, false, msgSlotBooked},
+	}
+	for _, tt := range tests {
+		result, err := tt.caller.bookAppointment(map[string]interface{}{"date_time": slot.Format(time.RFC3339)})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m := result.(map[string]interface{}); m["success"] != tt.success || (tt.err != "" && m["error"] != tt.err) {
+			t.Errorf("%s: got %v", tt.name, m)
+		}
+	}
+
+	if holds, _ := store.GetSlotHolds(slot, slot.Add(time.Hour)); len(holds) != 0 {
+		t.Fatalf("expected the hold to be released after booking, got %v", holds)
+	}
+	if entries, _ := store.GetWaitlistEntries(models.WaitlistBooked); len(entries) != 1 {
+		t.Fatalf("expected the waitlist entry to be marked booked, got %v", entries)
+	}
+}
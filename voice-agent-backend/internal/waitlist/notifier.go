@@ -0,0 +1,32 @@
+package waitlist
+
+import (
+	"log"
+	"time"
+
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// Notifier tells a waitlisted caller that a slot is being held for them.
+// Implementations might send an SMS, an email or place an outbound call.
+type Notifier interface {
+	NotifyOffer(entry *models.WaitlistEntry, hold *models.SlotHold) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(entry *models.WaitlistEntry, hold *models.SlotHold) error
+
+// NotifyOffer calls f
+func (f NotifierFunc) NotifyOffer(entry *models.WaitlistEntry, hold *models.SlotHold) error {
+	return f(entry, hold)
+}
+
+// LogNotifier only logs offers; it is the default until a real channel is configured
+type LogNotifier struct{}
+
+// NotifyOffer logs the offer
+func (LogNotifier) NotifyOffer(entry *models.WaitlistEntry, hold *models.SlotHold) error {
+	log.Printf("[waitlist] NOTIFY %s (%s): slot at %s is held until %s",
+		entry.UserName, entry.UserPhone, hold.DateTime.Format(time.RFC3339), hold.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,304 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/config"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// DefaultHoldWindow is how long a freed slot is held for a waitlisted caller
+const DefaultHoldWindow = 30 * time.Minute
+
+// Service offers freed slots to waitlisted callers. Each offer is a slot hold
+// for the caller; when it lapses the slot moves on to the next in line.
+type Service struct {
+	store      database.Store
+	schedule   *availability.Schedule
+	notifier   Notifier
+	holdWindow time.Duration
+	ticker     *time.Ticker
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mu         sync.Mutex // serializes offers so a slot goes to one caller
+}
+
+// NewService creates a waitlist service and starts the loop that expires
+// lapsed offers. A nil notifier logs offers.
+func NewService(cfg *config.Config, store database.Store, schedule *availability.Schedule, notifier Notifier) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	holdWindow := time.Duration(cfg.WaitlistHoldMinutes) * time.Minute
+	if holdWindow <= 0 {
+		holdWindow = DefaultHoldWindow
+	}
+
+	s := &Service{
+		store:      store,
+		schedule:   schedule,
+		notifier:   notifier,
+		holdWindow: holdWindow,
+		ticker:     time.NewTicker(1 * time.Minute),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	go s.expiryLoop()
+
+	return s
+}
+
+// HoldWindow returns how long offers are held
+func (s *Service) HoldWindow() time.Duration {
+	return s.holdWindow
+}
+
+// Stop stops the expiry loop
+func (s *Service) Stop() {
+	s.ticker.Stop()
+	s.cancel()
+}
+
+// Join validates and stores a new waitlist entry
+func (s *Service) Join(entry *models.WaitlistEntry) error {
+	from, err := s.schedule.ParseDate(entry.FromDate)
+	if err != nil {
+		return fmt.Errorf("invalid from_date, use YYYY-MM-DD")
+	}
+	if entry.ToDate == "" {
+		entry.ToDate = entry.FromDate
+	}
+	to, err := s.schedule.ParseDate(entry.ToDate)
+	if err != nil {
+		return fmt.Errorf("invalid to_date, use YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return fmt.Errorf("to_date must not be before from_date")
+	}
+	if _, err := availability.ParseTimeRanges(entry.PreferredTimes); err != nil {
+		return fmt.Errorf("invalid preferred_times: %w", err)
+	}
+
+	entry.Status = models.WaitlistWaiting
+	if err := s.store.CreateWaitlistEntry(entry); err != nil {
+		return fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	return nil
+}
+
+// AppointmentFreed offers a cancelled appointment's time to the waitlist
+func (s *Service) AppointmentFreed(apt *models.Appointment) {
+	if err := s.Offer(apt.ProviderID, apt.DateTime, apt.BlockEnd()); err != nil {
+		log.Printf("[waitlist] Failed to offer freed slot %s: %v", apt.DateTime.Format(time.RFC3339), err)
+	}
+}
+
+// Offer holds [start, end) on a provider's calendar for the first waiting
+// entry that fits and notifies them. It does nothing if the time has passed,
+// is booked or held again, or nobody is waiting for it.
+func (s *Service) Offer(providerID string, start, end time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !start.After(time.Now()) {
+		return nil
+	}
+
+	holds, err := s.store.GetSlotHolds(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	for _, hold := range holds {
+		if hold.ProviderID == providerID {
+			return nil
+		}
+	}
+
+	entries, err := s.store.GetWaitlistEntries(models.WaitlistWaiting)
+	if err != nil {
+		return fmt.Errorf("failed to get waitlist: %w", err)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if !s.matches(entry, providerID, start, end) {
+			continue
+		}
+
+		// Someone may have booked part of the slot since it was freed
+		minutes := entry.Duration + entry.BufferMinutes
+		available, err := s.store.CheckSlotAvailability(providerID, start, minutes)
+		if err != nil {
+			return fmt.Errorf("failed to check availability: %w", err)
+		}
+		if !available {
+			// A shorter request may still fit
+			continue
+		}
+
+		expiresAt := time.Now().Add(s.holdWindow)
+		hold := &models.SlotHold{
+			ProviderID:      providerID,
+			DateTime:        start,
+			EndTime:         start.Add(time.Duration(minutes) * time.Minute),
+			HolderPhone:     entry.UserPhone,
+			WaitlistEntryID: entry.ID,
+			ExpiresAt:       expiresAt,
+		}
+		err = s.store.CreateSlotHold(hold)
+		if errors.Is(err, database.ErrSlotUnavailable) {
+			// A caller held it since the check above
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to hold slot: %w", err)
+		}
+
+		entry.Status = models.WaitlistOffered
+		entry.OfferDateTime = &start
+		entry.OfferProviderID = providerID
+		entry.OfferExpiresAt = &expiresAt
+		if err := s.store.UpdateWaitlistEntry(entry); err != nil {
+			return fmt.Errorf("failed to update waitlist entry: %w", err)
+		}
+
+		log.Printf("[waitlist] Offered %s to %s until %s", start.Format(time.RFC3339), entry.UserPhone, expiresAt.Format(time.RFC3339))
+		if err := s.notifier.NotifyOffer(entry, hold); err != nil {
+			log.Printf("[waitlist] Failed to notify %s: %v", entry.UserPhone, err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// Accept marks the waitlist entry behind a hold as booked
+func (s *Service) Accept(hold *models.SlotHold, appointmentID string) error {
+	if hold.WaitlistEntryID == "" {
+		return nil
+	}
+	entry, err := s.store.GetWaitlistEntryByID(hold.WaitlistEntryID)
+	if err != nil {
+		return fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	if entry == nil {
+		return nil
+	}
+	entry.Status = models.WaitlistBooked
+	entry.AppointmentID = appointmentID
+	return s.store.UpdateWaitlistEntry(entry)
+}
+
+// matches reports whether a freed slot suits the entry: a provider offering
+// what they want, enough time, a date in range, and a start inside one of
+// the preferred time windows.
+func (s *Service) matches(entry *models.WaitlistEntry, providerID string, start, end time.Time) bool {
+	if entry.ProviderID != "" && entry.ProviderID != providerID {
+		return false
+	}
+	if start.Add(time.Duration(entry.Duration+entry.BufferMinutes) * time.Minute).After(end) {
+		return false
+	}
+	if entry.ServiceID != "" && providerID != "" {
+		service, err := s.store.GetServiceByID(entry.ServiceID)
+		if err != nil || service == nil || !service.OfferedBy(providerID) {
+			return false
+		}
+	}
+
+	local := start.In(s.schedule.Location)
+	date := local.Format("2006-01-02")
+	if date < entry.FromDate || date > entry.ToDate {
+		return false
+	}
+
+	windows, err := availability.ParseTimeRanges(entry.PreferredTimes)
+	if err != nil || len(windows) == 0 {
+		return err == nil
+	}
+	startMin := local.Hour()*60 + local.Minute()
+	for _, window := range windows {
+		if window.Contains(startMin, startMin+entry.Duration) {
+			return true
+		}
+	}
+	return false
+}
+
+// expiryLoop releases lapsed offers
+func (s *Service) expiryLoop() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.ticker.C:
+			s.expireOffers()
+		}
+	}
+}
+
+// expireOffers deletes expired holds, passes lapsed waitlist offers on to
+// the next caller in line and drops entries whose dates have all passed
+func (s *Service) expireOffers() {
+	s.expireEntries(time.Now())
+
+	expired, err := s.store.DeleteExpiredSlotHolds(time.Now())
+	if err != nil {
+		log.Printf("[waitlist] Failed to expire holds: %v", err)
+		return
+	}
+
+	for _, hold := range expired {
+		if hold.WaitlistEntryID == "" {
+			continue
+		}
+
+		entry, err := s.store.GetWaitlistEntryByID(hold.WaitlistEntryID)
+		if err != nil {
+			log.Printf("[waitlist] Failed to get entry %s: %v", hold.WaitlistEntryID, err)
+			continue
+		}
+		if entry != nil && entry.Status == models.WaitlistOffered {
+			entry.Status = models.WaitlistExpired
+			if err := s.store.UpdateWaitlistEntry(entry); err != nil {
+				log.Printf("[waitlist] Failed to expire entry %s: %v", entry.ID, err)
+			}
+		}
+
+		if err := s.Offer(hold.ProviderID, hold.DateTime, hold.EndTime); err != nil {
+			log.Printf("[waitlist] Failed to re-offer %s: %v", hold.DateTime.Format(time.RFC3339), err)
+		}
+	}
+}
+
+// expireEntries marks waiting entries whose last acceptable date is before
+// now's date as expired
+func (s *Service) expireEntries(now time.Time) {
+	entries, err := s.store.GetWaitlistEntries(models.WaitlistWaiting)
+	if err != nil {
+		log.Printf("[waitlist] Failed to get waitlist: %v", err)
+		return
+	}
+
+	today := now.In(s.schedule.Location).Format("2006-01-02")
+	for i := range entries {
+		entry := &entries[i]
+		if entry.ToDate >= today {
+			continue
+		}
+		entry.Status = models.WaitlistExpired
+		if err := s.store.UpdateWaitlistEntry(entry); err != nil {
+			log.Printf("[waitlist] Failed to expire entry %s: %v", entry.ID, err)
+		}
+	}
+}
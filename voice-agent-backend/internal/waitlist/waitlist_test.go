@@ -0,0 +1,197 @@
+package waitlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voice-agent/backend/internal/availability"
+	"github.com/voice-agent/backend/internal/database"
+	"github.com/voice-agent/backend/internal/models"
+)
+
+// newTestService returns a waitlist service without the expiry loop
+func newTestService(store database.Store, notifier Notifier) *Service {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &Service{
+		store:      store,
+		schedule:   &availability.Schedule{Location: time.UTC},
+		notifier:   notifier,
+		holdWindow: DefaultHoldWindow,
+	}
+}
+
+func TestJoinValidatesTheEntry(t *testing.T) {
+	s := newTestService(database.NewMemoryStore(), nil)
+	tests := []struct {
+		name  string
+		entry models.WaitlistEntry
+		ok    bool
+	}{
+		{"one day", models.WaitlistEntry{FromDate: "2026-03-10"}, true},
+		{"range with windows", models.WaitlistEntry{FromDate: "2026-03-10", ToDate: "2026-03-12", PreferredTimes: "09:00-12:00,14:00-17:00"}, true},
+		{"bad from_date", models.WaitlistEntry{FromDate: "next week"}, false},
+		{"range backwards", models.WaitlistEntry{FromDate: "2026-03-12", ToDate: "2026-03-10"}, false},
+		{"bad window", models.WaitlistEntry{FromDate: "2026-03-10", PreferredTimes: "mornings"}, false},
+	}
+	for _, tt := range tests {
+		entry := tt.entry
+		err := s.Join(&entry)
+		if tt.ok != (err == nil) {
+			t.Errorf("%s: got %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if tt.ok && (entry.Status != models.WaitlistWaiting || entry.ToDate == "") {
+			t.Errorf("%s: stored %+v", tt.name, entry)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	store := database.NewMemoryStore()
+	service := &models.Service{Name: "Color", Duration: 60, ProviderIDs: []string{"p1"}, Active: true}
+	if err := store.CreateService(service); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+	s := newTestService(store, nil)
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		entry models.WaitlistEntry
+		want  bool
+	}{
+		{"anything that day", models.WaitlistEntry{Duration: 30, FromDate: "2026-03-10", ToDate: "2026-03-10"}, true},
+		{"needs longer than the slot", models.WaitlistEntry{Duration: 45, BufferMinutes: 30, FromDate: "2026-03-10", ToDate: "2026-03-10"}, false},
+		{"other provider", models.WaitlistEntry{Duration: 30, ProviderID: "p2", FromDate: "2026-03-10", ToDate: "2026-03-10"}, false},
+		{"before the range", models.WaitlistEntry{Duration: 30, FromDate: "2026-03-11", ToDate: "2026-03-12"}, false},
+		{"after the range", models.WaitlistEntry{Duration: 30, FromDate: "2026-03-01", ToDate: "2026-03-09"}, false},
+		{"inside a window", models.WaitlistEntry{Duration: 30, FromDate: "2026-03-10", ToDate: "2026-03-10", PreferredTimes: "09:00-11:00"}, true},
+		{"runs past the window", models.WaitlistEntry{Duration: 60, FromDate: "2026-03-10", ToDate: "2026-03-10", PreferredTimes: "09:00-10:30"}, false},
+		{"service the provider offers", models.WaitlistEntry{Duration: 60, ServiceID: service.ID, FromDate: "2026-03-10", ToDate: "2026-03-10"}, true},
+		{"unknown service", models.WaitlistEntry{Duration: 30, ServiceID: "missing", FromDate: "2026-03-10", ToDate: "2026-03-10"}, false},
+	}
+	for _, tt := range tests {
+		if got := s.matches(&tt.entry, "p1", start, end); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if s.matches(&models.WaitlistEntry{Duration: 60, ServiceID: service.ID, FromDate: "2026-03-10", ToDate: "2026-03-10"}, "p3", start, end) {
+		t.Error("expected a provider without the service not to match")
+	}
+}
+
+func TestOfferHoldsTheSlotForTheFirstMatchingEntry(t *testing.T) {
+	store := database.NewMemoryStore()
+	var notified []string
+	s := newTestService(store, NotifierFunc(func(entry *models.WaitlistEntry, hold *models.SlotHold) error {
+		notified = append(notified, entry.UserPhone)
+		return nil
+	}))
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	day := start.Format("2006-01-02")
+
+	for _, entry := range []models.WaitlistEntry{
+		{UserPhone: "+15550000001", Duration: 90, FromDate: day, ToDate: day},
+		{UserPhone: "+15550000002", Duration: 30, FromDate: day, ToDate: day},
+		{UserPhone: "+15550000003", Duration: 30, FromDate: day, ToDate: day},
+	} {
+		entry := entry
+		if err := s.Join(&entry); err != nil {
+			t.Fatalf("join: %v", err)
+		}
+	}
+
+	if err := s.Offer("", start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+	if len(notified) != 1 || notified[0] != "+15550000002" {
+		t.Fatalf("expected the first entry that fits to be offered the slot, got %v", notified)
+	}
+	holds, _ := store.GetSlotHolds(start, start.Add(time.Hour))
+	if len(holds) != 1 || holds[0].HolderPhone != "+15550000002" || holds[0].WaitlistEntryID == "" {
+		t.Fatalf("expected one waitlist hold, got %+v", holds)
+	}
+
+	// A held slot is not offered twice
+	if err := s.Offer("", start, start.Add(time.Hour)); err != nil || len(notified) != 1 {
+		t.Fatalf("expected no second offer, got %v and %v", err, notified)
+	}
+}
+
+func TestOfferSkipsEntriesThatNoLongerFit(t *testing.T) {
+	store := database.NewMemoryStore()
+	var notified []string
+	s := newTestService(store, NotifierFunc(func(entry *models.WaitlistEntry, hold *models.SlotHold) error {
+		notified = append(notified, entry.UserPhone)
+		return nil
+	}))
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	day := start.Format("2006-01-02")
+
+	for _, entry := range []models.WaitlistEntry{
+		{UserPhone: "+15550000001", Duration: 60, FromDate: day, ToDate: day},
+		{UserPhone: "+15550000002", Duration: 30, FromDate: day, ToDate: day},
+	} {
+		entry := entry
+		if err := s.Join(&entry); err != nil {
+			t.Fatalf("join: %v", err)
+		}
+	}
+	// The second half of the freed hour was booked again
+	if err := store.CreateAppointment(&models.Appointment{UserPhone: "+15550000009", DateTime: start.Add(30 * time.Minute), Duration: 30, Status: models.StatusBooked}); err != nil {
+		t.Fatalf("create appointment: %v", err)
+	}
+
+	if err := s.Offer("", start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+	if len(notified) != 1 || notified[0] != "+15550000002" {
+		t.Fatalf("expected the half hour offered to the caller it still fits, got %v", notified)
+	}
+}
+
+func TestExpireEntriesDropsPastDateRanges(t *testing.T) {
+	store := database.NewMemoryStore()
+	s := newTestService(store, nil)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	entries := map[string]models.WaitlistEntry{
+		"past":    {UserPhone: "+15550000001", FromDate: "2026-03-01", ToDate: "2026-03-09"},
+		"today":   {UserPhone: "+15550000002", FromDate: "2026-03-08", ToDate: "2026-03-10"},
+		"ahead":   {UserPhone: "+15550000003", FromDate: "2026-03-12", ToDate: "2026-03-14"},
+		"offered": {UserPhone: "+15550000004", FromDate: "2026-03-01", ToDate: "2026-03-09"},
+	}
+	ids := make(map[string]string)
+	for name, entry := range entries {
+		entry := entry
+		if err := s.Join(&entry); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+		if name == "offered" {
+			entry.Status = models.WaitlistOffered
+			if err := store.UpdateWaitlistEntry(&entry); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+		}
+		ids[name] = entry.ID
+	}
+
+	s.expireEntries(now)
+
+	want := map[string]string{
+		"past":    models.WaitlistExpired,
+		"today":   models.WaitlistWaiting,
+		"ahead":   models.WaitlistWaiting,
+		"offered": models.WaitlistOffered,
+	}
+	for name, status := range want {
+		entry, err := store.GetWaitlistEntryByID(ids[name])
+		if err != nil || entry == nil || entry.Status != status {
+			t.Errorf("%s: got %+v, %v; want status %s", name, entry, err, status)
+		}
+	}
+}
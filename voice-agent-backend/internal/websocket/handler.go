@@ -13,6 +13,7 @@ import (
 	"github.com/voice-agent/backend/internal/agent"
 	"github.com/voice-agent/backend/internal/config"
 	"github.com/voice-agent/backend/internal/models"
+	"github.com/voice-agent/backend/internal/tools"
 )
 
 var upgrader = websocket.Upgrader{
@@ -34,16 +35,18 @@ type Client struct {
 
 // Manager manages WebSocket connections
 type Manager struct {
-	clients map[string]*Client
-	config  *config.Config
-	mu      sync.RWMutex
+	clients  map[string]*Client
+	config   *config.Config
+	services tools.Services
+	mu       sync.RWMutex
 }
 
 // NewManager creates a new WebSocket manager
-func NewManager(cfg *config.Config) *Manager {
+func NewManager(cfg *config.Config, services tools.Services) *Manager {
 	return &Manager{
-		clients: make(map[string]*Client),
-		config:  cfg,
+		clients:  make(map[string]*Client),
+		config:   cfg,
+		services: services,
 	}
 }
 
@@ -67,7 +70,7 @@ func (m *Manager) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create agent with callbacks
-	voiceAgent, err := agent.NewVoiceAgent(m.config, roomName, &agent.AgentConfig{
+	voiceAgent, err := agent.NewVoiceAgent(m.config, m.services, roomName, &agent.AgentConfig{
 		OnTranscript: func(text string, isFinal bool) {
 			client.sendMessage(models.WSMessage{
 				Type: models.WSTypeTranscript,
@@ -83,6 +86,12 @@ func (m *Manager) HandleConnection(w http.ResponseWriter, r *http.Request) {
 				Payload: text,
 			})
 		},
+		OnResponseDelta: func(responseID, delta string) {
+			client.sendMessage(models.WSMessage{
+				Type:    models.WSTypeResponseDelta,
+				Payload: models.ResponseDeltaPayload{ResponseID: responseID, Delta: delta},
+			})
+		},
 		OnToolCall: func(payload models.ToolCallPayload) {
 			client.sendMessage(models.WSMessage{
 				Type:    models.WSTypeToolCall,
@@ -0,0 +1,14 @@
+-- Supabase API roles. The schema grants to anon and authenticated, which only
+-- Supabase creates, so a plain Postgres database gets them as roles that
+-- cannot log in. This runs first so 001_initial_schema.sql applies unchanged.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'anon') THEN
+        CREATE ROLE anon NOLOGIN;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        CREATE ROLE authenticated NOLOGIN;
+    END IF;
+END
+$$;
@@ -0,0 +1,35 @@
+-- Enforce non-overlapping booked appointments in the database so that
+-- concurrent bookings cannot both succeed.
+-- Existing overlapping booked appointments must be resolved before applying.
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- timestamptz + interval is not immutable, so the end time is kept in a
+-- trigger-maintained column instead of a generated column.
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS end_time TIMESTAMP WITH TIME ZONE;
+
+CREATE OR REPLACE FUNCTION set_appointment_end_time()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.end_time = NEW.date_time + make_interval(mins => COALESCE(NEW.duration, 30));
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS set_appointments_end_time ON appointments;
+CREATE TRIGGER set_appointments_end_time
+    BEFORE INSERT OR UPDATE OF date_time, duration ON appointments
+    FOR EACH ROW
+    EXECUTE FUNCTION set_appointment_end_time();
+
+UPDATE appointments
+SET end_time = date_time + make_interval(mins => COALESCE(duration, 30))
+WHERE end_time IS NULL;
+
+ALTER TABLE appointments ALTER COLUMN end_time SET NOT NULL;
+
+-- Two booked appointments may not share any instant ([start, end) ranges)
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (tstzrange(date_time, end_time, '[)') WITH &&)
+    WHERE (status = 'booked');
@@ -0,0 +1,4 @@
+-- Optional per-user IANA timezone (e.g. 'America/New_York') used to show
+-- appointment times in the caller's local time alongside business time.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
@@ -0,0 +1,47 @@
+-- Providers are bookable resources (staff members, rooms) with their own
+-- calendars. Appointments may be assigned to a provider, and the overlap
+-- constraint now applies per provider rather than to the whole business.
+
+CREATE TABLE IF NOT EXISTS providers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'staff' CHECK (kind IN ('staff', 'room')),
+    hours TEXT, -- weekly hours, e.g. 'mon-fri 09:00-17:00'; NULL uses business hours
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DROP TRIGGER IF EXISTS update_providers_updated_at ON providers;
+CREATE TRIGGER update_providers_updated_at
+    BEFORE UPDATE ON providers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS provider_id UUID REFERENCES providers(id);
+CREATE INDEX IF NOT EXISTS idx_appointments_provider_date ON appointments(provider_id, date_time);
+
+-- Appointments without a provider share one calendar, hence the COALESCE
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (
+        COALESCE(provider_id, '00000000-0000-0000-0000-000000000000'::uuid) WITH =,
+        tstzrange(date_time, end_time, '[)') WITH &&
+    )
+    WHERE (status = 'booked');
+
+ALTER TABLE providers ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON providers
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON providers TO anon, authenticated;
+
+-- Example:
+-- INSERT INTO providers (name, kind, hours) VALUES
+--     ('Dr. Patel', 'staff', 'mon-fri 09:00-17:00'),
+--     ('Dr. Chen', 'staff', 'tue-sat 10:00-18:00'),
+--     ('Room 2', 'room', NULL);
@@ -0,0 +1,55 @@
+-- Service catalog: the appointment types a business offers, with their
+-- default length, buffer time, price and eligible providers. Appointments
+-- booked from the catalog record the service, buffer and price.
+
+CREATE TABLE IF NOT EXISTS services (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    duration INTEGER NOT NULL CHECK (duration > 0), -- in minutes
+    buffer_minutes INTEGER NOT NULL DEFAULT 0 CHECK (buffer_minutes >= 0),
+    price_cents BIGINT NOT NULL DEFAULT 0 CHECK (price_cents >= 0),
+    provider_ids UUID[] NOT NULL DEFAULT '{}', -- empty means any provider
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DROP TRIGGER IF EXISTS update_services_updated_at ON services;
+CREATE TRIGGER update_services_updated_at
+    BEFORE UPDATE ON services
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS service_id UUID REFERENCES services(id);
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS buffer_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS price_cents BIGINT;
+
+-- Buffer time after an appointment is part of its calendar block
+CREATE OR REPLACE FUNCTION set_appointment_end_time()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.end_time = NEW.date_time + make_interval(mins => COALESCE(NEW.duration, 30) + COALESCE(NEW.buffer_minutes, 0));
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS set_appointments_end_time ON appointments;
+CREATE TRIGGER set_appointments_end_time
+    BEFORE INSERT OR UPDATE OF date_time, duration, buffer_minutes ON appointments
+    FOR EACH ROW
+    EXECUTE FUNCTION set_appointment_end_time();
+
+ALTER TABLE services ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON services
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON services TO anon, authenticated;
+
+-- Example:
+-- INSERT INTO services (name, description, duration, buffer_minutes, price_cents) VALUES
+--     ('Check-up', 'Routine examination and cleaning', 30, 10, 7500),
+--     ('Consultation', 'Initial consultation with a specialist', 45, 15, 12000);
@@ -0,0 +1,33 @@
+-- Recurring appointments. A series stores its iCalendar RRULE; each
+-- occurrence is a regular appointment row pointing at the series, so
+-- conflicts, cancellations and reminders work per occurrence.
+
+CREATE TABLE IF NOT EXISTS appointment_series (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_phone VARCHAR(20) NOT NULL,
+    rrule TEXT NOT NULL, -- e.g. 'FREQ=WEEKLY;BYDAY=TU;COUNT=6'
+    dtstart TIMESTAMP WITH TIME ZONE NOT NULL,
+    timezone VARCHAR(64) NOT NULL, -- IANA zone the rule is expanded in
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointment_series_user_phone ON appointment_series(user_phone);
+
+DROP TRIGGER IF EXISTS update_appointment_series_updated_at ON appointment_series;
+CREATE TRIGGER update_appointment_series_updated_at
+    BEFORE UPDATE ON appointment_series
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES appointment_series(id);
+CREATE INDEX IF NOT EXISTS idx_appointments_series_id ON appointments(series_id);
+
+ALTER TABLE appointment_series ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON appointment_series
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON appointment_series TO anon, authenticated;
@@ -0,0 +1,64 @@
+-- Waitlist and slot holds. Callers join the waitlist for a date range and
+-- optional preferred times; when a matching appointment is cancelled the
+-- freed slot is held for the first waiting caller for a limited time.
+
+CREATE TABLE IF NOT EXISTS waitlist (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_phone VARCHAR(20) NOT NULL,
+    user_name VARCHAR(255),
+    provider_id UUID REFERENCES providers(id),
+    service_id UUID REFERENCES services(id),
+    duration INTEGER NOT NULL CHECK (duration > 0), -- in minutes
+    buffer_minutes INTEGER NOT NULL DEFAULT 0,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    preferred_times TEXT, -- e.g. '09:00-12:00,14:00-17:00'
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting', -- waiting, offered, booked, expired, cancelled
+    offer_date_time TIMESTAMP WITH TIME ZONE,
+    offer_provider_id UUID REFERENCES providers(id),
+    offer_expires_at TIMESTAMP WITH TIME ZONE,
+    appointment_id UUID REFERENCES appointments(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (to_date >= from_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_status ON waitlist(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_waitlist_user_phone ON waitlist(user_phone);
+
+DROP TRIGGER IF EXISTS update_waitlist_updated_at ON waitlist;
+CREATE TRIGGER update_waitlist_updated_at
+    BEFORE UPDATE ON waitlist
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS slot_holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_id UUID REFERENCES providers(id),
+    date_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    holder_phone VARCHAR(20),
+    waitlist_entry_id UUID REFERENCES waitlist(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (end_time > date_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_holds_range ON slot_holds(date_time, end_time);
+CREATE INDEX IF NOT EXISTS idx_slot_holds_expires_at ON slot_holds(expires_at);
+
+ALTER TABLE waitlist ENABLE ROW LEVEL SECURITY;
+ALTER TABLE slot_holds ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON waitlist
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "Allow all operations for service role" ON slot_holds
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON waitlist TO anon, authenticated;
+GRANT ALL ON slot_holds TO anon, authenticated;
@@ -0,0 +1,8 @@
+-- Slot holds taken during a call (hold_slot) belong to the agent session
+-- and are released when the call ends.
+
+ALTER TABLE slot_holds ADD COLUMN IF NOT EXISTS session_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_slot_holds_session_id ON slot_holds(session_id);
+
+-- slot_holds has row level security, its policy and its grants from
+-- 007_waitlist.sql; the new column is covered by them.
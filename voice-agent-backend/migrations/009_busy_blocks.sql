@@ -0,0 +1,24 @@
+-- Busy time pulled from providers' external calendars (CalDAV or ICS files).
+-- Each sync replaces a provider's blocks in the synced window; slots that
+-- overlap a block are unavailable.
+
+CREATE TABLE IF NOT EXISTS busy_blocks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_id UUID REFERENCES providers(id),
+    date_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    external_uid TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (end_time > date_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_busy_blocks_range ON busy_blocks(provider_id, date_time, end_time);
+
+ALTER TABLE busy_blocks ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON busy_blocks
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON busy_blocks TO anon, authenticated;
@@ -0,0 +1,25 @@
+-- Appointment lifecycle: booked -> confirmed -> checked_in -> completed, with
+-- rescheduled, no_show and cancelled along the way. Each transition records
+-- when it happened. Every status that still occupies a slot is covered by
+-- the overlap constraint.
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('booked', 'confirmed', 'rescheduled', 'checked_in', 'completed', 'no_show', 'cancelled'));
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS rescheduled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS no_show_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (
+        COALESCE(provider_id, '00000000-0000-0000-0000-000000000000'::uuid) WITH =,
+        tstzrange(date_time, end_time, '[)') WITH &&
+    )
+    WHERE (status IN ('booked', 'confirmed', 'rescheduled', 'checked_in'));
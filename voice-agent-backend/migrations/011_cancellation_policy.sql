@@ -0,0 +1,7 @@
+-- Cancellation policy: how often an appointment has been rescheduled, and
+-- the late cancellation fee charged for it with the payment opened to
+-- collect it.
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS reschedule_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS fee_cents BIGINT;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS fee_payment_id VARCHAR(255);
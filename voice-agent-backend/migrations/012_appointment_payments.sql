@@ -0,0 +1,6 @@
+-- Payments taken for an appointment's price through the process_payment
+-- tool: the Stripe charge or payment intent, its status and when it was paid.
+
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS payment_id VARCHAR(255);
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS payment_status VARCHAR(50);
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS paid_at TIMESTAMP WITH TIME ZONE;
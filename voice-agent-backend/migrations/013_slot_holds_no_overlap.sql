@@ -0,0 +1,22 @@
+-- Enforce non-overlapping slot holds per provider so that two callers
+-- cannot both hold the same slot. Expired holds still count until they are
+-- deleted, so CreateSlotHold clears expired overlapping holds first.
+
+DELETE FROM slot_holds WHERE expires_at <= NOW();
+
+-- Of any holds that already overlap, keep the oldest
+DELETE FROM slot_holds newer
+USING slot_holds older
+WHERE newer.id <> older.id
+  AND COALESCE(newer.provider_id, '00000000-0000-0000-0000-000000000000'::uuid)
+      = COALESCE(older.provider_id, '00000000-0000-0000-0000-000000000000'::uuid)
+  AND tstzrange(newer.date_time, newer.end_time, '[)') && tstzrange(older.date_time, older.end_time, '[)')
+  AND (newer.created_at, newer.id) > (older.created_at, older.id);
+
+ALTER TABLE slot_holds DROP CONSTRAINT IF EXISTS slot_holds_no_overlap;
+ALTER TABLE slot_holds
+    ADD CONSTRAINT slot_holds_no_overlap
+    EXCLUDE USING gist (
+        COALESCE(provider_id, '00000000-0000-0000-0000-000000000000'::uuid) WITH =,
+        tstzrange(date_time, end_time, '[)') WITH &&
+    );
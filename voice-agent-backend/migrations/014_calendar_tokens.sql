@@ -0,0 +1,17 @@
+-- Calendar feed tokens. Feed and invite URLs carry a random token that is
+-- looked up here, so the URL says nothing about whose calendar it is.
+
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    token VARCHAR(64) PRIMARY KEY,
+    phone_number VARCHAR(20) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE calendar_tokens ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "Allow all operations for service role" ON calendar_tokens
+    FOR ALL
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON calendar_tokens TO anon, authenticated;
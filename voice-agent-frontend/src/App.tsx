@@ -6,6 +6,7 @@ import { Avatar } from './components/Avatar';
 import { ChatPanel } from './components/Chat';
 import { CallControls, CallStatus } from './components/Call';
 import { ToolDisplay } from './components/ToolDisplay';
+import { PaymentPanel } from './components/Payment';
 import { CallSummary } from './components/Summary';
 import { LanguageSelector } from './components/UI/LanguageSelector';
 import type { AvatarState } from './types';
@@ -155,6 +156,14 @@ const App: React.FC = () => {
               />
             </div>
 
+            {/* Payment forms opened by the agent */}
+            {store.payments.length > 0 && (
+              <PaymentPanel
+                payments={store.payments}
+                onClose={store.removePayment}
+              />
+            )}
+
             {/* Tool Activity (shown when there are tool calls) */}
             {toolCalls.length > 0 && (
               <ToolDisplay
@@ -170,6 +179,7 @@ const App: React.FC = () => {
               messages={messages}
               currentTranscript={currentTranscript}
               isTranscriptFinal={false}
+              streamingResponse={store.streamingResponse}
               onSendMessage={sendText}
               isConnected={isConnected}
               className="h-full"
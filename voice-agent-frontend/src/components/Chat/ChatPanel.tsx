@@ -8,6 +8,7 @@ interface ChatPanelProps {
   messages: ConversationMessage[];
   currentTranscript: string;
   isTranscriptFinal: boolean;
+  streamingResponse?: ConversationMessage | null;
   onSendMessage: (text: string) => void;
   isConnected: boolean;
   className?: string;
@@ -17,6 +18,7 @@ export const ChatPanel: React.FC<ChatPanelProps> = ({
   messages,
   currentTranscript,
   isTranscriptFinal,
+  streamingResponse,
   onSendMessage,
   isConnected,
   className,
@@ -28,7 +30,7 @@ export const ChatPanel: React.FC<ChatPanelProps> = ({
   // Auto-scroll to bottom when new messages arrive
   useEffect(() => {
     messagesEndRef.current?.scrollIntoView({ behavior: 'smooth' });
-  }, [messages, currentTranscript]);
+  }, [messages, currentTranscript, streamingResponse]);
 
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
@@ -74,6 +76,9 @@ export const ChatPanel: React.FC<ChatPanelProps> = ({
           <ChatMessage key={`${message.timestamp}-${index}`} message={message} />
         ))}
 
+        {/* Reply still being spoken */}
+        {streamingResponse && <ChatMessage message={streamingResponse} />}
+
         {/* Show current transcript */}
         <TranscriptIndicator
           text={currentTranscript}
@@ -0,0 +1,194 @@
+import React from 'react';
+import { clsx } from 'clsx';
+import { CreditCard, Loader2, CheckCircle2, AlertCircle, X } from 'lucide-react';
+import { getStripe } from '../../services/stripe';
+import type { Stripe, StripeConfirmResult, StripeElements } from '../../services/stripe';
+import type { PaymentClient } from '../../types';
+
+interface PaymentPanelProps {
+  payments: PaymentClient[];
+  onClose: (paymentIntentId: string) => void;
+  className?: string;
+}
+
+// PaymentPanel shows a Stripe payment form for each payment the agent opened
+export const PaymentPanel: React.FC<PaymentPanelProps> = ({
+  payments,
+  onClose,
+  className,
+}) => {
+  if (payments.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className={clsx('bg-white rounded-2xl shadow-lg p-4', className)}>
+      <div className="flex items-center gap-2 mb-4">
+        <CreditCard className="w-5 h-5 text-agent-primary" />
+        <h3 className="font-semibold text-gray-800">Payment</h3>
+      </div>
+
+      <div className="space-y-4">
+        {payments.map((payment) => (
+          <PaymentForm
+            key={payment.payment_intent_id}
+            payment={payment}
+            onClose={() => onClose(payment.payment_intent_id)}
+          />
+        ))}
+      </div>
+    </div>
+  );
+};
+
+type FormState = 'loading' | 'ready' | 'submitting' | 'succeeded' | 'processing' | 'failed';
+
+interface PaymentFormProps {
+  payment: PaymentClient;
+  onClose: () => void;
+}
+
+export const PaymentForm: React.FC<PaymentFormProps> = ({ payment, onClose }) => {
+  const containerRef = React.useRef<HTMLDivElement>(null);
+  const stripeRef = React.useRef<Stripe | null>(null);
+  const elementsRef = React.useRef<StripeElements | null>(null);
+  const [state, setState] = React.useState<FormState>('loading');
+  const [error, setError] = React.useState<string | null>(null);
+
+  React.useEffect(() => {
+    let cancelled = false;
+    let destroy: (() => void) | null = null;
+
+    getStripe(payment.publishable_key)
+      .then((stripe) => {
+        if (cancelled || !containerRef.current) return;
+        const elements = stripe.elements({ clientSecret: payment.client_secret });
+        const element = elements.create('payment');
+        element.mount(containerRef.current);
+        destroy = () => element.destroy();
+        stripeRef.current = stripe;
+        elementsRef.current = elements;
+        setState('ready');
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        console.error('Failed to load the payment form:', err);
+        setError('The payment form could not be loaded.');
+        setState('failed');
+      });
+
+    return () => {
+      cancelled = true;
+      destroy?.();
+    };
+  }, [payment.publishable_key, payment.client_secret]);
+
+  const handleSubmit = async (event: React.FormEvent) => {
+    event.preventDefault();
+    const stripe = stripeRef.current;
+    const elements = elementsRef.current;
+    if (!stripe || !elements) return;
+
+    setState('submitting');
+    setError(null);
+    let result: StripeConfirmResult;
+    try {
+      result = await stripe.confirmPayment({
+        elements,
+        redirect: 'if_required',
+        confirmParams: { return_url: window.location.href },
+      });
+    } catch (err) {
+      console.error('Payment failed:', err);
+      setError('The payment failed. Please try again.');
+      setState('ready');
+      return;
+    }
+    if (result.error) {
+      setError(result.error.message || 'The payment failed. Please try again.');
+      setState('ready');
+      return;
+    }
+    setState(result.paymentIntent?.status === 'succeeded' ? 'succeeded' : 'processing');
+  };
+
+  const done = state === 'succeeded' || state === 'processing';
+
+  return (
+    <form
+      onSubmit={handleSubmit}
+      className={clsx(
+        'p-3 rounded-xl border',
+        done ? 'border-green-200 bg-green-50' : 'border-gray-200 bg-gray-50'
+      )}
+    >
+      <div className="flex items-center justify-between mb-3">
+        <span className="font-medium text-gray-800 text-sm">
+          {formatAmount(payment.amount_cents, payment.currency)}
+        </span>
+        <button
+          type="button"
+          onClick={onClose}
+          className="p-1 rounded-lg hover:bg-gray-200 transition-colors"
+          aria-label="Close payment"
+        >
+          <X className="w-4 h-4 text-gray-500" />
+        </button>
+      </div>
+
+      {/* Stripe mounts the card fields here */}
+      <div ref={containerRef} className={clsx(done && 'hidden')} />
+
+      {state === 'loading' && (
+        <div className="flex items-center gap-2 text-xs text-gray-500">
+          <Loader2 className="w-4 h-4 animate-spin" />
+          <span>Loading payment form...</span>
+        </div>
+      )}
+
+      {error && (
+        <div className="mt-3 flex items-center gap-2 text-xs text-red-700">
+          <AlertCircle className="w-4 h-4" />
+          <span>{error}</span>
+        </div>
+      )}
+
+      {done ? (
+        <div className="flex items-center gap-2 text-sm text-green-700">
+          <CheckCircle2 className="w-4 h-4" />
+          <span>
+            {state === 'succeeded'
+              ? 'Payment complete. Thank you!'
+              : 'Payment submitted. It will be confirmed shortly.'}
+          </span>
+        </div>
+      ) : (
+        state !== 'loading' &&
+        state !== 'failed' && (
+          <button
+            type="submit"
+            disabled={state === 'submitting'}
+            className={clsx(
+              'mt-4 w-full flex items-center justify-center gap-2 px-4 py-2 rounded-xl',
+              'bg-agent-primary text-white text-sm font-medium',
+              'hover:shadow-md transition-all duration-300',
+              'disabled:opacity-60 disabled:cursor-not-allowed'
+            )}
+          >
+            {state === 'submitting' && <Loader2 className="w-4 h-4 animate-spin" />}
+            Pay {formatAmount(payment.amount_cents, payment.currency)}
+          </button>
+        )
+      )}
+    </form>
+  );
+};
+
+function formatAmount(cents: number, currency: string): string {
+  return new Intl.NumberFormat(undefined, {
+    style: 'currency',
+    currency: (currency || 'usd').toUpperCase(),
+  }).format(cents / 100);
+}
+
+export default PaymentPanel;
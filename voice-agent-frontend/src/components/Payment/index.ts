@@ -0,0 +1 @@
+export { PaymentPanel, PaymentForm, default } from './PaymentForm';
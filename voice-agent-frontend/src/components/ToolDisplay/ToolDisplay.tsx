@@ -15,6 +15,8 @@ import {
   Clock,
   User,
   AlertCircle,
+  Hourglass,
+  Ban,
 } from 'lucide-react';
 import type { ToolCallPayload } from '../../types';
 
@@ -71,6 +73,14 @@ const ToolCallCard: React.FC<ToolCallCardProps> = ({ toolCall, isActive }) => {
         return <CheckCircle2 className="w-4 h-4 text-green-500" />;
       case 'failed':
         return <XCircle className="w-4 h-4 text-red-500" />;
+      case 'pending':
+        return <Hourglass className="w-4 h-4 text-amber-500" />;
+      case 'confirmed':
+        return <CheckCircle2 className="w-4 h-4 text-green-500" />;
+      case 'declined':
+        return <Ban className="w-4 h-4 text-gray-500" />;
+      case 'expired':
+        return <Clock className="w-4 h-4 text-gray-400" />;
       default:
         return <div className="w-4 h-4 rounded-full border-2 border-gray-300" />;
     }
@@ -86,6 +96,12 @@ const ToolCallCard: React.FC<ToolCallCardProps> = ({ toolCall, isActive }) => {
           ? 'border-green-200 bg-green-50'
           : toolCall.status === 'failed'
           ? 'border-red-200 bg-red-50'
+          : toolCall.status === 'pending'
+          ? 'border-amber-200 bg-amber-50'
+          : toolCall.status === 'confirmed'
+          ? 'border-green-200 bg-green-50'
+          : toolCall.status === 'declined' || toolCall.status === 'expired'
+          ? 'border-gray-200 bg-gray-50 opacity-60'
           : 'border-gray-200 bg-gray-50'
       )}
     >
@@ -121,6 +137,14 @@ const ToolCallCard: React.FC<ToolCallCardProps> = ({ toolCall, isActive }) => {
         )}
       </div>
 
+      {/* What a change waiting for the caller's agreement will do */}
+      {toolCall.summary && (
+        <div className="mt-3 p-2 bg-white/70 rounded-lg text-xs text-gray-700">
+          <span className="font-medium">{pendingLabel(toolCall.status)}</span>{' '}
+          {toolCall.summary}
+        </div>
+      )}
+
       {/* Arguments display */}
       <>{renderArguments(toolCall.arguments)}</>
 
@@ -449,6 +473,19 @@ function getToolInfo(toolName: string): ToolInfo {
   );
 }
 
+function pendingLabel(status: ToolCallPayload['status']): string {
+  switch (status) {
+    case 'confirmed':
+      return 'Confirmed:';
+    case 'declined':
+      return 'Declined:';
+    case 'expired':
+      return 'Expired:';
+    default:
+      return 'Waiting for your OK:';
+  }
+}
+
 function renderArguments(args: Record<string, unknown>): React.ReactNode {
   const keys = Object.keys(args);
   if (keys.length === 0) {
@@ -6,8 +6,10 @@ import { useAudio, float32ToInt16, int16ToArrayBuffer } from './useAudio';
 import type {
   ConnectionPayload,
   TranscriptPayload,
+  ResponseDeltaPayload,
   ToolCallPayload,
   ToolResultPayload,
+  PaymentClient,
   CallSummary,
   CostBreakdown,
   CallState,
@@ -163,6 +165,10 @@ export function useVoiceAgent(
             store.setCallState('listening');
           }
         },
+        onAgentResponseDelta: (payload: ResponseDeltaPayload) => {
+          store.appendResponseDelta(payload);
+          store.setAvatarState('speaking');
+        },
         onAgentResponse: (text: string) => {
           store.addMessage({
             role: 'assistant',
@@ -174,10 +180,16 @@ export function useVoiceAgent(
         },
         onToolCall: (payload: ToolCallPayload) => {
           store.addToolCall(payload);
-          store.setCallState('processing');
+          if (payload.status === 'executing') {
+            store.setCallState('processing');
+          }
         },
         onToolResult: (payload: ToolResultPayload) => {
           store.updateToolCall(payload);
+          const payments = paymentsFromResult(payload);
+          if (payments.length > 0) {
+            store.addPayments(payments);
+          }
         },
         onCallSummary: (summary: CallSummary, cost: CostBreakdown) => {
           console.log('[useVoiceAgent] Received call summary:', summary);
@@ -323,4 +335,18 @@ export function useVoiceAgent(
   };
 }
 
+// paymentsFromResult collects the payment forms a tool result asks the
+// client to show
+function paymentsFromResult(payload: ToolResultPayload): PaymentClient[] {
+  const client = payload.client;
+  if (!client) {
+    return [];
+  }
+  const payments = [...(client.fee_payments ?? [])];
+  if (client.payment_intent_id && client.client_secret && client.publishable_key) {
+    payments.push(client as PaymentClient);
+  }
+  return payments.filter((p) => p.client_secret && p.publishable_key);
+}
+
 export default useVoiceAgent;
@@ -0,0 +1,76 @@
+// Stripe.js is loaded from Stripe's servers, as Stripe requires for PCI
+// compliance. These types cover the parts the payment form uses.
+
+export interface StripeError {
+  message?: string;
+}
+
+export interface StripePaymentElement {
+  mount(element: HTMLElement): void;
+  destroy(): void;
+}
+
+export interface StripeElements {
+  create(type: 'payment'): StripePaymentElement;
+}
+
+export interface StripeConfirmResult {
+  error?: StripeError;
+  paymentIntent?: { id: string; status: string };
+}
+
+export interface Stripe {
+  elements(options: { clientSecret: string }): StripeElements;
+  confirmPayment(options: {
+    elements: StripeElements;
+    redirect: 'if_required';
+    confirmParams: { return_url: string };
+  }): Promise<StripeConfirmResult>;
+}
+
+declare global {
+  interface Window {
+    Stripe?: (publishableKey: string) => Stripe;
+  }
+}
+
+const STRIPE_JS_URL = 'https://js.stripe.com/v3';
+
+let scriptPromise: Promise<void> | null = null;
+const instances = new Map<string, Stripe>();
+
+function loadScript(): Promise<void> {
+  if (window.Stripe) {
+    return Promise.resolve();
+  }
+  if (!scriptPromise) {
+    scriptPromise = new Promise((resolve, reject) => {
+      const script = document.createElement('script');
+      script.src = STRIPE_JS_URL;
+      script.async = true;
+      script.onload = () => resolve();
+      script.onerror = () => {
+        scriptPromise = null;
+        script.remove();
+        reject(new Error('Failed to load Stripe.js'));
+      };
+      document.head.appendChild(script);
+    });
+  }
+  return scriptPromise;
+}
+
+// getStripe returns the Stripe.js instance for a publishable key, loading
+// the library on first use
+export async function getStripe(publishableKey: string): Promise<Stripe> {
+  await loadScript();
+  if (!window.Stripe) {
+    throw new Error('Stripe.js is unavailable');
+  }
+  let stripe = instances.get(publishableKey);
+  if (!stripe) {
+    stripe = window.Stripe(publishableKey);
+    instances.set(publishableKey, stripe);
+  }
+  return stripe;
+}
@@ -2,6 +2,7 @@ import type {
   WSMessage,
   WSMessageType,
   TranscriptPayload,
+  ResponseDeltaPayload,
   ConnectionPayload,
   ToolCallPayload,
   ToolResultPayload,
@@ -16,6 +17,7 @@ interface WSEventHandlers {
   onConnect?: (payload: ConnectionPayload) => void;
   onTranscript?: (payload: TranscriptPayload) => void;
   onAgentResponse?: (text: string) => void;
+  onAgentResponseDelta?: (payload: ResponseDeltaPayload) => void;
   onToolCall?: (payload: ToolCallPayload) => void;
   onToolResult?: (payload: ToolResultPayload) => void;
   onCallSummary?: (summary: CallSummary, cost: CostBreakdown) => void;
@@ -146,6 +148,9 @@ export class WebSocketService {
       case 'agent_response':
         this.handlers.onAgentResponse?.(message.payload as string);
         break;
+      case 'agent_response_delta':
+        this.handlers.onAgentResponseDelta?.(message.payload as ResponseDeltaPayload);
+        break;
       case 'tool_call':
         this.handlers.onToolCall?.(message.payload as ToolCallPayload);
         break;
@@ -4,7 +4,9 @@ import type {
   AvatarState,
   ConversationMessage,
   ToolCallPayload,
+  ResponseDeltaPayload,
   ToolResultPayload,
+  PaymentClient,
   CallSummary,
   CostBreakdown,
 } from '../types';
@@ -22,11 +24,16 @@ interface CallStore {
   messages: ConversationMessage[];
   currentTranscript: string;
   isTranscriptFinal: boolean;
+  // Reply being spoken, shown until the full agent_response arrives
+  streamingResponse: (ConversationMessage & { id: string }) | null;
 
   // Tool calls
   toolCalls: ToolCallPayload[];
   activeToolCall: ToolCallPayload | null;
 
+  // Payments waiting for the caller
+  payments: PaymentClient[];
+
   // Summary and cost
   callSummary: CallSummary | null;
   costBreakdown: CostBreakdown | null;
@@ -44,11 +51,15 @@ interface CallStore {
 
   addMessage: (message: ConversationMessage) => void;
   setTranscript: (text: string, isFinal: boolean) => void;
+  appendResponseDelta: (payload: ResponseDeltaPayload) => void;
 
   addToolCall: (toolCall: ToolCallPayload) => void;
   updateToolCall: (result: ToolResultPayload) => void;
   setActiveToolCall: (toolCall: ToolCallPayload | null) => void;
 
+  addPayments: (payments: PaymentClient[]) => void;
+  removePayment: (paymentIntentId: string) => void;
+
   setCallSummary: (summary: CallSummary) => void;
   setCostBreakdown: (cost: CostBreakdown) => void;
 
@@ -68,8 +79,10 @@ const initialState = {
   messages: [],
   currentTranscript: '',
   isTranscriptFinal: false,
+  streamingResponse: null,
   toolCalls: [],
   activeToolCall: null,
+  payments: [],
   callSummary: null,
   costBreakdown: null,
   avatarUrl: null,
@@ -110,17 +123,45 @@ export const useCallStore = create<CallStore>((set) => ({
       messages: [...state.messages, message],
       currentTranscript: '',
       isTranscriptFinal: false,
+      streamingResponse: message.role === 'assistant' ? null : state.streamingResponse,
     })),
 
   setTranscript: (text, isFinal) =>
     set({ currentTranscript: text, isTranscriptFinal: isFinal }),
 
-  addToolCall: (toolCall) =>
+  appendResponseDelta: ({ response_id, delta }) =>
     set((state) => ({
-      toolCalls: [...state.toolCalls, toolCall],
-      activeToolCall: toolCall,
+      streamingResponse:
+        state.streamingResponse?.id === response_id
+          ? { ...state.streamingResponse, content: `${state.streamingResponse.content} ${delta}` }
+          : {
+              id: response_id,
+              role: 'assistant' as const,
+              content: delta,
+              timestamp: new Date().toISOString(),
+            },
     })),
 
+  addToolCall: (toolCall) =>
+    set((state) => {
+      // A pending action is sent again with its answer under the same id
+      if (state.toolCalls.some((tc) => tc.id === toolCall.id)) {
+        return {
+          toolCalls: state.toolCalls.map((tc) =>
+            tc.id === toolCall.id ? { ...tc, ...toolCall } : tc
+          ),
+          activeToolCall:
+            state.activeToolCall?.id === toolCall.id
+              ? { ...state.activeToolCall, ...toolCall }
+              : state.activeToolCall,
+        };
+      }
+      return {
+        toolCalls: [...state.toolCalls, toolCall],
+        activeToolCall: toolCall,
+      };
+    }),
+
   updateToolCall: (result) =>
     set((state) => ({
       toolCalls: state.toolCalls.map((tc) =>
@@ -146,6 +187,22 @@ export const useCallStore = create<CallStore>((set) => ({
 
   setActiveToolCall: (activeToolCall) => set({ activeToolCall }),
 
+  addPayments: (payments) =>
+    set((state) => ({
+      // A payment offered again replaces the one already shown
+      payments: [
+        ...state.payments.filter(
+          (p) => !payments.some((n) => n.payment_intent_id === p.payment_intent_id)
+        ),
+        ...payments,
+      ],
+    })),
+
+  removePayment: (paymentIntentId) =>
+    set((state) => ({
+      payments: state.payments.filter((p) => p.payment_intent_id !== paymentIntentId),
+    })),
+
   setCallSummary: (callSummary) => {
     console.log('[CallStore] setCallSummary called, setting callState to ended');
     return set({ callSummary, callState: 'ended' });
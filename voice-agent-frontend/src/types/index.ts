@@ -59,11 +59,24 @@ export interface ToolCallRecord {
   timestamp: string;
 }
 
+// A change that needs the caller's agreement arrives as 'pending' with a
+// summary, and the same id is later sent as 'confirmed', 'declined' or
+// 'expired'
+export type ToolCallStatus =
+  | 'pending'
+  | 'confirmed'
+  | 'declined'
+  | 'expired'
+  | 'executing'
+  | 'completed'
+  | 'failed';
+
 export interface ToolCallPayload {
   id: string;
   name: string;
   arguments: Record<string, unknown>;
-  status: 'pending' | 'executing' | 'completed' | 'failed';
+  status: ToolCallStatus;
+  summary?: string;
   result?: unknown;
   error?: string;
 }
@@ -72,9 +85,25 @@ export interface ToolResultPayload {
   id: string;
   name: string;
   result: unknown;
+  client?: ToolResultClient;
   error?: string;
 }
 
+// Payment intent the caller can pay on screen, sent only to the client
+export interface PaymentClient {
+  payment_intent_id: string;
+  client_secret: string;
+  amount_cents: number;
+  currency: string;
+  publishable_key: string;
+}
+
+// Client-only data on a tool result: a payment (process_payment) or the
+// late cancellation fees to pay (cancel_appointment)
+export interface ToolResultClient extends Partial<PaymentClient> {
+  fee_payments?: PaymentClient[];
+}
+
 // Call summary types
 export interface CallSummary {
   id?: string;
@@ -111,6 +140,7 @@ export type WSMessageType =
   | 'connected'
   | 'transcript'
   | 'agent_response'
+  | 'agent_response_delta'
   | 'tool_call'
   | 'tool_result'
   | 'call_summary'
@@ -127,6 +157,13 @@ export interface TranscriptPayload {
   is_final: boolean;
 }
 
+// One spoken piece of a reply, sent while the rest is still being written.
+// The full reply follows as agent_response.
+export interface ResponseDeltaPayload {
+  response_id: string;
+  delta: string;
+}
+
 // Connection payload
 export interface ConnectionPayload {
   agent_id: string;